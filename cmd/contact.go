@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
@@ -9,8 +10,15 @@ import (
 	"os/signal"
 	"syscall"
 
+	"grpc-contact-manager/services/addressbook"
+	"grpc-contact-manager/services/attachment"
+	"grpc-contact-manager/services/changefeed"
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/contact"
 	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/reminder"
 	"grpc-contact-manager/services/servers"
+	"grpc-contact-manager/services/storage"
 
 	"github.com/joho/godotenv"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -37,6 +45,15 @@ func main() {
 	dbName := os.Getenv("DB_NAME")
 	grpcPort := os.Getenv("USER_PORT")
 	port := os.Getenv("PORT")
+	storageDir := os.Getenv("STORAGE_DIR")
+	reminderWebhook := os.Getenv("REMINDER_WEBHOOK_URL")
+	addressBookKey, err := base64.StdEncoding.DecodeString(os.Getenv("ADDRESS_BOOK_KEY"))
+	if err != nil {
+		panic(err)
+	}
+	if len(addressBookKey) == 0 {
+		log.Warn("ADDRESS_BOOK_KEY is not set, so address books with passwords can't be added")
+	}
 
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=5432 sslmode=disable", host, userName, password, dbName)
 	log.Infof("DSN: %s", dsn)
@@ -58,9 +75,24 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	// replicas share their changes through the database
+	feed := changefeed.NewPostgres(db, dsn)
+	server.Feed = feed
+
+	if storageDir == "" {
+		storageDir = "./storage"
+	}
+	store, err := storage.NewLocal(storageDir)
+	if err != nil {
+		panic(err)
+	}
+	server.Storage = store
+	server.AddressBookKey = addressBookKey
 
 	server.Router.Use(middlewares.RecordRequestLatency())
-	server.UserRoutes() //setup the user routes
+	server.UserRoutes()    //setup the user routes
+	server.ContactRoutes() //setup the contact routes
+	server.CardDAVRoutes() //setup the carddav address books
 	httpServer, err := server.StartHttp(ctx, port)
 	if err != nil {
 		panic(err)
@@ -98,10 +130,63 @@ func main() {
 			panic(err)
 		}
 	}()
+
+	reminders, err := reminder.New(db)
+	if err != nil {
+		panic(err)
+	}
+	var notifier reminder.Notifier = reminder.LogNotifier{}
+	if reminderWebhook != "" {
+		notifier = reminder.NewWebhookNotifier(reminderWebhook)
+	}
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	go func() {
+		log.Info("Start reminder scheduler")
+		reminder.NewScheduler(reminders, notifier).Run(schedulerCtx)
+	}()
+
+	addressBooks, err := addressbook.New(db)
+	if err != nil {
+		panic(err)
+	}
+	addressBooks.Key = addressBookKey
+	contacts, err := contact.New(db)
+	if err != nil {
+		panic(err)
+	}
+	contacts.Feed = feed
+	go func() {
+		log.Info("Start address book syncer")
+		addressbook.NewSyncer(addressBooks, contacts).Run(schedulerCtx)
+	}()
+
+	changes, err := changelog.New(db)
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		log.Info("Start change feed listener")
+		feed.Listen(schedulerCtx)
+	}()
+	go func() {
+		log.Info("Start change log pruner")
+		changes.RunPruner(schedulerCtx, changelog.DefaultPruneInterval)
+	}()
+
+	attachments, err := attachment.New(db, store)
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		log.Info("Start upload expirer")
+		attachments.RunExpirer(schedulerCtx, attachment.DefaultExpireInterval)
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Shutting down server")
+	stopScheduler()
 	if err := httpServer.Shutdown(ctx); err != nil {
 		log.Fatal(err)
 	}
@@ -21,7 +21,83 @@ type ContactManagerClient interface {
 	NewContact(ctx context.Context, in *Contact, opts ...grpc.CallOption) (*Contact, error)
 	GetContactByID(ctx context.Context, in *FindContactRequest, opts ...grpc.CallOption) (*Contact, error)
 	GetUserContacts(ctx context.Context, in *User, opts ...grpc.CallOption) (*ContactList, error)
+	// SyncContacts returns the contacts created, updated and deleted since the sync token, or all
+	// of them when it is empty, with the token to sync from next. It fails with FAILED_PRECONDITION
+	// when the token is older than the 30 days changes are kept: the client must then sync again
+	// without a token.
+	SyncContacts(ctx context.Context, in *SyncContactsRequest, opts ...grpc.CallOption) (*ContactChanges, error)
+	// WatchContacts streams the changes to the user's contacts as they are made, from the sync
+	// token on when set, or from now. Every event carries the token to resume from. It fails with
+	// FAILED_PRECONDITION like SyncContacts when the token is too old.
+	WatchContacts(ctx context.Context, in *WatchContactsRequest, opts ...grpc.CallOption) (ContactManager_WatchContactsClient, error)
+	// UpdateContact changes the fields set in the contact and keeps the others, like
+	// PatchContact with an empty updateMask. Fields are cleared with an updateMask.
 	UpdateContact(ctx context.Context, in *Contact, opts ...grpc.CallOption) (*Contact, error)
+	// PatchContact changes only the fields named by the update mask.
+	PatchContact(ctx context.Context, in *UpdateContactRequest, opts ...grpc.CallOption) (*Contact, error)
+	UploadContactPhoto(ctx context.Context, opts ...grpc.CallOption) (ContactManager_UploadContactPhotoClient, error)
+	GetContactPhoto(ctx context.Context, in *PhotoRequest, opts ...grpc.CallOption) (*Photo, error)
+	DeleteContactPhoto(ctx context.Context, in *PhotoRequest, opts ...grpc.CallOption) (*Photo, error)
+	StartAttachmentUpload(ctx context.Context, in *AttachmentUploadRequest, opts ...grpc.CallOption) (*AttachmentUpload, error)
+	GetAttachmentUpload(ctx context.Context, in *AttachmentUpload, opts ...grpc.CallOption) (*AttachmentUpload, error)
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (ContactManager_UploadAttachmentClient, error)
+	ListAttachments(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*AttachmentList, error)
+	DownloadAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (ContactManager_DownloadAttachmentClient, error)
+	DeleteAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*Attachment, error)
+	AddNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error)
+	DeleteNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error)
+	LogInteraction(ctx context.Context, in *Interaction, opts ...grpc.CallOption) (*Interaction, error)
+	DeleteInteraction(ctx context.Context, in *Interaction, opts ...grpc.CallOption) (*Interaction, error)
+	GetContactTimeline(ctx context.Context, in *TimelineRequest, opts ...grpc.CallOption) (*Timeline, error)
+	CreateReminder(ctx context.Context, in *Reminder, opts ...grpc.CallOption) (*Reminder, error)
+	ListReminders(ctx context.Context, in *ReminderRequest, opts ...grpc.CallOption) (*ReminderList, error)
+	UpdateReminder(ctx context.Context, in *Reminder, opts ...grpc.CallOption) (*Reminder, error)
+	DeleteReminder(ctx context.Context, in *Reminder, opts ...grpc.CallOption) (*Reminder, error)
+	LinkContacts(ctx context.Context, in *LinkContactsRequest, opts ...grpc.CallOption) (*RelatedContact, error)
+	UnlinkContacts(ctx context.Context, in *RelatedContact, opts ...grpc.CallOption) (*RelatedContact, error)
+	GetRelatedContacts(ctx context.Context, in *RelatedContactsRequest, opts ...grpc.CallOption) (*RelatedContactList, error)
+	CreateOrganization(ctx context.Context, in *Organization, opts ...grpc.CallOption) (*Organization, error)
+	UpdateOrganization(ctx context.Context, in *Organization, opts ...grpc.CallOption) (*Organization, error)
+	DeleteOrganization(ctx context.Context, in *Organization, opts ...grpc.CallOption) (*Organization, error)
+	ListOrganizations(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*OrganizationList, error)
+	GetOrganizationContacts(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*ContactList, error)
+	SuggestOrganizations(ctx context.Context, in *SuggestOrganizationsRequest, opts ...grpc.CallOption) (*OrganizationList, error)
+	FindDuplicates(ctx context.Context, in *FindDuplicatesRequest, opts ...grpc.CallOption) (*DuplicateList, error)
+	MergeContacts(ctx context.Context, in *MergeContactsRequest, opts ...grpc.CallOption) (*MergeResult, error)
+	UndoMerge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*ContactList, error)
+	ListContactRevisions(ctx context.Context, in *RevisionsRequest, opts ...grpc.CallOption) (*RevisionList, error)
+	RevertContact(ctx context.Context, in *RevertContactRequest, opts ...grpc.CallOption) (*Contact, error)
+	BatchCreateContacts(ctx context.Context, in *BatchCreateContactsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchUpdateContacts(ctx context.Context, in *BatchUpdateContactsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchDeleteContacts(ctx context.Context, in *BatchDeleteContactsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// ImportContacts creates the streamed contacts. Contacts with the email of an existing
+	// contact are skipped, or updated when the duplicate-policy metadata is "update".
+	ImportContacts(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportContactsClient, error)
+	// ExportContacts streams the contacts of the user in id order.
+	ExportContacts(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (ContactManager_ExportContactsClient, error)
+	// ImportVCards imports a vCard file sent in chunks, with the duplicate-policy metadata of ImportContacts.
+	ImportVCards(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportVCardsClient, error)
+	// ExportVCards streams the contacts of the user as a vCard file, in chunks.
+	ExportVCards(ctx context.Context, in *VCardExportRequest, opts ...grpc.CallOption) (ContactManager_ExportVCardsClient, error)
+	// ImportCSV imports a CSV file sent in chunks, with the duplicate-policy metadata of ImportContacts.
+	ImportCSV(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportCSVClient, error)
+	// ExportCSV streams the contacts of the user as a CSV file, in chunks.
+	ExportCSV(ctx context.Context, in *CSVExportRequest, opts ...grpc.CallOption) (ContactManager_ExportCSVClient, error)
+	// ImportXLSX imports an Excel workbook sent in chunks, with the duplicate-policy metadata of ImportContacts.
+	ImportXLSX(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportXLSXClient, error)
+	// ExportXLSX streams the contacts of the user as an Excel workbook, in chunks.
+	ExportXLSX(ctx context.Context, in *XLSXExportRequest, opts ...grpc.CallOption) (ContactManager_ExportXLSXClient, error)
+	// ExportFile streams the contacts of the user as a file in the requested format, in chunks.
+	// It is an addition next to ExportContacts, whose stream of contact messages has no format.
+	ExportFile(ctx context.Context, in *FileExportRequest, opts ...grpc.CallOption) (ContactManager_ExportFileClient, error)
+	// AddAddressBook subscribes the user to an external CardDAV address book, whose contacts
+	// are pulled on a schedule.
+	AddAddressBook(ctx context.Context, in *AddressBook, opts ...grpc.CallOption) (*AddressBook, error)
+	ListAddressBooks(ctx context.Context, in *AddressBookRequest, opts ...grpc.CallOption) (*AddressBookList, error)
+	// DeleteAddressBook stops syncing the address book. The synced contacts are kept.
+	DeleteAddressBook(ctx context.Context, in *AddressBook, opts ...grpc.CallOption) (*AddressBook, error)
+	// SyncAddressBook makes the address book due, so it is synced within a minute.
+	SyncAddressBook(ctx context.Context, in *AddressBook, opts ...grpc.CallOption) (*AddressBook, error)
 }
 
 type contactManagerClient struct {
@@ -59,123 +135,2109 @@ func (c *contactManagerClient) GetUserContacts(ctx context.Context, in *User, op
 	return out, nil
 }
 
+func (c *contactManagerClient) SyncContacts(ctx context.Context, in *SyncContactsRequest, opts ...grpc.CallOption) (*ContactChanges, error) {
+	out := new(ContactChanges)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/SyncContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) WatchContacts(ctx context.Context, in *WatchContactsRequest, opts ...grpc.CallOption) (ContactManager_WatchContactsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[0], "/contact.ContactManager/WatchContacts", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerWatchContactsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_WatchContactsClient interface {
+	Recv() (*ContactEvent, error)
+	grpc.ClientStream
+}
+
+type contactManagerWatchContactsClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerWatchContactsClient) Recv() (*ContactEvent, error) {
+	m := new(ContactEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *contactManagerClient) UpdateContact(ctx context.Context, in *Contact, opts ...grpc.CallOption) (*Contact, error) {
 	out := new(Contact)
 	err := c.cc.Invoke(ctx, "/contact.ContactManager/UpdateContact", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *contactManagerClient) PatchContact(ctx context.Context, in *UpdateContactRequest, opts ...grpc.CallOption) (*Contact, error) {
+	out := new(Contact)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/PatchContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) UploadContactPhoto(ctx context.Context, opts ...grpc.CallOption) (ContactManager_UploadContactPhotoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[1], "/contact.ContactManager/UploadContactPhoto", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerUploadContactPhotoClient{stream}
+	return x, nil
+}
+
+type ContactManager_UploadContactPhotoClient interface {
+	Send(*PhotoChunk) error
+	CloseAndRecv() (*Photo, error)
+	grpc.ClientStream
+}
+
+type contactManagerUploadContactPhotoClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerUploadContactPhotoClient) Send(m *PhotoChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contactManagerUploadContactPhotoClient) CloseAndRecv() (*Photo, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Photo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) GetContactPhoto(ctx context.Context, in *PhotoRequest, opts ...grpc.CallOption) (*Photo, error) {
+	out := new(Photo)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/GetContactPhoto", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DeleteContactPhoto(ctx context.Context, in *PhotoRequest, opts ...grpc.CallOption) (*Photo, error) {
+	out := new(Photo)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteContactPhoto", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) StartAttachmentUpload(ctx context.Context, in *AttachmentUploadRequest, opts ...grpc.CallOption) (*AttachmentUpload, error) {
+	out := new(AttachmentUpload)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/StartAttachmentUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) GetAttachmentUpload(ctx context.Context, in *AttachmentUpload, opts ...grpc.CallOption) (*AttachmentUpload, error) {
+	out := new(AttachmentUpload)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/GetAttachmentUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (ContactManager_UploadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[2], "/contact.ContactManager/UploadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerUploadAttachmentClient{stream}
+	return x, nil
+}
+
+type ContactManager_UploadAttachmentClient interface {
+	Send(*AttachmentChunk) error
+	CloseAndRecv() (*AttachmentUpload, error)
+	grpc.ClientStream
+}
+
+type contactManagerUploadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerUploadAttachmentClient) Send(m *AttachmentChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contactManagerUploadAttachmentClient) CloseAndRecv() (*AttachmentUpload, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AttachmentUpload)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ListAttachments(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*AttachmentList, error) {
+	out := new(AttachmentList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/ListAttachments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DownloadAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (ContactManager_DownloadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[3], "/contact.ContactManager/DownloadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerDownloadAttachmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_DownloadAttachmentClient interface {
+	Recv() (*AttachmentChunk, error)
+	grpc.ClientStream
+}
+
+type contactManagerDownloadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerDownloadAttachmentClient) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) DeleteAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*Attachment, error) {
+	out := new(Attachment)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteAttachment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) AddNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error) {
+	out := new(Note)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/AddNote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DeleteNote(ctx context.Context, in *Note, opts ...grpc.CallOption) (*Note, error) {
+	out := new(Note)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteNote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) LogInteraction(ctx context.Context, in *Interaction, opts ...grpc.CallOption) (*Interaction, error) {
+	out := new(Interaction)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/LogInteraction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DeleteInteraction(ctx context.Context, in *Interaction, opts ...grpc.CallOption) (*Interaction, error) {
+	out := new(Interaction)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteInteraction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) GetContactTimeline(ctx context.Context, in *TimelineRequest, opts ...grpc.CallOption) (*Timeline, error) {
+	out := new(Timeline)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/GetContactTimeline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) CreateReminder(ctx context.Context, in *Reminder, opts ...grpc.CallOption) (*Reminder, error) {
+	out := new(Reminder)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/CreateReminder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) ListReminders(ctx context.Context, in *ReminderRequest, opts ...grpc.CallOption) (*ReminderList, error) {
+	out := new(ReminderList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/ListReminders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) UpdateReminder(ctx context.Context, in *Reminder, opts ...grpc.CallOption) (*Reminder, error) {
+	out := new(Reminder)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/UpdateReminder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DeleteReminder(ctx context.Context, in *Reminder, opts ...grpc.CallOption) (*Reminder, error) {
+	out := new(Reminder)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteReminder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) LinkContacts(ctx context.Context, in *LinkContactsRequest, opts ...grpc.CallOption) (*RelatedContact, error) {
+	out := new(RelatedContact)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/LinkContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) UnlinkContacts(ctx context.Context, in *RelatedContact, opts ...grpc.CallOption) (*RelatedContact, error) {
+	out := new(RelatedContact)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/UnlinkContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) GetRelatedContacts(ctx context.Context, in *RelatedContactsRequest, opts ...grpc.CallOption) (*RelatedContactList, error) {
+	out := new(RelatedContactList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/GetRelatedContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) CreateOrganization(ctx context.Context, in *Organization, opts ...grpc.CallOption) (*Organization, error) {
+	out := new(Organization)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/CreateOrganization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) UpdateOrganization(ctx context.Context, in *Organization, opts ...grpc.CallOption) (*Organization, error) {
+	out := new(Organization)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/UpdateOrganization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DeleteOrganization(ctx context.Context, in *Organization, opts ...grpc.CallOption) (*Organization, error) {
+	out := new(Organization)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteOrganization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) ListOrganizations(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*OrganizationList, error) {
+	out := new(OrganizationList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/ListOrganizations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) GetOrganizationContacts(ctx context.Context, in *OrganizationRequest, opts ...grpc.CallOption) (*ContactList, error) {
+	out := new(ContactList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/GetOrganizationContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) SuggestOrganizations(ctx context.Context, in *SuggestOrganizationsRequest, opts ...grpc.CallOption) (*OrganizationList, error) {
+	out := new(OrganizationList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/SuggestOrganizations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) FindDuplicates(ctx context.Context, in *FindDuplicatesRequest, opts ...grpc.CallOption) (*DuplicateList, error) {
+	out := new(DuplicateList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/FindDuplicates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) MergeContacts(ctx context.Context, in *MergeContactsRequest, opts ...grpc.CallOption) (*MergeResult, error) {
+	out := new(MergeResult)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/MergeContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) UndoMerge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*ContactList, error) {
+	out := new(ContactList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/UndoMerge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) ListContactRevisions(ctx context.Context, in *RevisionsRequest, opts ...grpc.CallOption) (*RevisionList, error) {
+	out := new(RevisionList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/ListContactRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) RevertContact(ctx context.Context, in *RevertContactRequest, opts ...grpc.CallOption) (*Contact, error) {
+	out := new(Contact)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/RevertContact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) BatchCreateContacts(ctx context.Context, in *BatchCreateContactsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/BatchCreateContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) BatchUpdateContacts(ctx context.Context, in *BatchUpdateContactsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/BatchUpdateContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) BatchDeleteContacts(ctx context.Context, in *BatchDeleteContactsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/BatchDeleteContacts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) ImportContacts(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportContactsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[4], "/contact.ContactManager/ImportContacts", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerImportContactsClient{stream}
+	return x, nil
+}
+
+type ContactManager_ImportContactsClient interface {
+	Send(*Contact) error
+	CloseAndRecv() (*ImportSummary, error)
+	grpc.ClientStream
+}
+
+type contactManagerImportContactsClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerImportContactsClient) Send(m *Contact) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contactManagerImportContactsClient) CloseAndRecv() (*ImportSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ExportContacts(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (ContactManager_ExportContactsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[5], "/contact.ContactManager/ExportContacts", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerExportContactsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_ExportContactsClient interface {
+	Recv() (*Contact, error)
+	grpc.ClientStream
+}
+
+type contactManagerExportContactsClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerExportContactsClient) Recv() (*Contact, error) {
+	m := new(Contact)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ImportVCards(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportVCardsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[6], "/contact.ContactManager/ImportVCards", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerImportVCardsClient{stream}
+	return x, nil
+}
+
+type ContactManager_ImportVCardsClient interface {
+	Send(*VCardChunk) error
+	CloseAndRecv() (*ImportSummary, error)
+	grpc.ClientStream
+}
+
+type contactManagerImportVCardsClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerImportVCardsClient) Send(m *VCardChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contactManagerImportVCardsClient) CloseAndRecv() (*ImportSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ExportVCards(ctx context.Context, in *VCardExportRequest, opts ...grpc.CallOption) (ContactManager_ExportVCardsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[7], "/contact.ContactManager/ExportVCards", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerExportVCardsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_ExportVCardsClient interface {
+	Recv() (*VCardChunk, error)
+	grpc.ClientStream
+}
+
+type contactManagerExportVCardsClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerExportVCardsClient) Recv() (*VCardChunk, error) {
+	m := new(VCardChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ImportCSV(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportCSVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[8], "/contact.ContactManager/ImportCSV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerImportCSVClient{stream}
+	return x, nil
+}
+
+type ContactManager_ImportCSVClient interface {
+	Send(*CSVChunk) error
+	CloseAndRecv() (*ImportSummary, error)
+	grpc.ClientStream
+}
+
+type contactManagerImportCSVClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerImportCSVClient) Send(m *CSVChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contactManagerImportCSVClient) CloseAndRecv() (*ImportSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ExportCSV(ctx context.Context, in *CSVExportRequest, opts ...grpc.CallOption) (ContactManager_ExportCSVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[9], "/contact.ContactManager/ExportCSV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerExportCSVClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_ExportCSVClient interface {
+	Recv() (*CSVChunk, error)
+	grpc.ClientStream
+}
+
+type contactManagerExportCSVClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerExportCSVClient) Recv() (*CSVChunk, error) {
+	m := new(CSVChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ImportXLSX(ctx context.Context, opts ...grpc.CallOption) (ContactManager_ImportXLSXClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[10], "/contact.ContactManager/ImportXLSX", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerImportXLSXClient{stream}
+	return x, nil
+}
+
+type ContactManager_ImportXLSXClient interface {
+	Send(*XLSXChunk) error
+	CloseAndRecv() (*ImportSummary, error)
+	grpc.ClientStream
+}
+
+type contactManagerImportXLSXClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerImportXLSXClient) Send(m *XLSXChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *contactManagerImportXLSXClient) CloseAndRecv() (*ImportSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ExportXLSX(ctx context.Context, in *XLSXExportRequest, opts ...grpc.CallOption) (ContactManager_ExportXLSXClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[11], "/contact.ContactManager/ExportXLSX", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerExportXLSXClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_ExportXLSXClient interface {
+	Recv() (*XLSXChunk, error)
+	grpc.ClientStream
+}
+
+type contactManagerExportXLSXClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerExportXLSXClient) Recv() (*XLSXChunk, error) {
+	m := new(XLSXChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) ExportFile(ctx context.Context, in *FileExportRequest, opts ...grpc.CallOption) (ContactManager_ExportFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ContactManager_ServiceDesc.Streams[12], "/contact.ContactManager/ExportFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &contactManagerExportFileClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ContactManager_ExportFileClient interface {
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type contactManagerExportFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *contactManagerExportFileClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *contactManagerClient) AddAddressBook(ctx context.Context, in *AddressBook, opts ...grpc.CallOption) (*AddressBook, error) {
+	out := new(AddressBook)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/AddAddressBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) ListAddressBooks(ctx context.Context, in *AddressBookRequest, opts ...grpc.CallOption) (*AddressBookList, error) {
+	out := new(AddressBookList)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/ListAddressBooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) DeleteAddressBook(ctx context.Context, in *AddressBook, opts ...grpc.CallOption) (*AddressBook, error) {
+	out := new(AddressBook)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/DeleteAddressBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contactManagerClient) SyncAddressBook(ctx context.Context, in *AddressBook, opts ...grpc.CallOption) (*AddressBook, error) {
+	out := new(AddressBook)
+	err := c.cc.Invoke(ctx, "/contact.ContactManager/SyncAddressBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContactManagerServer is the server API for ContactManager service.
+// All implementations must embed UnimplementedContactManagerServer
+// for forward compatibility
+type ContactManagerServer interface {
+	NewContact(context.Context, *Contact) (*Contact, error)
+	GetContactByID(context.Context, *FindContactRequest) (*Contact, error)
+	GetUserContacts(context.Context, *User) (*ContactList, error)
+	// SyncContacts returns the contacts created, updated and deleted since the sync token, or all
+	// of them when it is empty, with the token to sync from next. It fails with FAILED_PRECONDITION
+	// when the token is older than the 30 days changes are kept: the client must then sync again
+	// without a token.
+	SyncContacts(context.Context, *SyncContactsRequest) (*ContactChanges, error)
+	// WatchContacts streams the changes to the user's contacts as they are made, from the sync
+	// token on when set, or from now. Every event carries the token to resume from. It fails with
+	// FAILED_PRECONDITION like SyncContacts when the token is too old.
+	WatchContacts(*WatchContactsRequest, ContactManager_WatchContactsServer) error
+	// UpdateContact changes the fields set in the contact and keeps the others, like
+	// PatchContact with an empty updateMask. Fields are cleared with an updateMask.
+	UpdateContact(context.Context, *Contact) (*Contact, error)
+	// PatchContact changes only the fields named by the update mask.
+	PatchContact(context.Context, *UpdateContactRequest) (*Contact, error)
+	UploadContactPhoto(ContactManager_UploadContactPhotoServer) error
+	GetContactPhoto(context.Context, *PhotoRequest) (*Photo, error)
+	DeleteContactPhoto(context.Context, *PhotoRequest) (*Photo, error)
+	StartAttachmentUpload(context.Context, *AttachmentUploadRequest) (*AttachmentUpload, error)
+	GetAttachmentUpload(context.Context, *AttachmentUpload) (*AttachmentUpload, error)
+	UploadAttachment(ContactManager_UploadAttachmentServer) error
+	ListAttachments(context.Context, *AttachmentRequest) (*AttachmentList, error)
+	DownloadAttachment(*AttachmentRequest, ContactManager_DownloadAttachmentServer) error
+	DeleteAttachment(context.Context, *AttachmentRequest) (*Attachment, error)
+	AddNote(context.Context, *Note) (*Note, error)
+	DeleteNote(context.Context, *Note) (*Note, error)
+	LogInteraction(context.Context, *Interaction) (*Interaction, error)
+	DeleteInteraction(context.Context, *Interaction) (*Interaction, error)
+	GetContactTimeline(context.Context, *TimelineRequest) (*Timeline, error)
+	CreateReminder(context.Context, *Reminder) (*Reminder, error)
+	ListReminders(context.Context, *ReminderRequest) (*ReminderList, error)
+	UpdateReminder(context.Context, *Reminder) (*Reminder, error)
+	DeleteReminder(context.Context, *Reminder) (*Reminder, error)
+	LinkContacts(context.Context, *LinkContactsRequest) (*RelatedContact, error)
+	UnlinkContacts(context.Context, *RelatedContact) (*RelatedContact, error)
+	GetRelatedContacts(context.Context, *RelatedContactsRequest) (*RelatedContactList, error)
+	CreateOrganization(context.Context, *Organization) (*Organization, error)
+	UpdateOrganization(context.Context, *Organization) (*Organization, error)
+	DeleteOrganization(context.Context, *Organization) (*Organization, error)
+	ListOrganizations(context.Context, *OrganizationRequest) (*OrganizationList, error)
+	GetOrganizationContacts(context.Context, *OrganizationRequest) (*ContactList, error)
+	SuggestOrganizations(context.Context, *SuggestOrganizationsRequest) (*OrganizationList, error)
+	FindDuplicates(context.Context, *FindDuplicatesRequest) (*DuplicateList, error)
+	MergeContacts(context.Context, *MergeContactsRequest) (*MergeResult, error)
+	UndoMerge(context.Context, *MergeRequest) (*ContactList, error)
+	ListContactRevisions(context.Context, *RevisionsRequest) (*RevisionList, error)
+	RevertContact(context.Context, *RevertContactRequest) (*Contact, error)
+	BatchCreateContacts(context.Context, *BatchCreateContactsRequest) (*BatchResponse, error)
+	BatchUpdateContacts(context.Context, *BatchUpdateContactsRequest) (*BatchResponse, error)
+	BatchDeleteContacts(context.Context, *BatchDeleteContactsRequest) (*BatchResponse, error)
+	// ImportContacts creates the streamed contacts. Contacts with the email of an existing
+	// contact are skipped, or updated when the duplicate-policy metadata is "update".
+	ImportContacts(ContactManager_ImportContactsServer) error
+	// ExportContacts streams the contacts of the user in id order.
+	ExportContacts(*ExportRequest, ContactManager_ExportContactsServer) error
+	// ImportVCards imports a vCard file sent in chunks, with the duplicate-policy metadata of ImportContacts.
+	ImportVCards(ContactManager_ImportVCardsServer) error
+	// ExportVCards streams the contacts of the user as a vCard file, in chunks.
+	ExportVCards(*VCardExportRequest, ContactManager_ExportVCardsServer) error
+	// ImportCSV imports a CSV file sent in chunks, with the duplicate-policy metadata of ImportContacts.
+	ImportCSV(ContactManager_ImportCSVServer) error
+	// ExportCSV streams the contacts of the user as a CSV file, in chunks.
+	ExportCSV(*CSVExportRequest, ContactManager_ExportCSVServer) error
+	// ImportXLSX imports an Excel workbook sent in chunks, with the duplicate-policy metadata of ImportContacts.
+	ImportXLSX(ContactManager_ImportXLSXServer) error
+	// ExportXLSX streams the contacts of the user as an Excel workbook, in chunks.
+	ExportXLSX(*XLSXExportRequest, ContactManager_ExportXLSXServer) error
+	// ExportFile streams the contacts of the user as a file in the requested format, in chunks.
+	// It is an addition next to ExportContacts, whose stream of contact messages has no format.
+	ExportFile(*FileExportRequest, ContactManager_ExportFileServer) error
+	// AddAddressBook subscribes the user to an external CardDAV address book, whose contacts
+	// are pulled on a schedule.
+	AddAddressBook(context.Context, *AddressBook) (*AddressBook, error)
+	ListAddressBooks(context.Context, *AddressBookRequest) (*AddressBookList, error)
+	// DeleteAddressBook stops syncing the address book. The synced contacts are kept.
+	DeleteAddressBook(context.Context, *AddressBook) (*AddressBook, error)
+	// SyncAddressBook makes the address book due, so it is synced within a minute.
+	SyncAddressBook(context.Context, *AddressBook) (*AddressBook, error)
+	mustEmbedUnimplementedContactManagerServer()
+}
+
+// UnimplementedContactManagerServer must be embedded to have forward compatible implementations.
+type UnimplementedContactManagerServer struct {
+}
+
+func (UnimplementedContactManagerServer) NewContact(context.Context, *Contact) (*Contact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewContact not implemented")
+}
+func (UnimplementedContactManagerServer) GetContactByID(context.Context, *FindContactRequest) (*Contact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContactByID not implemented")
+}
+func (UnimplementedContactManagerServer) GetUserContacts(context.Context, *User) (*ContactList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserContacts not implemented")
+}
+func (UnimplementedContactManagerServer) SyncContacts(context.Context, *SyncContactsRequest) (*ContactChanges, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncContacts not implemented")
+}
+func (UnimplementedContactManagerServer) WatchContacts(*WatchContactsRequest, ContactManager_WatchContactsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchContacts not implemented")
+}
+func (UnimplementedContactManagerServer) UpdateContact(context.Context, *Contact) (*Contact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateContact not implemented")
+}
+func (UnimplementedContactManagerServer) PatchContact(context.Context, *UpdateContactRequest) (*Contact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchContact not implemented")
+}
+func (UnimplementedContactManagerServer) UploadContactPhoto(ContactManager_UploadContactPhotoServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadContactPhoto not implemented")
+}
+func (UnimplementedContactManagerServer) GetContactPhoto(context.Context, *PhotoRequest) (*Photo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContactPhoto not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteContactPhoto(context.Context, *PhotoRequest) (*Photo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteContactPhoto not implemented")
+}
+func (UnimplementedContactManagerServer) StartAttachmentUpload(context.Context, *AttachmentUploadRequest) (*AttachmentUpload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartAttachmentUpload not implemented")
+}
+func (UnimplementedContactManagerServer) GetAttachmentUpload(context.Context, *AttachmentUpload) (*AttachmentUpload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAttachmentUpload not implemented")
+}
+func (UnimplementedContactManagerServer) UploadAttachment(ContactManager_UploadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (UnimplementedContactManagerServer) ListAttachments(context.Context, *AttachmentRequest) (*AttachmentList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAttachments not implemented")
+}
+func (UnimplementedContactManagerServer) DownloadAttachment(*AttachmentRequest, ContactManager_DownloadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteAttachment(context.Context, *AttachmentRequest) (*Attachment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAttachment not implemented")
+}
+func (UnimplementedContactManagerServer) AddNote(context.Context, *Note) (*Note, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddNote not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteNote(context.Context, *Note) (*Note, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNote not implemented")
+}
+func (UnimplementedContactManagerServer) LogInteraction(context.Context, *Interaction) (*Interaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogInteraction not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteInteraction(context.Context, *Interaction) (*Interaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteInteraction not implemented")
+}
+func (UnimplementedContactManagerServer) GetContactTimeline(context.Context, *TimelineRequest) (*Timeline, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContactTimeline not implemented")
+}
+func (UnimplementedContactManagerServer) CreateReminder(context.Context, *Reminder) (*Reminder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReminder not implemented")
+}
+func (UnimplementedContactManagerServer) ListReminders(context.Context, *ReminderRequest) (*ReminderList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReminders not implemented")
+}
+func (UnimplementedContactManagerServer) UpdateReminder(context.Context, *Reminder) (*Reminder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReminder not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteReminder(context.Context, *Reminder) (*Reminder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReminder not implemented")
+}
+func (UnimplementedContactManagerServer) LinkContacts(context.Context, *LinkContactsRequest) (*RelatedContact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LinkContacts not implemented")
+}
+func (UnimplementedContactManagerServer) UnlinkContacts(context.Context, *RelatedContact) (*RelatedContact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnlinkContacts not implemented")
+}
+func (UnimplementedContactManagerServer) GetRelatedContacts(context.Context, *RelatedContactsRequest) (*RelatedContactList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRelatedContacts not implemented")
+}
+func (UnimplementedContactManagerServer) CreateOrganization(context.Context, *Organization) (*Organization, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrganization not implemented")
+}
+func (UnimplementedContactManagerServer) UpdateOrganization(context.Context, *Organization) (*Organization, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOrganization not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteOrganization(context.Context, *Organization) (*Organization, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteOrganization not implemented")
+}
+func (UnimplementedContactManagerServer) ListOrganizations(context.Context, *OrganizationRequest) (*OrganizationList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrganizations not implemented")
+}
+func (UnimplementedContactManagerServer) GetOrganizationContacts(context.Context, *OrganizationRequest) (*ContactList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrganizationContacts not implemented")
+}
+func (UnimplementedContactManagerServer) SuggestOrganizations(context.Context, *SuggestOrganizationsRequest) (*OrganizationList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuggestOrganizations not implemented")
+}
+func (UnimplementedContactManagerServer) FindDuplicates(context.Context, *FindDuplicatesRequest) (*DuplicateList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDuplicates not implemented")
+}
+func (UnimplementedContactManagerServer) MergeContacts(context.Context, *MergeContactsRequest) (*MergeResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeContacts not implemented")
+}
+func (UnimplementedContactManagerServer) UndoMerge(context.Context, *MergeRequest) (*ContactList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndoMerge not implemented")
+}
+func (UnimplementedContactManagerServer) ListContactRevisions(context.Context, *RevisionsRequest) (*RevisionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListContactRevisions not implemented")
+}
+func (UnimplementedContactManagerServer) RevertContact(context.Context, *RevertContactRequest) (*Contact, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevertContact not implemented")
+}
+func (UnimplementedContactManagerServer) BatchCreateContacts(context.Context, *BatchCreateContactsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateContacts not implemented")
+}
+func (UnimplementedContactManagerServer) BatchUpdateContacts(context.Context, *BatchUpdateContactsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdateContacts not implemented")
+}
+func (UnimplementedContactManagerServer) BatchDeleteContacts(context.Context, *BatchDeleteContactsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteContacts not implemented")
+}
+func (UnimplementedContactManagerServer) ImportContacts(ContactManager_ImportContactsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportContacts not implemented")
+}
+func (UnimplementedContactManagerServer) ExportContacts(*ExportRequest, ContactManager_ExportContactsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportContacts not implemented")
+}
+func (UnimplementedContactManagerServer) ImportVCards(ContactManager_ImportVCardsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportVCards not implemented")
+}
+func (UnimplementedContactManagerServer) ExportVCards(*VCardExportRequest, ContactManager_ExportVCardsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportVCards not implemented")
+}
+func (UnimplementedContactManagerServer) ImportCSV(ContactManager_ImportCSVServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportCSV not implemented")
+}
+func (UnimplementedContactManagerServer) ExportCSV(*CSVExportRequest, ContactManager_ExportCSVServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportCSV not implemented")
+}
+func (UnimplementedContactManagerServer) ImportXLSX(ContactManager_ImportXLSXServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportXLSX not implemented")
+}
+func (UnimplementedContactManagerServer) ExportXLSX(*XLSXExportRequest, ContactManager_ExportXLSXServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportXLSX not implemented")
+}
+func (UnimplementedContactManagerServer) ExportFile(*FileExportRequest, ContactManager_ExportFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportFile not implemented")
+}
+func (UnimplementedContactManagerServer) AddAddressBook(context.Context, *AddressBook) (*AddressBook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAddressBook not implemented")
+}
+func (UnimplementedContactManagerServer) ListAddressBooks(context.Context, *AddressBookRequest) (*AddressBookList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAddressBooks not implemented")
+}
+func (UnimplementedContactManagerServer) DeleteAddressBook(context.Context, *AddressBook) (*AddressBook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAddressBook not implemented")
+}
+func (UnimplementedContactManagerServer) SyncAddressBook(context.Context, *AddressBook) (*AddressBook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncAddressBook not implemented")
+}
+func (UnimplementedContactManagerServer) mustEmbedUnimplementedContactManagerServer() {}
+
+// UnsafeContactManagerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContactManagerServer will
+// result in compilation errors.
+type UnsafeContactManagerServer interface {
+	mustEmbedUnimplementedContactManagerServer()
+}
+
+func RegisterContactManagerServer(s grpc.ServiceRegistrar, srv ContactManagerServer) {
+	s.RegisterService(&ContactManager_ServiceDesc, srv)
+}
+
+func _ContactManager_NewContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Contact)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).NewContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/NewContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).NewContact(ctx, req.(*Contact))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_GetContactByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetContactByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetContactByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetContactByID(ctx, req.(*FindContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_GetUserContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(User)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetUserContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetUserContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetUserContacts(ctx, req.(*User))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_SyncContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).SyncContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/SyncContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).SyncContacts(ctx, req.(*SyncContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_WatchContacts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchContactsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).WatchContacts(m, &contactManagerWatchContactsServer{stream})
+}
+
+type ContactManager_WatchContactsServer interface {
+	Send(*ContactEvent) error
+	grpc.ServerStream
+}
+
+type contactManagerWatchContactsServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerWatchContactsServer) Send(m *ContactEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_UpdateContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Contact)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).UpdateContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/UpdateContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).UpdateContact(ctx, req.(*Contact))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_PatchContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).PatchContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/PatchContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).PatchContact(ctx, req.(*UpdateContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_UploadContactPhoto_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContactManagerServer).UploadContactPhoto(&contactManagerUploadContactPhotoServer{stream})
+}
+
+type ContactManager_UploadContactPhotoServer interface {
+	SendAndClose(*Photo) error
+	Recv() (*PhotoChunk, error)
+	grpc.ServerStream
+}
+
+type contactManagerUploadContactPhotoServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerUploadContactPhotoServer) SendAndClose(m *Photo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *contactManagerUploadContactPhotoServer) Recv() (*PhotoChunk, error) {
+	m := new(PhotoChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ContactManager_GetContactPhoto_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PhotoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetContactPhoto(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetContactPhoto",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetContactPhoto(ctx, req.(*PhotoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_DeleteContactPhoto_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PhotoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).DeleteContactPhoto(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/DeleteContactPhoto",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).DeleteContactPhoto(ctx, req.(*PhotoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_StartAttachmentUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).StartAttachmentUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/StartAttachmentUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).StartAttachmentUpload(ctx, req.(*AttachmentUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_GetAttachmentUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentUpload)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetAttachmentUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetAttachmentUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetAttachmentUpload(ctx, req.(*AttachmentUpload))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContactManagerServer).UploadAttachment(&contactManagerUploadAttachmentServer{stream})
+}
+
+type ContactManager_UploadAttachmentServer interface {
+	SendAndClose(*AttachmentUpload) error
+	Recv() (*AttachmentChunk, error)
+	grpc.ServerStream
+}
+
+type contactManagerUploadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerUploadAttachmentServer) SendAndClose(m *AttachmentUpload) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *contactManagerUploadAttachmentServer) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ContactManager_ListAttachments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).ListAttachments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/ListAttachments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).ListAttachments(ctx, req.(*AttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AttachmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).DownloadAttachment(m, &contactManagerDownloadAttachmentServer{stream})
+}
+
+type ContactManager_DownloadAttachmentServer interface {
+	Send(*AttachmentChunk) error
+	grpc.ServerStream
+}
+
+type contactManagerDownloadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerDownloadAttachmentServer) Send(m *AttachmentChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_DeleteAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).DeleteAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/DeleteAttachment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).DeleteAttachment(ctx, req.(*AttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_AddNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Note)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).AddNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/AddNote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).AddNote(ctx, req.(*Note))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_DeleteNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Note)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).DeleteNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/DeleteNote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).DeleteNote(ctx, req.(*Note))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_LogInteraction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Interaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).LogInteraction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/LogInteraction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).LogInteraction(ctx, req.(*Interaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_DeleteInteraction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Interaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).DeleteInteraction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/DeleteInteraction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).DeleteInteraction(ctx, req.(*Interaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_GetContactTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetContactTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetContactTimeline",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetContactTimeline(ctx, req.(*TimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_CreateReminder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reminder)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).CreateReminder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/CreateReminder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).CreateReminder(ctx, req.(*Reminder))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_ListReminders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReminderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).ListReminders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/ListReminders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).ListReminders(ctx, req.(*ReminderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_UpdateReminder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reminder)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).UpdateReminder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/UpdateReminder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).UpdateReminder(ctx, req.(*Reminder))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_DeleteReminder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reminder)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).DeleteReminder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/DeleteReminder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).DeleteReminder(ctx, req.(*Reminder))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_LinkContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).LinkContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/LinkContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).LinkContacts(ctx, req.(*LinkContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_UnlinkContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelatedContact)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).UnlinkContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/UnlinkContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).UnlinkContacts(ctx, req.(*RelatedContact))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_GetRelatedContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelatedContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetRelatedContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetRelatedContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetRelatedContacts(ctx, req.(*RelatedContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_CreateOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Organization)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).CreateOrganization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/CreateOrganization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).CreateOrganization(ctx, req.(*Organization))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_UpdateOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Organization)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).UpdateOrganization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/UpdateOrganization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).UpdateOrganization(ctx, req.(*Organization))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_DeleteOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Organization)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).DeleteOrganization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/DeleteOrganization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).DeleteOrganization(ctx, req.(*Organization))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_ListOrganizations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrganizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).ListOrganizations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/ListOrganizations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).ListOrganizations(ctx, req.(*OrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_GetOrganizationContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrganizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).GetOrganizationContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/GetOrganizationContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).GetOrganizationContacts(ctx, req.(*OrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_SuggestOrganizations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestOrganizationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).SuggestOrganizations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/SuggestOrganizations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).SuggestOrganizations(ctx, req.(*SuggestOrganizationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_FindDuplicates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindDuplicatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).FindDuplicates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/FindDuplicates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).FindDuplicates(ctx, req.(*FindDuplicatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_MergeContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).MergeContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/MergeContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).MergeContacts(ctx, req.(*MergeContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_UndoMerge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).UndoMerge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/UndoMerge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).UndoMerge(ctx, req.(*MergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_ListContactRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).ListContactRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/ListContactRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).ListContactRevisions(ctx, req.(*RevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_RevertContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertContactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).RevertContact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/RevertContact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).RevertContact(ctx, req.(*RevertContactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_BatchCreateContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).BatchCreateContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/BatchCreateContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).BatchCreateContacts(ctx, req.(*BatchCreateContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContactManager_BatchUpdateContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).BatchUpdateContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/BatchUpdateContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).BatchUpdateContacts(ctx, req.(*BatchUpdateContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// ContactManagerServer is the server API for ContactManager service.
-// All implementations must embed UnimplementedContactManagerServer
-// for forward compatibility
-type ContactManagerServer interface {
-	NewContact(context.Context, *Contact) (*Contact, error)
-	GetContactByID(context.Context, *FindContactRequest) (*Contact, error)
-	GetUserContacts(context.Context, *User) (*ContactList, error)
-	UpdateContact(context.Context, *Contact) (*Contact, error)
-	mustEmbedUnimplementedContactManagerServer()
+func _ContactManager_BatchDeleteContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteContactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContactManagerServer).BatchDeleteContacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/contact.ContactManager/BatchDeleteContacts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContactManagerServer).BatchDeleteContacts(ctx, req.(*BatchDeleteContactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedContactManagerServer must be embedded to have forward compatible implementations.
-type UnimplementedContactManagerServer struct {
+func _ContactManager_ImportContacts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContactManagerServer).ImportContacts(&contactManagerImportContactsServer{stream})
 }
 
-func (UnimplementedContactManagerServer) NewContact(context.Context, *Contact) (*Contact, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method NewContact not implemented")
+type ContactManager_ImportContactsServer interface {
+	SendAndClose(*ImportSummary) error
+	Recv() (*Contact, error)
+	grpc.ServerStream
 }
-func (UnimplementedContactManagerServer) GetContactByID(context.Context, *FindContactRequest) (*Contact, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetContactByID not implemented")
+
+type contactManagerImportContactsServer struct {
+	grpc.ServerStream
 }
-func (UnimplementedContactManagerServer) GetUserContacts(context.Context, *User) (*ContactList, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetUserContacts not implemented")
+
+func (x *contactManagerImportContactsServer) SendAndClose(m *ImportSummary) error {
+	return x.ServerStream.SendMsg(m)
 }
-func (UnimplementedContactManagerServer) UpdateContact(context.Context, *Contact) (*Contact, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateContact not implemented")
+
+func (x *contactManagerImportContactsServer) Recv() (*Contact, error) {
+	m := new(Contact)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
-func (UnimplementedContactManagerServer) mustEmbedUnimplementedContactManagerServer() {}
 
-// UnsafeContactManagerServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to ContactManagerServer will
-// result in compilation errors.
-type UnsafeContactManagerServer interface {
-	mustEmbedUnimplementedContactManagerServer()
+func _ContactManager_ExportContacts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).ExportContacts(m, &contactManagerExportContactsServer{stream})
 }
 
-func RegisterContactManagerServer(s grpc.ServiceRegistrar, srv ContactManagerServer) {
-	s.RegisterService(&ContactManager_ServiceDesc, srv)
+type ContactManager_ExportContactsServer interface {
+	Send(*Contact) error
+	grpc.ServerStream
 }
 
-func _ContactManager_NewContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Contact)
+type contactManagerExportContactsServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerExportContactsServer) Send(m *Contact) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_ImportVCards_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContactManagerServer).ImportVCards(&contactManagerImportVCardsServer{stream})
+}
+
+type ContactManager_ImportVCardsServer interface {
+	SendAndClose(*ImportSummary) error
+	Recv() (*VCardChunk, error)
+	grpc.ServerStream
+}
+
+type contactManagerImportVCardsServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerImportVCardsServer) SendAndClose(m *ImportSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *contactManagerImportVCardsServer) Recv() (*VCardChunk, error) {
+	m := new(VCardChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ContactManager_ExportVCards_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(VCardExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).ExportVCards(m, &contactManagerExportVCardsServer{stream})
+}
+
+type ContactManager_ExportVCardsServer interface {
+	Send(*VCardChunk) error
+	grpc.ServerStream
+}
+
+type contactManagerExportVCardsServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerExportVCardsServer) Send(m *VCardChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_ImportCSV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContactManagerServer).ImportCSV(&contactManagerImportCSVServer{stream})
+}
+
+type ContactManager_ImportCSVServer interface {
+	SendAndClose(*ImportSummary) error
+	Recv() (*CSVChunk, error)
+	grpc.ServerStream
+}
+
+type contactManagerImportCSVServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerImportCSVServer) SendAndClose(m *ImportSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *contactManagerImportCSVServer) Recv() (*CSVChunk, error) {
+	m := new(CSVChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ContactManager_ExportCSV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CSVExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).ExportCSV(m, &contactManagerExportCSVServer{stream})
+}
+
+type ContactManager_ExportCSVServer interface {
+	Send(*CSVChunk) error
+	grpc.ServerStream
+}
+
+type contactManagerExportCSVServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerExportCSVServer) Send(m *CSVChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_ImportXLSX_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ContactManagerServer).ImportXLSX(&contactManagerImportXLSXServer{stream})
+}
+
+type ContactManager_ImportXLSXServer interface {
+	SendAndClose(*ImportSummary) error
+	Recv() (*XLSXChunk, error)
+	grpc.ServerStream
+}
+
+type contactManagerImportXLSXServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerImportXLSXServer) SendAndClose(m *ImportSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *contactManagerImportXLSXServer) Recv() (*XLSXChunk, error) {
+	m := new(XLSXChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ContactManager_ExportXLSX_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(XLSXExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).ExportXLSX(m, &contactManagerExportXLSXServer{stream})
+}
+
+type ContactManager_ExportXLSXServer interface {
+	Send(*XLSXChunk) error
+	grpc.ServerStream
+}
+
+type contactManagerExportXLSXServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerExportXLSXServer) Send(m *XLSXChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_ExportFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FileExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContactManagerServer).ExportFile(m, &contactManagerExportFileServer{stream})
+}
+
+type ContactManager_ExportFileServer interface {
+	Send(*FileChunk) error
+	grpc.ServerStream
+}
+
+type contactManagerExportFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *contactManagerExportFileServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ContactManager_AddAddressBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressBook)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContactManagerServer).NewContact(ctx, in)
+		return srv.(ContactManagerServer).AddAddressBook(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/contact.ContactManager/NewContact",
+		FullMethod: "/contact.ContactManager/AddAddressBook",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContactManagerServer).NewContact(ctx, req.(*Contact))
+		return srv.(ContactManagerServer).AddAddressBook(ctx, req.(*AddressBook))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContactManager_GetContactByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FindContactRequest)
+func _ContactManager_ListAddressBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressBookRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContactManagerServer).GetContactByID(ctx, in)
+		return srv.(ContactManagerServer).ListAddressBooks(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/contact.ContactManager/GetContactByID",
+		FullMethod: "/contact.ContactManager/ListAddressBooks",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContactManagerServer).GetContactByID(ctx, req.(*FindContactRequest))
+		return srv.(ContactManagerServer).ListAddressBooks(ctx, req.(*AddressBookRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContactManager_GetUserContacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(User)
+func _ContactManager_DeleteAddressBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressBook)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContactManagerServer).GetUserContacts(ctx, in)
+		return srv.(ContactManagerServer).DeleteAddressBook(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/contact.ContactManager/GetUserContacts",
+		FullMethod: "/contact.ContactManager/DeleteAddressBook",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContactManagerServer).GetUserContacts(ctx, req.(*User))
+		return srv.(ContactManagerServer).DeleteAddressBook(ctx, req.(*AddressBook))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ContactManager_UpdateContact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Contact)
+func _ContactManager_SyncAddressBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressBook)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ContactManagerServer).UpdateContact(ctx, in)
+		return srv.(ContactManagerServer).SyncAddressBook(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/contact.ContactManager/UpdateContact",
+		FullMethod: "/contact.ContactManager/SyncAddressBook",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContactManagerServer).UpdateContact(ctx, req.(*Contact))
+		return srv.(ContactManagerServer).SyncAddressBook(ctx, req.(*AddressBook))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -199,12 +2261,230 @@ var ContactManager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserContacts",
 			Handler:    _ContactManager_GetUserContacts_Handler,
 		},
+		{
+			MethodName: "SyncContacts",
+			Handler:    _ContactManager_SyncContacts_Handler,
+		},
 		{
 			MethodName: "UpdateContact",
 			Handler:    _ContactManager_UpdateContact_Handler,
 		},
+		{
+			MethodName: "PatchContact",
+			Handler:    _ContactManager_PatchContact_Handler,
+		},
+		{
+			MethodName: "GetContactPhoto",
+			Handler:    _ContactManager_GetContactPhoto_Handler,
+		},
+		{
+			MethodName: "DeleteContactPhoto",
+			Handler:    _ContactManager_DeleteContactPhoto_Handler,
+		},
+		{
+			MethodName: "StartAttachmentUpload",
+			Handler:    _ContactManager_StartAttachmentUpload_Handler,
+		},
+		{
+			MethodName: "GetAttachmentUpload",
+			Handler:    _ContactManager_GetAttachmentUpload_Handler,
+		},
+		{
+			MethodName: "ListAttachments",
+			Handler:    _ContactManager_ListAttachments_Handler,
+		},
+		{
+			MethodName: "DeleteAttachment",
+			Handler:    _ContactManager_DeleteAttachment_Handler,
+		},
+		{
+			MethodName: "AddNote",
+			Handler:    _ContactManager_AddNote_Handler,
+		},
+		{
+			MethodName: "DeleteNote",
+			Handler:    _ContactManager_DeleteNote_Handler,
+		},
+		{
+			MethodName: "LogInteraction",
+			Handler:    _ContactManager_LogInteraction_Handler,
+		},
+		{
+			MethodName: "DeleteInteraction",
+			Handler:    _ContactManager_DeleteInteraction_Handler,
+		},
+		{
+			MethodName: "GetContactTimeline",
+			Handler:    _ContactManager_GetContactTimeline_Handler,
+		},
+		{
+			MethodName: "CreateReminder",
+			Handler:    _ContactManager_CreateReminder_Handler,
+		},
+		{
+			MethodName: "ListReminders",
+			Handler:    _ContactManager_ListReminders_Handler,
+		},
+		{
+			MethodName: "UpdateReminder",
+			Handler:    _ContactManager_UpdateReminder_Handler,
+		},
+		{
+			MethodName: "DeleteReminder",
+			Handler:    _ContactManager_DeleteReminder_Handler,
+		},
+		{
+			MethodName: "LinkContacts",
+			Handler:    _ContactManager_LinkContacts_Handler,
+		},
+		{
+			MethodName: "UnlinkContacts",
+			Handler:    _ContactManager_UnlinkContacts_Handler,
+		},
+		{
+			MethodName: "GetRelatedContacts",
+			Handler:    _ContactManager_GetRelatedContacts_Handler,
+		},
+		{
+			MethodName: "CreateOrganization",
+			Handler:    _ContactManager_CreateOrganization_Handler,
+		},
+		{
+			MethodName: "UpdateOrganization",
+			Handler:    _ContactManager_UpdateOrganization_Handler,
+		},
+		{
+			MethodName: "DeleteOrganization",
+			Handler:    _ContactManager_DeleteOrganization_Handler,
+		},
+		{
+			MethodName: "ListOrganizations",
+			Handler:    _ContactManager_ListOrganizations_Handler,
+		},
+		{
+			MethodName: "GetOrganizationContacts",
+			Handler:    _ContactManager_GetOrganizationContacts_Handler,
+		},
+		{
+			MethodName: "SuggestOrganizations",
+			Handler:    _ContactManager_SuggestOrganizations_Handler,
+		},
+		{
+			MethodName: "FindDuplicates",
+			Handler:    _ContactManager_FindDuplicates_Handler,
+		},
+		{
+			MethodName: "MergeContacts",
+			Handler:    _ContactManager_MergeContacts_Handler,
+		},
+		{
+			MethodName: "UndoMerge",
+			Handler:    _ContactManager_UndoMerge_Handler,
+		},
+		{
+			MethodName: "ListContactRevisions",
+			Handler:    _ContactManager_ListContactRevisions_Handler,
+		},
+		{
+			MethodName: "RevertContact",
+			Handler:    _ContactManager_RevertContact_Handler,
+		},
+		{
+			MethodName: "BatchCreateContacts",
+			Handler:    _ContactManager_BatchCreateContacts_Handler,
+		},
+		{
+			MethodName: "BatchUpdateContacts",
+			Handler:    _ContactManager_BatchUpdateContacts_Handler,
+		},
+		{
+			MethodName: "BatchDeleteContacts",
+			Handler:    _ContactManager_BatchDeleteContacts_Handler,
+		},
+		{
+			MethodName: "AddAddressBook",
+			Handler:    _ContactManager_AddAddressBook_Handler,
+		},
+		{
+			MethodName: "ListAddressBooks",
+			Handler:    _ContactManager_ListAddressBooks_Handler,
+		},
+		{
+			MethodName: "DeleteAddressBook",
+			Handler:    _ContactManager_DeleteAddressBook_Handler,
+		},
+		{
+			MethodName: "SyncAddressBook",
+			Handler:    _ContactManager_SyncAddressBook_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchContacts",
+			Handler:       _ContactManager_WatchContacts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadContactPhoto",
+			Handler:       _ContactManager_UploadContactPhoto_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _ContactManager_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _ContactManager_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportContacts",
+			Handler:       _ContactManager_ImportContacts_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportContacts",
+			Handler:       _ContactManager_ExportContacts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportVCards",
+			Handler:       _ContactManager_ImportVCards_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportVCards",
+			Handler:       _ContactManager_ExportVCards_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportCSV",
+			Handler:       _ContactManager_ImportCSV_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportCSV",
+			Handler:       _ContactManager_ExportCSV_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportXLSX",
+			Handler:       _ContactManager_ImportXLSX_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportXLSX",
+			Handler:       _ContactManager_ExportXLSX_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportFile",
+			Handler:       _ContactManager_ExportFile_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "contact/contact.proto",
 }
 
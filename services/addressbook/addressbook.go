@@ -0,0 +1,272 @@
+// Package addressbook pulls contacts from external CardDAV address books, such as Nextcloud or
+// Fastmail accounts, into the contacts of a user. Every address book is synced on a schedule,
+// fetching only the cards changed since the last sync.
+package addressbook
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"grpc-contact-manager/services/lease"
+
+	"gorm.io/gorm"
+)
+
+// Conflict policies, deciding which side wins when a contact changed both locally and in the
+// external address book since the last sync.
+const (
+	// PreferRemote overwrites local changes with the external card.
+	PreferRemote = "remote"
+	// PreferLocal keeps local changes and skips the external card.
+	PreferLocal = "local"
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+	errInvalidURL         = errors.New("url must be an http or https url")
+	errInvalidPolicy      = errors.New("conflicts must be remote or local")
+	errLeaseLost          = errors.New("address book was claimed by another syncer")
+
+	// ErrNotFound is returned when the address book doesn't exist or belongs to another user.
+	ErrNotFound = errors.New("address book not found")
+)
+
+// Source is an external address book the user pulls contacts from.
+type Source struct {
+	gorm.Model
+	UserID uint   `json:"user_id" gorm:"column:user_id;index"`
+	Name   string `json:"name"`
+	// URL is the address book, or the CardDAV server it is discovered from on the first sync.
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	// Password is kept to sync unattended, so it should be an app password. It is only read by
+	// Create, which stores it encrypted in SealedPassword.
+	Password       string `json:"-" gorm:"-"`
+	SealedPassword []byte `json:"-"`
+	Conflicts      string `json:"conflicts"`
+	// AddressBook is the path of the address book found on the server.
+	AddressBook  string     `json:"address_book"`
+	SyncToken    string     `json:"-"`
+	NextSyncAt   time.Time  `json:"next_sync_at" gorm:"index"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	// LockedUntil is set while a syncer syncs the address book, like Reminder.LockedUntil.
+	LockedUntil *time.Time `json:"-" gorm:"index"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error"`
+}
+
+// Actor is the actor recorded on the revisions of the contacts written by syncs of the source.
+func (s *Source) Actor() string {
+	return "addressbook:" + strconv.FormatUint(uint64(s.ID), 10)
+}
+
+// Link ties a card of a source to the contact it was synced to.
+type Link struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	SourceID  uint   `json:"source_id" gorm:"column:source_id;uniqueIndex:idx_addressbook_link_href"`
+	Href      string `json:"href" gorm:"uniqueIndex:idx_addressbook_link_href"`
+	ContactID uint   `json:"contact_id" gorm:"column:contact_id;index"`
+	ETag      string `json:"etag"`
+	// Version is the version of the contact as last synced. The contact was changed locally
+	// when its version differs.
+	Version uint `json:"version"`
+}
+
+// TableName keeps the links apart from the other kinds of links.
+func (Link) TableName() string {
+	return "addressbook_links"
+}
+
+// DB - address book repository
+type DB struct {
+	Conn *gorm.DB
+	// Key encrypts the passwords of the address books at rest. It must be KeySize bytes.
+	Key []byte
+}
+
+// New creates a new instance of the address book repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the sources and links tables, encrypting the passwords stored in plain text.
+func (d *DB) Migrate() error {
+	if err := d.Conn.AutoMigrate(Source{}, Link{}); err != nil {
+		return err
+	}
+	return d.sealPlainPasswords()
+}
+
+// Create adds an address book for the user, due to be synced right away.
+func (d *DB) Create(s Source) (*Source, error) {
+	if s.Conflicts == "" {
+		s.Conflicts = PreferRemote
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if s.Name == "" {
+		u, _ := url.Parse(s.URL)
+		s.Name = u.Host
+	}
+	if s.Password != "" {
+		sealed, err := d.seal(s.Password)
+		if err != nil {
+			return nil, err
+		}
+		s.Password, s.SealedPassword = "", sealed
+	}
+	s.NextSyncAt = time.Now()
+	return &s, d.Conn.Create(&s).Error
+}
+
+// Find returns the address book with the given ID.
+func (d *DB) Find(userID, id uint) (*Source, error) {
+	var s Source
+	err := d.Conn.Where("user_id = ?", userID).First(&s, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// FindByUserID returns the address books of the user.
+func (d *DB) FindByUserID(userID uint) ([]Source, error) {
+	var sources []Source
+	res := d.Conn.Where("user_id = ?", userID).Order("id").Find(&sources)
+	return sources, res.Error
+}
+
+// Delete removes an address book and its links. The synced contacts are kept.
+func (d *DB) Delete(userID, id uint) (*Source, error) {
+	s, err := d.Find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	err = d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_id = ?", s.ID).Delete(&Link{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(s).Error
+	})
+	return s, err
+}
+
+// SyncNow makes the address book due, so it is synced on the next poll.
+func (d *DB) SyncNow(userID, id uint) (*Source, error) {
+	s, err := d.Find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.NextSyncAt = time.Now()
+	return s, d.Conn.Model(s).Update("next_sync_at", s.NextSyncAt).Error
+}
+
+// Claim locks up to limit due address books for syncing for the hold duration. Address books
+// locked by another syncer are skipped, so several instances can run side by side.
+func (d *DB) Claim(now time.Time, hold time.Duration, limit int) ([]Source, error) {
+	var due []Source
+	err := d.Conn.Scopes(lease.Available(now)).Where("next_sync_at <= ?", now).
+		Order("next_sync_at").Limit(limit).Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	until := lease.Until(now, hold)
+	claimed := due[:0]
+	for _, s := range due {
+		ok, err := lease.Claim(d.Conn.Model(&Source{}), s.ID, now, until)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			s.LockedUntil = &until
+			s.Attempts++
+			claimed = append(claimed, s)
+		}
+	}
+	return claimed, nil
+}
+
+// Renew extends the lock of a claimed address book until until. It fails with errLeaseLost
+// when another syncer claimed it since.
+func (d *DB) Renew(s *Source, until time.Time) error {
+	held, err := lease.Renew(d.Conn.Model(&Source{}).Where("id = ?", s.ID), *s.LockedUntil, until)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return errLeaseLost
+	}
+	s.LockedUntil = &until
+	return nil
+}
+
+// Synced records a successful sync of a claimed address book, scheduling the next one.
+func (d *DB) Synced(s *Source, at, next time.Time) error {
+	s.LastSyncedAt, s.NextSyncAt = &at, next
+	return d.Conn.Model(s).Updates(map[string]interface{}{
+		"address_book":   s.AddressBook,
+		"sync_token":     s.SyncToken,
+		"last_synced_at": at,
+		"next_sync_at":   next,
+		"locked_until":   nil,
+		"attempts":       0,
+		"last_error":     "",
+	}).Error
+}
+
+// Failed releases a claimed address book so it is synced again at or after retryAt.
+func (d *DB) Failed(s *Source, cause error, retryAt time.Time) error {
+	return d.Conn.Model(s).Updates(map[string]interface{}{
+		"next_sync_at": retryAt,
+		"locked_until": nil,
+		"last_error":   cause.Error(),
+	}).Error
+}
+
+// Links returns the links of the source by href.
+func (d *DB) Links(sourceID uint) (map[string]*Link, error) {
+	var links []Link
+	if err := d.Conn.Where("source_id = ?", sourceID).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	byHref := make(map[string]*Link, len(links))
+	for i := range links {
+		byHref[links[i].Href] = &links[i]
+	}
+	return byHref, nil
+}
+
+// SaveLink creates or updates the link.
+func (d *DB) SaveLink(l *Link) error {
+	return d.Conn.Save(l).Error
+}
+
+// DeleteLink removes the link.
+func (d *DB) DeleteLink(l *Link) error {
+	return d.Conn.Delete(l).Error
+}
+
+func (s *Source) validate() error {
+	if s.UserID == 0 {
+		return errInvalidUserID
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidURL
+	}
+	if s.Conflicts != PreferRemote && s.Conflicts != PreferLocal {
+		return errInvalidPolicy
+	}
+	return nil
+}
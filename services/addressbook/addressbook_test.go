@@ -0,0 +1,167 @@
+package addressbook
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"grpc-contact-manager/services/carddav"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db       *DB
+	contacts *contact.DB
+	users    *user.DB
+	objects  *carddav.DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d.Key = bytes.Repeat([]byte{7}, KeySize)
+	db = d
+	users = &user.DB{Conn: conn}
+	contacts = &contact.DB{Conn: conn}
+	objects = &carddav.DB{Conn: conn}
+	for _, repo := range []interface{ Migrate() error }{users, contacts, objects, db} {
+		if err := repo.Migrate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+func TestCreate(t *testing.T) {
+	table := []struct {
+		name   string
+		source Source
+		want   error
+	}{
+		{
+			name:   "All good",
+			source: Source{UserID: 1, URL: "https://dav.example.com/", Username: "ada"},
+		},
+		{
+			name:   "No user",
+			source: Source{URL: "https://dav.example.com/"},
+			want:   errInvalidUserID,
+		},
+		{
+			name:   "Not a url",
+			source: Source{UserID: 1, URL: "dav.example.com"},
+			want:   errInvalidURL,
+		},
+		{
+			name:   "Unknown policy",
+			source: Source{UserID: 1, URL: "https://dav.example.com/", Conflicts: "newest"},
+			want:   errInvalidPolicy,
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := db.Create(tt.source)
+			if tt.want != nil {
+				assert.Equal(t, tt.want, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "dav.example.com", s.Name)
+			assert.Equal(t, PreferRemote, s.Conflicts)
+			assert.False(t, s.NextSyncAt.After(time.Now()))
+		})
+	}
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestClaim(t *testing.T) {
+	s, err := db.Create(Source{UserID: 1, URL: "https://dav.example.com/"})
+	require.NoError(t, err)
+	now := time.Now()
+
+	claimed, err := db.Claim(now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// a claimed address book is locked for the lease
+	claimed, err = db.Claim(now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, db.Synced(s, now, now.Add(time.Hour)))
+	claimed, err = db.Claim(now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	_, err = db.SyncNow(1, s.ID)
+	require.NoError(t, err)
+	claimed, err = db.Claim(time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	_, err = db.SyncNow(2, s.ID)
+	assert.Equal(t, ErrNotFound, err)
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestPasswords(t *testing.T) {
+	s, err := db.Create(Source{UserID: 1, URL: "https://dav.example.com/", Username: "ada", Password: "app-password"})
+	require.NoError(t, err)
+	assert.Empty(t, s.Password)
+	found, err := db.Find(1, s.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, string(found.SealedPassword), "app-password")
+	password, err := db.password(found)
+	require.NoError(t, err)
+	assert.Equal(t, "app-password", password)
+
+	_, err = (&DB{Conn: db.Conn}).Create(Source{UserID: 1, URL: "https://dav.example.com/", Password: "app-password"})
+	assert.Equal(t, errNoKey, err)
+	_, err = (&DB{Conn: db.Conn, Key: []byte("short")}).password(found)
+	assert.Equal(t, errInvalidKey, err)
+	_, err = (&DB{Conn: db.Conn, Key: bytes.Repeat([]byte{8}, KeySize)}).password(found)
+	assert.Equal(t, errSealedPassword, err)
+
+	// passwords stored in plain text are encrypted by the migration
+	require.NoError(t, db.Conn.Exec("ALTER TABLE sources ADD COLUMN `password` text").Error)
+	require.NoError(t, db.Conn.Exec("UPDATE sources SET password = ?, sealed_password = NULL WHERE id = ?", "plain", s.ID).Error)
+	require.NoError(t, db.Migrate())
+	assert.False(t, db.Conn.Migrator().HasColumn(&Source{}, "password"))
+	found, err = db.Find(1, s.ID)
+	require.NoError(t, err)
+	password, err = db.password(found)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", password)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func cleanup() error {
+	for _, table := range []string{"addressbook_links", "sources", "carddav_objects", "revisions", "contacts", "organizations", "users"} {
+		if err := db.Conn.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package addressbook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var errForbiddenAddress = errors.New("address book must be on a public address")
+
+// newHTTPClient creates the client the requests to the CardDAV servers are sent with. Its
+// dialer refuses addresses that aren't public once the host is resolved, so address books
+// can't reach the internal network, neither directly nor through redirects.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}
+	return &http.Client{
+		Timeout: requestTimeout,
+		// no proxy, as it would be the one address dialed
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// publicOnly is the control of the dialer, run with the resolved address of every connection.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !public(ip) {
+		return errForbiddenAddress
+	}
+	return nil
+}
+
+// public tells if the address is reachable from the internet, as opposed to loopback, private,
+// link-local and other special addresses.
+func public(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
@@ -0,0 +1,92 @@
+package addressbook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize is the size of the key the passwords of address books are encrypted with, in bytes.
+const KeySize = 32
+
+var (
+	errNoKey          = errors.New("address book passwords can't be stored without a key")
+	errInvalidKey     = fmt.Errorf("address book key must be %d bytes", KeySize)
+	errSealedPassword = errors.New("address book password can't be decrypted")
+)
+
+// seal encrypts the password with AES-GCM under the key of the DB, prefixed with its nonce.
+func (d *DB) seal(password string) ([]byte, error) {
+	aead, err := d.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(password)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, []byte(password), nil), nil
+}
+
+// password decrypts the password of the address book, empty when it has none.
+func (d *DB) password(s *Source) (string, error) {
+	if len(s.SealedPassword) == 0 {
+		return "", nil
+	}
+	aead, err := d.aead()
+	if err != nil {
+		return "", err
+	}
+	n := aead.NonceSize()
+	if len(s.SealedPassword) < n {
+		return "", errSealedPassword
+	}
+	plain, err := aead.Open(nil, s.SealedPassword[:n], s.SealedPassword[n:], nil)
+	if err != nil {
+		return "", errSealedPassword
+	}
+	return string(plain), nil
+}
+
+func (d *DB) aead() (cipher.AEAD, error) {
+	if len(d.Key) == 0 {
+		return nil, errNoKey
+	}
+	if len(d.Key) != KeySize {
+		return nil, errInvalidKey
+	}
+	block, err := aes.NewCipher(d.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealPlainPasswords encrypts the passwords stored in plain text by earlier versions and drops
+// their column.
+func (d *DB) sealPlainPasswords() error {
+	m := d.Conn.Migrator()
+	if !m.HasColumn(&Source{}, "password") {
+		return nil
+	}
+	var plain []struct {
+		ID       uint
+		Password string
+	}
+	if err := d.Conn.Table("sources").Select("id, password").Where("password <> ''").Scan(&plain).Error; err != nil {
+		return err
+	}
+	for _, p := range plain {
+		sealed, err := d.seal(p.Password)
+		if err != nil {
+			return err
+		}
+		if err := d.Conn.Model(&Source{}).Where("id = ?", p.ID).Update("sealed_password", sealed).Error; err != nil {
+			return err
+		}
+	}
+	return m.DropColumn(&Source{}, "password")
+}
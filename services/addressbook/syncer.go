@@ -0,0 +1,439 @@
+package addressbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/lease"
+	"grpc-contact-manager/services/vcard"
+
+	"github.com/emersion/go-webdav"
+	"github.com/emersion/go-webdav/carddav"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultInterval is how often the syncer looks for due address books.
+	DefaultInterval = time.Minute
+	// DefaultEvery is the time between two syncs of an address book.
+	DefaultEvery = 15 * time.Minute
+	// DefaultLease is how long a claimed address book stays locked while being synced. The lock
+	// is renewed once half of it is spent, as the cards are fetched.
+	DefaultLease = 10 * time.Minute
+	// DefaultBatchSize is the number of address books claimed per poll.
+	DefaultBatchSize = 10
+	// multigetSize is the number of cards fetched per request.
+	multigetSize = 100
+	// requestTimeout bounds every request to the CardDAV servers.
+	requestTimeout = time.Minute
+	// maxBackoff caps the delay between retries of a failing address book.
+	maxBackoff = 6 * time.Hour
+	// maxSyncErrors caps the card errors kept in a sync result. Failed cards are still counted.
+	maxSyncErrors = 100
+)
+
+var errNoAddressBook = errors.New("no address book found on the server")
+
+// CardError is the error of a card that couldn't be synced.
+type CardError struct {
+	Href string
+	Err  error
+}
+
+// Result counts the outcome of the cards of a sync.
+type Result struct {
+	Created int
+	Updated int
+	Deleted int
+	Skipped int
+	// Conflicts counts the cards changed on both sides, settled by the conflict policy.
+	Conflicts int
+	Failed    int
+	Errors    []CardError
+}
+
+// Syncer syncs the due address books on a schedule. Like the reminder scheduler, its state
+// lives in the database, and a sync only advances the sync token once all the changes were
+// written, so an interrupted sync is done again.
+type Syncer struct {
+	DB       *DB
+	Contacts *contact.DB
+	// Client sends the requests to the CardDAV servers. The default one only connects to public
+	// addresses.
+	Client    webdav.HTTPClient
+	Interval  time.Duration
+	Every     time.Duration
+	Lease     time.Duration
+	BatchSize int
+	now       func() time.Time
+}
+
+// NewSyncer creates a syncer with the default interval, lease and batch size.
+func NewSyncer(db *DB, contacts *contact.DB) *Syncer {
+	return &Syncer{
+		DB:        db,
+		Contacts:  contacts,
+		Client:    newHTTPClient(),
+		Interval:  DefaultInterval,
+		Every:     DefaultEvery,
+		Lease:     DefaultLease,
+		BatchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// Run syncs due address books until the context is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx); err != nil {
+			log.WithError(err).Error("syncing address books")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and syncs the address books that are due now and returns how many were synced.
+func (s *Syncer) RunOnce(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.DB.Claim(now, s.Lease, s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	synced := 0
+	for i := range due {
+		src := &due[i]
+		logger := log.WithField("address_book_id", src.ID)
+		res, err := s.Sync(ctx, src)
+		if errors.Is(err, errLeaseLost) {
+			// the other syncer syncs it now
+			logger.WithError(err).Warn("address book sync abandoned")
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).Warn("address book sync failed")
+			if err := s.DB.Failed(src, err, now.Add(lease.Backoff(src.Attempts, maxBackoff))); err != nil {
+				return synced, err
+			}
+			continue
+		}
+		logger.WithFields(log.Fields{
+			"created":   res.Created,
+			"updated":   res.Updated,
+			"deleted":   res.Deleted,
+			"conflicts": res.Conflicts,
+			"failed":    res.Failed,
+		}).Info("address book synced")
+		at := s.now()
+		if err := s.DB.Synced(src, at, at.Add(s.Every)); err != nil {
+			return synced, err
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+// Sync pulls the cards changed since the last sync of the address book into the contacts of
+// its user, leaving the new sync token in src. The address book is found on the server on
+// the first sync. Cards that can't be synced are counted as failed without stopping the sync.
+// The lock of a claimed address book is renewed along the way, and the sync stops with
+// errLeaseLost if another syncer claimed it meanwhile.
+func (s *Syncer) Sync(ctx context.Context, src *Source) (*Result, error) {
+	password, err := s.DB.password(src)
+	if err != nil {
+		return nil, err
+	}
+	client, err := carddav.NewClient(webdav.HTTPClientWithBasicAuth(s.Client, src.Username, password), src.URL)
+	if err != nil {
+		return nil, err
+	}
+	if src.AddressBook == "" {
+		if src.AddressBook, err = discover(ctx, client); err != nil {
+			return nil, err
+		}
+		src.SyncToken = ""
+	}
+	links, err := s.DB.Links(src.ID)
+	if err != nil {
+		return nil, err
+	}
+	changed, deleted, token, err := changes(ctx, client, src, links)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &run{
+		db:       s.DB,
+		src:      src,
+		links:    links,
+		contacts: s.Contacts.As(src.Actor()),
+		result:   &Result{},
+	}
+	for len(changed) > 0 {
+		if err := s.renew(src); err != nil {
+			return nil, err
+		}
+		n := multigetSize
+		if n > len(changed) {
+			n = len(changed)
+		}
+		objects, err := client.MultiGetAddressBook(ctx, src.AddressBook, &carddav.AddressBookMultiGet{
+			Paths:       changed[:n],
+			DataRequest: carddav.AddressDataRequest{AllProp: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range objects {
+			if err := r.apply(&objects[i]); err != nil {
+				return nil, err
+			}
+		}
+		changed = changed[n:]
+	}
+	if err := s.renew(src); err != nil {
+		return nil, err
+	}
+	for _, href := range deleted {
+		if err := r.remove(href); err != nil {
+			return nil, err
+		}
+	}
+	src.SyncToken = token
+	return r.result, nil
+}
+
+// renew extends the lock of the address book once half of the lease is spent. Address books
+// synced without being claimed aren't locked.
+func (s *Syncer) renew(src *Source) error {
+	if src.LockedUntil == nil {
+		return nil
+	}
+	now := s.now()
+	if now.Before(src.LockedUntil.Add(-s.Lease / 2)) {
+		return nil
+	}
+	return s.DB.Renew(src, lease.Until(now, s.Lease))
+}
+
+// discover finds the address book at the URL of the client, or the first one of the user
+// the server knows the URL is authenticated as.
+func discover(ctx context.Context, client *carddav.Client) (string, error) {
+	if books, err := client.FindAddressBooks(ctx, ""); err == nil && len(books) > 0 {
+		return books[0].Path, nil
+	}
+	principal, err := client.FindCurrentUserPrincipal(ctx)
+	if err != nil {
+		return "", err
+	}
+	home, err := client.FindAddressBookHomeSet(ctx, principal)
+	if err != nil {
+		return "", err
+	}
+	books, err := client.FindAddressBooks(ctx, home)
+	if err != nil {
+		return "", err
+	}
+	if len(books) == 0 {
+		return "", errNoAddressBook
+	}
+	return books[0].Path, nil
+}
+
+// changes returns the hrefs of the cards changed and deleted since the sync token, and the new
+// token. When the server refuses the token, or doesn't support sync-collection, the whole
+// address book is listed and compared to the links instead.
+func changes(ctx context.Context, client *carddav.Client, src *Source, links map[string]*Link) (changed, deleted []string, token string, err error) {
+	full := src.SyncToken == ""
+	res, err := client.SyncCollection(ctx, src.AddressBook, &carddav.SyncQuery{SyncToken: src.SyncToken})
+	if err != nil && !full {
+		// the token may have expired, start over
+		full = true
+		res, err = client.SyncCollection(ctx, src.AddressBook, &carddav.SyncQuery{})
+	}
+	var listed []carddav.AddressObject
+	if err == nil {
+		listed, deleted, token = res.Updated, res.Deleted, res.SyncToken
+	} else {
+		files, lerr := client.ReadDir(ctx, src.AddressBook, false)
+		if lerr != nil {
+			return nil, nil, "", fmt.Errorf("listing address book: %v", lerr)
+		}
+		for _, f := range files {
+			if !f.IsDir {
+				listed = append(listed, carddav.AddressObject{Path: f.Path, ETag: f.ETag})
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(listed))
+	for _, o := range listed {
+		seen[o.Path] = true
+		if l, ok := links[o.Path]; ok && l.ETag != "" && l.ETag == o.ETag {
+			continue
+		}
+		changed = append(changed, o.Path)
+	}
+	if full {
+		deleted = nil
+		for href := range links {
+			if !seen[href] {
+				deleted = append(deleted, href)
+			}
+		}
+	}
+	return changed, deleted, token, nil
+}
+
+// run applies the changes of a sync.
+type run struct {
+	db       *DB
+	src      *Source
+	links    map[string]*Link
+	contacts *contact.DB
+	result   *Result
+}
+
+// apply writes the card to the contact it is linked to, or to a new contact. A contact of the
+// user with the same email is linked to the card and settled as a conflict. Returned errors
+// stop the sync, while the errors of the card are counted in the result.
+func (r *run) apply(obj *carddav.AddressObject) error {
+	link, ok := r.links[obj.Path]
+	if !ok {
+		link = &Link{SourceID: r.src.ID, Href: obj.Path}
+		r.links[obj.Path] = link
+	}
+	// the card is done with until it changes again, even when it fails
+	link.ETag = obj.ETag
+
+	e, err := vcard.FromCard(obj.Card)
+	if err != nil {
+		r.fail(link, err)
+		return r.db.SaveLink(link)
+	}
+	c := e.Contact
+	c.UserID = r.src.UserID
+
+	var existing *contact.Contact
+	conflict := false
+	if link.ContactID != 0 {
+		if existing, err = r.contact("id = ?", link.ContactID); err != nil {
+			return err
+		}
+		if existing == nil {
+			// deleted locally, so it stays deleted
+			r.result.Skipped++
+			return r.db.SaveLink(link)
+		}
+		conflict = existing.Version != link.Version
+	} else if c.Email != "" {
+		if existing, err = r.contact("email = ?", c.Email); err != nil {
+			return err
+		}
+		if existing != nil {
+			link.ContactID, link.Version = existing.ID, existing.Version
+			conflict = true
+		}
+	}
+
+	if existing == nil {
+		var created *contact.Contact
+		err := r.write(&c, e.Organization, func(tx *contact.DB) (err error) {
+			created, err = tx.Create(c)
+			return err
+		})
+		if err != nil {
+			r.fail(link, err)
+			return r.db.SaveLink(link)
+		}
+		link.ContactID, link.Version = created.ID, created.Version
+		r.result.Created++
+		return r.db.SaveLink(link)
+	}
+	if conflict {
+		r.result.Conflicts++
+		if r.src.Conflicts == PreferLocal {
+			r.result.Skipped++
+			return r.db.SaveLink(link)
+		}
+	}
+	c.ID, c.Version, c.CreatedAt = existing.ID, existing.Version, existing.CreatedAt
+	err = r.write(&c, e.Organization, func(tx *contact.DB) error {
+		return tx.Update(&c)
+	})
+	if err != nil {
+		r.fail(link, err)
+		return r.db.SaveLink(link)
+	}
+	link.Version = c.Version
+	r.result.Updated++
+	return r.db.SaveLink(link)
+}
+
+// write resolves the named organization of the card's contact and runs fn in the same
+// transaction, so a card that fails to be written leaves no organization behind.
+func (r *run) write(c *contact.Contact, organization string, fn func(tx *contact.DB) error) error {
+	return r.contacts.Transaction(func(tx *contact.DB) error {
+		if err := tx.ResolveOrganization(c, organization); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// remove deletes the contact of a card deleted from the address book, unless it was changed
+// locally and local changes are preferred.
+func (r *run) remove(href string) error {
+	link, ok := r.links[href]
+	if !ok {
+		return nil
+	}
+	if link.ContactID != 0 {
+		existing, err := r.contact("id = ?", link.ContactID)
+		if err != nil {
+			return err
+		}
+		keep := false
+		if existing != nil && existing.Version != link.Version {
+			r.result.Conflicts++
+			keep = r.src.Conflicts == PreferLocal
+		}
+		if existing != nil && !keep {
+			if _, err := r.contacts.Delete(r.src.UserID, existing.ID); err != nil {
+				return err
+			}
+			r.result.Deleted++
+		}
+	}
+	delete(r.links, href)
+	return r.db.DeleteLink(link)
+}
+
+// contact returns the contact of the user matching the query, or nil when there is none.
+func (r *run) contact(query string, args ...interface{}) (*contact.Contact, error) {
+	var c contact.Contact
+	err := r.contacts.Conn.Where("user_id = ?", r.src.UserID).Where(query, args...).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *run) fail(link *Link, err error) {
+	r.result.Failed++
+	if len(r.result.Errors) < maxSyncErrors {
+		r.result.Errors = append(r.result.Errors, CardError{Href: link.Href, Err: err})
+	}
+}
@@ -0,0 +1,219 @@
+package addressbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"grpc-contact-manager/services/carddav"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/organization"
+	"grpc-contact-manager/services/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteServer serves the contacts of a new user over CardDAV, standing for an external
+// address book, and returns the user.
+func remoteServer(t *testing.T) (*httptest.Server, *user.User) {
+	remote, err := users.Create(user.User{Name: "Remote", Email: "remote@example.com", Password: "secret"})
+	require.NoError(t, err)
+	srv := httptest.NewServer(&carddav.Handler{
+		Contacts: contacts,
+		Orgs:     &organization.DB{Conn: db.Conn},
+		Users:    users,
+		Objects:  objects,
+		Prefix:   "/dav",
+	})
+	t.Cleanup(srv.Close)
+	return srv, remote
+}
+
+func newContact(t *testing.T, userID uint, name, email string) *contact.Contact {
+	c, err := contacts.Create(contact.Contact{
+		UserID:   userID,
+		Fullname: name,
+		Email:    email,
+		Phone:    "+2348012345678",
+		Address:  "12 Marina Road, Lagos",
+	})
+	require.NoError(t, err)
+	return c
+}
+
+// localContact returns the contact of the local user with the email, nil when there is none.
+func localContact(t *testing.T, userID uint, email string) *contact.Contact {
+	var found []contact.Contact
+	require.NoError(t, contacts.Conn.Where("user_id = ? AND email = ?", userID, email).Find(&found).Error)
+	if len(found) == 0 {
+		return nil
+	}
+	return &found[0]
+}
+
+func rename(t *testing.T, c *contact.Contact, name string) {
+	c.Fullname = name
+	require.NoError(t, contacts.Update(c))
+}
+
+func TestSync(t *testing.T) {
+	srv, remote := remoteServer(t)
+	local, err := users.Create(user.User{Name: "Local", Email: "local@example.com", Password: "password"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+	ctx := context.Background()
+
+	ada := newContact(t, remote.ID, "Ada Obi", "ada@example.com")
+	bayo := newContact(t, remote.ID, "Bayo Ade", "bayo@example.com")
+	src, err := db.Create(Source{UserID: local.ID, URL: srv.URL + "/dav/", Username: remote.Email, Password: "secret"})
+	require.NoError(t, err)
+	syncer := NewSyncer(db, contacts)
+	syncer.Client = srv.Client()
+
+	res, err := syncer.Sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Created)
+	assert.Equal(t, fmt.Sprintf("/dav/addressbooks/%d/contacts/", remote.ID), src.AddressBook)
+	assert.NotEmpty(t, src.SyncToken)
+	localAda := localContact(t, local.ID, "ada@example.com")
+	require.NotNil(t, localAda)
+	assert.Equal(t, "Ada Obi", localAda.Fullname)
+	revisions, err := contacts.Revisions(local.ID, localAda.ID)
+	require.NoError(t, err)
+	assert.Equal(t, src.Actor(), revisions[0].Actor)
+
+	res, err = syncer.Sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, *res)
+
+	rename(t, ada, "Ada Obi-Eze")
+	_, err = contacts.Delete(remote.ID, bayo.ID)
+	require.NoError(t, err)
+	newContact(t, remote.ID, "Chidi Okafor", "chidi@example.com")
+	res, err = syncer.Sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 1, Updated: 1, Deleted: 1}, *res)
+	assert.Equal(t, "Ada Obi-Eze", localContact(t, local.ID, "ada@example.com").Fullname)
+	assert.Nil(t, localContact(t, local.ID, "bayo@example.com"))
+
+	// changes on both sides are settled by the policy
+	rename(t, localContact(t, local.ID, "ada@example.com"), "Ada (local)")
+	rename(t, ada, "Ada (remote)")
+	res, err = syncer.Sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Updated: 1, Conflicts: 1}, *res)
+	assert.Equal(t, "Ada (remote)", localContact(t, local.ID, "ada@example.com").Fullname)
+
+	src.Conflicts = PreferLocal
+	rename(t, localContact(t, local.ID, "ada@example.com"), "Ada (local)")
+	rename(t, ada, "Ada (remote again)")
+	res, err = syncer.Sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Skipped: 1, Conflicts: 1}, *res)
+	assert.Equal(t, "Ada (local)", localContact(t, local.ID, "ada@example.com").Fullname)
+
+	// a refused token falls back to comparing the whole address book
+	chidi := localContact(t, remote.ID, "chidi@example.com")
+	_, err = contacts.Delete(remote.ID, chidi.ID)
+	require.NoError(t, err)
+	src.SyncToken = "expired"
+	res, err = syncer.Sync(ctx, src)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Deleted: 1}, *res)
+	assert.Nil(t, localContact(t, local.ID, "chidi@example.com"))
+
+	src.SealedPassword, err = db.seal("wrong")
+	require.NoError(t, err)
+	_, err = syncer.Sync(ctx, src)
+	assert.Error(t, err)
+}
+
+func TestRunOnce(t *testing.T) {
+	srv, remote := remoteServer(t)
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+	newContact(t, remote.ID, "Ada Obi", "ada@example.com")
+	ok, err := db.Create(Source{UserID: 90, URL: srv.URL + "/dav/", Username: remote.Email, Password: "secret"})
+	require.NoError(t, err)
+	failing, err := db.Create(Source{UserID: 91, URL: srv.URL + "/dav/", Username: remote.Email, Password: "wrong"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	syncer := NewSyncer(db, contacts)
+	syncer.Client = srv.Client()
+	syncer.now = func() time.Time { return now }
+	synced, err := syncer.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, synced)
+
+	found, err := db.Find(90, ok.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found.LastSyncedAt)
+	assert.NotEmpty(t, found.SyncToken)
+	assert.True(t, found.NextSyncAt.Equal(now.Add(DefaultEvery)))
+	assert.NotNil(t, localContact(t, 90, "ada@example.com"))
+
+	found, err = db.Find(91, failing.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, found.LastError)
+	assert.Nil(t, found.LastSyncedAt)
+	assert.True(t, found.NextSyncAt.Equal(now.Add(time.Minute)))
+}
+
+func TestSyncRenewsLease(t *testing.T) {
+	srv, remote := remoteServer(t)
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+	newContact(t, remote.ID, "Ada Obi", "ada@example.com")
+	_, err := db.Create(Source{UserID: 92, URL: srv.URL + "/dav/", Username: remote.Email, Password: "secret"})
+	require.NoError(t, err)
+	now := time.Now()
+	claimed, err := db.Claim(now, DefaultLease, DefaultBatchSize)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	src := &claimed[0]
+
+	// the sync runs past half of the lease
+	clock := now.Add(DefaultLease * 3 / 4)
+	syncer := NewSyncer(db, contacts)
+	syncer.Client = srv.Client()
+	syncer.now = func() time.Time { return clock }
+	_, err = syncer.Sync(context.Background(), src)
+	require.NoError(t, err)
+	assert.True(t, src.LockedUntil.After(now.Add(DefaultLease)))
+	other, err := db.Claim(now.Add(DefaultLease+time.Second), DefaultLease, DefaultBatchSize)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	// a sync outlasting its lock stops once another syncer claimed the address book
+	clock = src.LockedUntil.Add(time.Second)
+	other, err = db.Claim(clock, DefaultLease, DefaultBatchSize)
+	require.NoError(t, err)
+	require.Len(t, other, 1)
+	_, err = syncer.Sync(context.Background(), src)
+	assert.Equal(t, errLeaseLost, err)
+}
+
+func TestPublicAddressesOnly(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::ffff:127.0.0.1"} {
+		assert.False(t, public(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "2606:4700:4700::1111"} {
+		assert.True(t, public(net.ParseIP(ip)), ip)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, err := newHTTPClient().Get(srv.URL)
+	assert.True(t, errors.Is(err, errForbiddenAddress), err)
+}
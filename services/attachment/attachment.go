@@ -0,0 +1,398 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"grpc-contact-manager/services/storage"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultQuota is the storage each user gets for attachments, in bytes.
+	DefaultQuota = 100 << 20
+
+	// MaxChunkSize is the largest chunk accepted in a single upload call, in bytes.
+	MaxChunkSize = 1 << 20
+
+	// UploadTTL is how long an upload is kept without receiving a chunk before it expires.
+	UploadTTL = 24 * time.Hour
+
+	// DefaultExpireInterval is how often RunExpirer removes the expired uploads.
+	DefaultExpireInterval = time.Hour
+)
+
+var (
+	errConnNotInitialized  = errors.New("connection not initialized")
+	errStoreNotInitialized = errors.New("blob store not initialized")
+	errInvalidUserID       = errors.New("invalid user id")
+	errInvalidContactID    = errors.New("invalid contact id")
+	errEmptyName           = errors.New("file name must be provided")
+	errInvalidSize         = errors.New("file size must be greater than zero")
+	errChunkTooLarge       = fmt.Errorf("chunk must not be larger than %d bytes", MaxChunkSize)
+	errUploadComplete      = errors.New("upload already completed")
+
+	// ErrNotFound is returned when the attachment or upload doesn't exist or belongs to another user.
+	ErrNotFound = errors.New("attachment not found")
+	// ErrQuotaExceeded is returned when storing the file would take the user over their quota.
+	ErrQuotaExceeded = errors.New("attachment storage quota exceeded")
+	// ErrOffsetMismatch is returned when a chunk doesn't continue where the upload stopped.
+	ErrOffsetMismatch = errors.New("chunk offset does not match the upload offset")
+)
+
+// Attachment is a file attached to a contact. Files with the same content share a single blob per user.
+type Attachment struct {
+	gorm.Model
+	UserID      uint   `json:"user_id" gorm:"column:user_id;index:idx_attachment_user_sha"`
+	ContactID   uint   `json:"contact_id" gorm:"column:contact_id;index"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256" gorm:"column:sha256;index:idx_attachment_user_sha"`
+}
+
+// Key returns the blob store key of the attachment content.
+func (a *Attachment) Key() string {
+	return blobKey(a.UserID, a.SHA256)
+}
+
+// Upload tracks a resumable upload. Each received chunk is kept as a separate blob until the upload
+// completes. Its declared size counts against the user's quota until then.
+type Upload struct {
+	gorm.Model
+	UserID      uint   `json:"user_id" gorm:"column:user_id;index"`
+	ContactID   uint   `json:"contact_id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Offset      int64  `json:"offset"`
+	Parts       int    `json:"parts"`
+}
+
+func (u *Upload) partKey(part int) string {
+	return fmt.Sprintf("uploads/%d/%d/%d", u.UserID, u.ID, part)
+}
+
+// DB - attachment repository backed by a database connection and a blob store
+type DB struct {
+	Conn  *gorm.DB
+	Store storage.BlobStore
+	Quota int64
+}
+
+// New creates a new instance of the attachment repository with the default quota
+func New(conn *gorm.DB, store storage.BlobStore) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	if store == nil {
+		return nil, errStoreNotInitialized
+	}
+	return &DB{Conn: conn, Store: store, Quota: DefaultQuota}, nil
+}
+
+// Migrate creates the attachment and upload tables
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Attachment{}, Upload{})
+}
+
+// StartUpload registers a new upload after checking that the file fits in the user's quota.
+func (d *DB) StartUpload(u Upload) (*Upload, error) {
+	if err := u.validate(); err != nil {
+		return nil, err
+	}
+	u.Offset, u.Parts = 0, 0
+	return &u, d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := checkQuota(tx, d.Quota, u.UserID, u.Size, 0); err != nil {
+			return err
+		}
+		return tx.Create(&u).Error
+	})
+}
+
+// FindUpload returns the upload so a client can resume from its offset. Expired uploads aren't found.
+func (d *DB) FindUpload(userID, id uint) (*Upload, error) {
+	var u Upload
+	err := d.Conn.Where("user_id = ? AND updated_at >= ?", userID, expiry()).First(&u, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// WriteChunk appends data at offset to the upload. Once the last byte arrives the upload
+// is turned into an attachment, which is returned alongside the finished upload. If that
+// fails, an empty chunk at the end of the upload completes it again.
+func (d *DB) WriteChunk(userID, uploadID uint, offset int64, data []byte) (*Upload, *Attachment, error) {
+	if len(data) > MaxChunkSize {
+		return nil, nil, errChunkTooLarge
+	}
+	u, err := d.FindUpload(userID, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Offset == u.Size && (offset != u.Size || len(data) > 0) {
+		return nil, nil, errUploadComplete
+	}
+	if offset != u.Offset || u.Offset+int64(len(data)) > u.Size {
+		return nil, nil, ErrOffsetMismatch
+	}
+	if len(data) > 0 {
+		if err := d.writePart(u, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	if u.Offset < u.Size {
+		return u, nil, nil
+	}
+	a, err := d.complete(u)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, a, nil
+}
+
+// writePart stores data as the next part of the upload. The offset only moves if no other
+// chunk moved it meanwhile, and the part is stored while the row is held, so concurrent
+// chunks at the same offset can't overwrite each other.
+func (d *DB) writePart(u *Upload, data []byte) error {
+	return d.Conn.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Upload{}).Where("id = ?", u.ID).Where(map[string]interface{}{"offset": u.Offset}).
+			Updates(map[string]interface{}{"offset": u.Offset + int64(len(data)), "parts": u.Parts + 1})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrOffsetMismatch
+		}
+		if _, err := d.Store.Put(u.partKey(u.Parts), bytes.NewReader(data)); err != nil {
+			return err
+		}
+		u.Parts++
+		u.Offset += int64(len(data))
+		return nil
+	})
+}
+
+// complete hashes the uploaded parts, stores the content unless the user already has it and removes the parts.
+func (d *DB) complete(u *Upload) (*Attachment, error) {
+	h := sha256.New()
+	if err := d.copyParts(h, u); err != nil {
+		return nil, err
+	}
+	a := Attachment{
+		UserID:      u.UserID,
+		ContactID:   u.ContactID,
+		Name:        u.Name,
+		ContentType: u.ContentType,
+		Size:        u.Size,
+		SHA256:      hex.EncodeToString(h.Sum(nil)),
+	}
+
+	err := d.Conn.Transaction(func(tx *gorm.DB) error {
+		// only one of the calls completing the upload at once removes it
+		res := tx.Unscoped().Delete(u)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		var existing int64
+		if err := tx.Model(&Attachment{}).Where("user_id = ? AND sha256 = ?", u.UserID, a.SHA256).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing == 0 {
+			if err := checkQuota(tx, d.Quota, u.UserID, u.Size, u.ID); err != nil {
+				return err
+			}
+			pr, pw := io.Pipe()
+			go func() {
+				pw.CloseWithError(d.copyParts(pw, u))
+			}()
+			_, err := d.Store.Put(a.Key(), pr)
+			pr.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(&a).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < u.Parts; i++ {
+		if err := d.Store.Delete(u.partKey(i)); err != nil {
+			return nil, err
+		}
+	}
+	return &a, nil
+}
+
+func (d *DB) copyParts(w io.Writer, u *Upload) error {
+	for i := 0; i < u.Parts; i++ {
+		r, err := d.Store.Get(u.partKey(i))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindByContact returns the attachments of the contact.
+func (d *DB) FindByContact(userID, contactID uint) ([]Attachment, error) {
+	var attachments []Attachment
+	res := d.Conn.Where("user_id = ? AND contact_id = ?", userID, contactID).Order("id").Find(&attachments)
+	return attachments, res.Error
+}
+
+// Find returns the attachment with the given ID.
+func (d *DB) Find(userID, id uint) (*Attachment, error) {
+	var a Attachment
+	err := d.Conn.Where("user_id = ?", userID).First(&a, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Open opens the attachment content. The caller must close the returned reader.
+func (d *DB) Open(a *Attachment) (io.ReadCloser, error) {
+	return d.Store.Get(a.Key())
+}
+
+// Delete removes the attachment, and its content once no other attachment of the user shares it.
+func (d *DB) Delete(userID, id uint) (*Attachment, error) {
+	a, err := d.Find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Conn.Unscoped().Delete(a).Error; err != nil {
+		return nil, err
+	}
+	var shared int64
+	if err := d.Conn.Model(&Attachment{}).Where("user_id = ? AND sha256 = ?", userID, a.SHA256).Count(&shared).Error; err != nil {
+		return nil, err
+	}
+	if shared == 0 {
+		return a, d.Store.Delete(a.Key())
+	}
+	return a, nil
+}
+
+// Usage returns the bytes stored for the user, plus the declared size of their uploads in
+// progress. Duplicate files are only counted once.
+func (d *DB) Usage(userID uint) (int64, error) {
+	return usage(d.Conn, userID, 0)
+}
+
+// ExpireUploads removes the uploads that received no chunk since before, along with their parts.
+func (d *DB) ExpireUploads(before time.Time) (int, error) {
+	var uploads []Upload
+	if err := d.Conn.Where("updated_at < ?", before).Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+	for i := range uploads {
+		u := &uploads[i]
+		for part := 0; part < u.Parts; part++ {
+			if err := d.Store.Delete(u.partKey(part)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return i, err
+			}
+		}
+		if err := d.Conn.Unscoped().Delete(u).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(uploads), nil
+}
+
+// RunExpirer removes the uploads older than UploadTTL every interval until ctx is done.
+func (d *DB) RunExpirer(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultExpireInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := d.ExpireUploads(expiry())
+		if err != nil {
+			log.WithError(err).Error("expiring stale uploads")
+		} else if n > 0 {
+			log.WithField("uploads", n).Info("expired stale uploads")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// usage returns the bytes the user stores or reserves, leaving out the upload being completed.
+func usage(tx *gorm.DB, userID, exceptUpload uint) (int64, error) {
+	var stored, reserved int64
+	err := tx.Raw(`SELECT COALESCE(SUM(size), 0) FROM (
+		SELECT DISTINCT sha256, size FROM attachments WHERE user_id = ? AND deleted_at IS NULL
+	) AS blobs`, userID).Scan(&stored).Error
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Model(&Upload{}).Select("COALESCE(SUM(size), 0)").
+		Where("user_id = ? AND id <> ? AND updated_at >= ?", userID, exceptUpload, expiry()).
+		Scan(&reserved).Error
+	return stored + reserved, err
+}
+
+func checkQuota(tx *gorm.DB, quota int64, userID uint, size int64, exceptUpload uint) error {
+	used, err := usage(tx, userID, exceptUpload)
+	if err != nil {
+		return err
+	}
+	if used+size > quota {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+func expiry() time.Time {
+	return time.Now().Add(-UploadTTL)
+}
+
+func (u *Upload) validate() error {
+	if u.UserID == 0 {
+		return errInvalidUserID
+	}
+	if u.ContactID == 0 {
+		return errInvalidContactID
+	}
+	if u.Name == "" {
+		return errEmptyName
+	}
+	if u.Size <= 0 {
+		return errInvalidSize
+	}
+	return nil
+}
+
+func blobKey(userID uint, sum string) string {
+	return fmt.Sprintf("attachments/%d/%s", userID, sum)
+}
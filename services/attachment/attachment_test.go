@@ -0,0 +1,310 @@
+package attachment
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"grpc-contact-manager/services/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db *DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "attachments")
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := storage.NewLocal(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn, store)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db = d
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestValidateUpload(t *testing.T) {
+	table := []struct {
+		name   string
+		upload Upload
+		want   error
+	}{
+		{
+			name:   "All good",
+			upload: Upload{UserID: 1, ContactID: 1, Name: "contract.pdf", Size: 10},
+			want:   nil,
+		},
+		{
+			name:   "Invalid User",
+			upload: Upload{ContactID: 1, Name: "contract.pdf", Size: 10},
+			want:   errInvalidUserID,
+		},
+		{
+			name:   "Invalid Contact",
+			upload: Upload{UserID: 1, Name: "contract.pdf", Size: 10},
+			want:   errInvalidContactID,
+		},
+		{
+			name:   "Empty Name",
+			upload: Upload{UserID: 1, ContactID: 1, Size: 10},
+			want:   errEmptyName,
+		},
+		{
+			name:   "Empty File",
+			upload: Upload{UserID: 1, ContactID: 1, Name: "contract.pdf"},
+			want:   errInvalidSize,
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.upload.validate())
+		})
+	}
+}
+
+func TestResumableUpload(t *testing.T) {
+	data := []byte("a contract that is uploaded in three chunks")
+	u, err := db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "contract.txt", ContentType: "text/plain", Size: int64(len(data))})
+	require.NoError(t, err)
+
+	u, a, err := db.WriteChunk(1, u.ID, 0, data[:10])
+	require.NoError(t, err)
+	assert.Nil(t, a)
+	assert.Equal(t, int64(10), u.Offset)
+
+	// a chunk that skips ahead is rejected
+	_, _, err = db.WriteChunk(1, u.ID, 20, data[20:])
+	assert.Equal(t, ErrOffsetMismatch, err)
+
+	// the client reconnects and asks where to resume from
+	resumed, err := db.FindUpload(1, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), resumed.Offset)
+
+	_, a, err = db.WriteChunk(1, u.ID, 10, data[10:20])
+	require.NoError(t, err)
+	assert.Nil(t, a)
+	_, a, err = db.WriteChunk(1, u.ID, 20, data[20:])
+	require.NoError(t, err)
+	require.NotNil(t, a)
+
+	sum := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(sum[:]), a.SHA256)
+	assert.Equal(t, int64(len(data)), a.Size)
+
+	r, err := db.Open(a)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, data, got)
+
+	// the upload is gone once completed
+	_, err = db.FindUpload(1, u.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestDedupeAndQuota(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 64)
+	first := upload(t, 1, 1, data)
+	second := upload(t, 1, 2, data)
+	assert.Equal(t, first.SHA256, second.SHA256)
+
+	usage, err := db.Usage(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(64), usage)
+
+	db.Quota = 100
+	defer func() { db.Quota = DefaultQuota }()
+	_, err = db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "big.bin", Size: 37})
+	assert.Equal(t, ErrQuotaExceeded, err)
+
+	// deleting one copy keeps the shared content
+	_, err = db.Delete(1, first.ID)
+	require.NoError(t, err)
+	r, err := db.Open(second)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	_, err = db.Delete(1, second.ID)
+	require.NoError(t, err)
+	_, err = db.Open(second)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestFindByContact(t *testing.T) {
+	upload(t, 1, 1, []byte("first"))
+	upload(t, 1, 1, []byte("second"))
+	upload(t, 2, 1, []byte("other user"))
+
+	attachments, err := db.FindByContact(1, 1)
+	require.NoError(t, err)
+	assert.Len(t, attachments, 2)
+
+	_, err = db.Find(2, attachments[0].ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestUploadsReserveQuota(t *testing.T) {
+	db.Quota = 100
+	defer func() { db.Quota = DefaultQuota }()
+	started, err := db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "first.bin", Size: 60})
+	require.NoError(t, err)
+
+	usage, err := db.Usage(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), usage)
+
+	// an upload in progress holds its size until it completes
+	_, err = db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "second.bin", Size: 60})
+	assert.Equal(t, ErrQuotaExceeded, err)
+
+	_, a, err := db.WriteChunk(1, started.ID, 0, bytes.Repeat([]byte("x"), 60))
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	usage, err = db.Usage(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), usage)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestRetryCompletion(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 60)
+	started, err := db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "retried.bin", Size: 60})
+	require.NoError(t, err)
+
+	// the last chunk is kept even though the upload can't complete
+	db.Quota = 50
+	_, _, err = db.WriteChunk(1, started.ID, 0, data)
+	assert.Equal(t, ErrQuotaExceeded, err)
+	db.Quota = DefaultQuota
+	resumed, err := db.FindUpload(1, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), resumed.Offset)
+
+	_, _, err = db.WriteChunk(1, started.ID, 60, data[:1])
+	assert.Equal(t, errUploadComplete, err)
+	_, a, err := db.WriteChunk(1, started.ID, 60, nil)
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	assert.Equal(t, int64(60), a.Size)
+	_, err = db.FindUpload(1, started.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestStaleChunk(t *testing.T) {
+	data := []byte("written by two clients at once")
+	started, err := db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "raced.txt", Size: int64(len(data))})
+	require.NoError(t, err)
+
+	// a chunk that read the upload before another chunk moved its offset is rejected
+	stale := *started
+	u, _, err := db.WriteChunk(1, started.ID, 0, data[:10])
+	require.NoError(t, err)
+	assert.Equal(t, ErrOffsetMismatch, db.writePart(&stale, []byte("overwrite!")))
+	assert.Equal(t, 0, stale.Parts)
+
+	_, a, err := db.WriteChunk(1, u.ID, 10, data[10:])
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	r, err := db.Open(a)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, data, got)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestExpireUploads(t *testing.T) {
+	stale, err := db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "stale.bin", Size: 20})
+	require.NoError(t, err)
+	stale, _, err = db.WriteChunk(1, stale.ID, 0, []byte("abandoned"))
+	require.NoError(t, err)
+	fresh, err := db.StartUpload(Upload{UserID: 1, ContactID: 1, Name: "fresh.bin", Size: 20})
+	require.NoError(t, err)
+	require.NoError(t, db.Conn.Model(stale).UpdateColumn("updated_at", time.Now().Add(-UploadTTL-time.Minute)).Error)
+
+	// an expired upload can't be resumed nor holds quota
+	_, err = db.FindUpload(1, stale.ID)
+	assert.Equal(t, ErrNotFound, err)
+	usage, err := db.Usage(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), usage)
+
+	n, err := db.ExpireUploads(time.Now().Add(-UploadTTL))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = db.Store.Get(stale.partKey(0))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = db.FindUpload(1, fresh.ID)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func upload(t *testing.T, userID, contactID uint, data []byte) *Attachment {
+	u, err := db.StartUpload(Upload{UserID: userID, ContactID: contactID, Name: "file.bin", Size: int64(len(data))})
+	require.NoError(t, err)
+	_, a, err := db.WriteChunk(userID, u.ID, 0, data)
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	return a
+}
+
+func cleanup() error {
+	if err := db.Conn.Exec("DELETE FROM uploads").Error; err != nil {
+		return err
+	}
+	return db.Conn.Exec("DELETE FROM attachments").Error
+}
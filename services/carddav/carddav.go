@@ -0,0 +1,188 @@
+// Package carddav serves the contacts of each user as a CardDAV address book (RFC 6352), so that
+// the contacts apps of phones and desktops can sync with them. Every user has a principal and a
+// single address book, found through current-user-principal and addressbook-home-set:
+//
+//	/principals/{user id}/
+//	/addressbooks/{user id}/
+//	/addressbooks/{user id}/contacts/
+//	/addressbooks/{user id}/contacts/{name}.vcf
+//
+// Contacts are served as vCard 3.0, which all clients read, without their photos. Clients keep
+// in sync with the sync-collection report (RFC 6578) or by comparing ETags.
+package carddav
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/organization"
+	"grpc-contact-manager/services/user"
+)
+
+// Namespaces of the properties served.
+const (
+	nsDAV     = "DAV:"
+	nsCardDAV = "urn:ietf:params:xml:ns:carddav"
+	// nsCalServer holds getctag, which Apple clients read to find out if anything changed
+	nsCalServer = "http://calendarserver.org/ns/"
+)
+
+const (
+	// addressBookName is the path segment of the address book of every user.
+	addressBookName = "contacts"
+	// maxResourceSize caps the size of the vCards clients can put.
+	maxResourceSize = 1 << 20
+)
+
+// Handler serves CardDAV requests, authenticating users by their email and password through
+// HTTP basic authentication.
+type Handler struct {
+	Contacts *contact.DB
+	Orgs     *organization.DB
+	Users    *user.DB
+	Objects  *DB
+	// Prefix is the path the handler is mounted at, such as "/carddav".
+	Prefix string
+}
+
+// kind is the kind of resource a path names.
+type kind int
+
+const (
+	kindRoot kind = iota
+	kindPrincipal
+	kindHome
+	kindAddressBook
+	kindObject
+)
+
+// resource is a resource named by a request path.
+type resource struct {
+	kind   kind
+	userID uint
+	// name is the name of an address object.
+	name string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		h.options(w)
+		return
+	}
+	u, ok := h.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="contacts", charset="UTF-8"`)
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	res, ok := h.parse(r.URL.Path)
+	if !ok || (res.kind != kindRoot && res.userID != u.ID) {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+	s := &session{h: h, user: u}
+	switch r.Method {
+	case "PROPFIND":
+		s.propfind(w, r, res)
+	case "PROPPATCH":
+		s.proppatch(w, r, res)
+	case "REPORT":
+		s.report(w, r, res)
+	case http.MethodGet, http.MethodHead:
+		s.get(w, r, res)
+	case http.MethodPut:
+		s.put(w, r, res)
+	case http.MethodDelete:
+		s.delete(w, r, res)
+	default:
+		w.Header().Set("Allow", allowed)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+const allowed = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, PROPPATCH, REPORT"
+
+func (h *Handler) options(w http.ResponseWriter) {
+	w.Header().Set("DAV", "1, 3, addressbook")
+	w.Header().Set("Allow", allowed)
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticate checks the email and password of the basic credentials.
+func (h *Handler) authenticate(r *http.Request) (*user.User, bool) {
+	email, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, false
+	}
+	u, err := h.Users.Authenticate(email, password)
+	if err != nil {
+		return nil, false
+	}
+	return u, true
+}
+
+// parse finds the resource named by the path. Collections are named with or without their
+// trailing slash.
+func (h *Handler) parse(path string) (resource, bool) {
+	if !strings.HasPrefix(path, h.Prefix) {
+		return resource{}, false
+	}
+	path = strings.Trim(strings.TrimPrefix(path, h.Prefix), "/")
+	if path == "" {
+		return resource{kind: kindRoot}, true
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return resource{}, false
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil || id == 0 {
+		return resource{}, false
+	}
+	res := resource{userID: uint(id)}
+	switch {
+	case parts[0] == "principals" && len(parts) == 2:
+		res.kind = kindPrincipal
+	case parts[0] == "addressbooks" && len(parts) == 2:
+		res.kind = kindHome
+	case parts[0] == "addressbooks" && len(parts) == 3 && parts[2] == addressBookName:
+		res.kind = kindAddressBook
+	case parts[0] == "addressbooks" && len(parts) == 4 && parts[2] == addressBookName && parts[3] != "":
+		res.kind, res.name = kindObject, parts[3]
+	default:
+		return resource{}, false
+	}
+	return res, true
+}
+
+func (h *Handler) principalPath(userID uint) string {
+	return h.Prefix + "/principals/" + strconv.FormatUint(uint64(userID), 10) + "/"
+}
+
+func (h *Handler) homePath(userID uint) string {
+	return h.Prefix + "/addressbooks/" + strconv.FormatUint(uint64(userID), 10) + "/"
+}
+
+func (h *Handler) addressBookPath(userID uint) string {
+	return h.homePath(userID) + addressBookName + "/"
+}
+
+// path returns the path of the resource.
+func (h *Handler) path(res resource) string {
+	switch res.kind {
+	case kindPrincipal:
+		return h.principalPath(res.userID)
+	case kindHome:
+		return h.homePath(res.userID)
+	case kindAddressBook:
+		return h.addressBookPath(res.userID)
+	case kindObject:
+		return h.addressBookPath(res.userID) + res.name
+	}
+	return h.Prefix + "/"
+}
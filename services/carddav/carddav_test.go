@@ -0,0 +1,275 @@
+package carddav
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/organization"
+	"grpc-contact-manager/services/user"
+
+	govcard "github.com/emersion/go-vcard"
+	"github.com/emersion/go-webdav"
+	"github.com/emersion/go-webdav/carddav"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	handler *Handler
+	server  *httptest.Server
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	users := &user.DB{Conn: conn}
+	contacts := &contact.DB{Conn: conn}
+	objects, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, db := range []interface{ Migrate() error }{users, contacts, objects} {
+		if err := db.Migrate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	handler = &Handler{
+		Contacts: contacts,
+		Orgs:     &organization.DB{Conn: conn},
+		Users:    users,
+		Objects:  objects,
+		Prefix:   "/carddav",
+	}
+	server = httptest.NewServer(handler)
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+// newUser creates a user with the password "password".
+func newUser(t *testing.T, email string) *user.User {
+	u, err := handler.Users.Create(user.User{Name: "Ada Obi", Email: email, Password: "password"})
+	require.NoError(t, err)
+	return u
+}
+
+func newClient(t *testing.T, email string) *carddav.Client {
+	c, err := carddav.NewClient(webdav.HTTPClientWithBasicAuth(http.DefaultClient, email, "password"), server.URL+"/carddav/")
+	require.NoError(t, err)
+	return c
+}
+
+// do sends a request authenticated as the user, with headers given as name and value pairs.
+func do(t *testing.T, method, path, email, body string, headers ...string) *http.Response {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if email != "" {
+		req.SetBasicAuth(email, "password")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+func readBody(t *testing.T, res *http.Response) string {
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func newCard(uid, name, email string) govcard.Card {
+	card := govcard.Card{}
+	card.SetValue(govcard.FieldVersion, "4.0")
+	card.SetValue(govcard.FieldUID, uid)
+	card.SetValue(govcard.FieldFormattedName, name)
+	card.SetValue(govcard.FieldEmail, email)
+	card.SetValue(govcard.FieldTelephone, "+2348012345678")
+	card.SetAddress(&govcard.Address{StreetAddress: "12 Marina Road", Locality: "Lagos"})
+	card.SetValue(govcard.FieldOrganization, "Acme")
+	return card
+}
+
+func TestDiscovery(t *testing.T) {
+	u := newUser(t, "discovery@example.com")
+	ctx := context.Background()
+
+	res := do(t, "OPTIONS", "/carddav/", "", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, res.Header.Get("DAV"), "addressbook")
+	res = do(t, "PROPFIND", "/carddav/", "", "")
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Contains(t, res.Header.Get("WWW-Authenticate"), "Basic")
+
+	c := newClient(t, u.Email)
+	require.NoError(t, c.HasSupport(ctx))
+	principal, err := c.FindCurrentUserPrincipal(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("/carddav/principals/%d/", u.ID), principal)
+	home, err := c.FindAddressBookHomeSet(ctx, principal)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("/carddav/addressbooks/%d/", u.ID), home)
+	books, err := c.FindAddressBooks(ctx, home)
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Equal(t, home+"contacts/", books[0].Path)
+	assert.Equal(t, "Contacts", books[0].Name)
+	assert.EqualValues(t, maxResourceSize, books[0].MaxResourceSize)
+
+	res = do(t, "PROPFIND", books[0].Path, u.Email, `<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop><d:sync-token/><cs:getctag/><d:getetag/></d:prop>
+</d:propfind>`, "Depth", "0")
+	body := readBody(t, res)
+	assert.Equal(t, http.StatusMultiStatus, res.StatusCode)
+	assert.Contains(t, body, "<d:sync-token>"+syncTokenPrefix)
+	assert.Contains(t, body, "<cs:getctag>"+syncTokenPrefix)
+	assert.Contains(t, body, "<d:getetag/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status>")
+
+	// the address books of other users aren't found
+	other := newUser(t, "discovery-other@example.com")
+	res = do(t, "PROPFIND", fmt.Sprintf("/carddav/addressbooks/%d/contacts/", other.ID), u.Email, "")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	res = do(t, "PROPFIND", "/carddav/unknown/", u.Email, "")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestSync(t *testing.T) {
+	u := newUser(t, "sync@example.com")
+	ctx := context.Background()
+	c := newClient(t, u.Email)
+	book := fmt.Sprintf("/carddav/addressbooks/%d/contacts/", u.ID)
+
+	existing, err := handler.Contacts.Create(contact.Contact{
+		UserID:   u.ID,
+		Fullname: "Bola Ade",
+		Email:    "bola@example.com",
+		Phone:    "+2348000000000",
+		Address:  "1 Allen Avenue, Ikeja",
+	})
+	require.NoError(t, err)
+	existingPath := fmt.Sprintf("%s%d.vcf", book, existing.ID)
+
+	first, err := c.SyncCollection(ctx, book, &carddav.SyncQuery{})
+	require.NoError(t, err)
+	require.Len(t, first.Updated, 1)
+	assert.Equal(t, existingPath, first.Updated[0].Path)
+	assert.Equal(t, fmt.Sprintf("%d-1", existing.ID), first.Updated[0].ETag)
+	assert.Empty(t, first.Deleted)
+
+	// a card put by the client is served where it was put, with its UID
+	put, err := c.PutAddressObject(ctx, book+"f81d4fae.vcf", newCard("urn:uuid:f81d4fae", "Chidi Okafor", "chidi@acme.com"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, put.ETag)
+	got, err := c.GetAddressObject(ctx, book+"f81d4fae.vcf")
+	require.NoError(t, err)
+	assert.Equal(t, "urn:uuid:f81d4fae", got.Card.Value(govcard.FieldUID))
+	assert.Equal(t, "Chidi Okafor", got.Card.PreferredValue(govcard.FieldFormattedName))
+	assert.Equal(t, "Acme;", got.Card.Value(govcard.FieldOrganization))
+	assert.Equal(t, put.ETag, got.ETag)
+
+	second, err := c.SyncCollection(ctx, book, &carddav.SyncQuery{SyncToken: first.SyncToken})
+	require.NoError(t, err)
+	require.Len(t, second.Updated, 1)
+	assert.Equal(t, book+"f81d4fae.vcf", second.Updated[0].Path)
+	assert.Empty(t, second.Deleted)
+
+	objects, err := c.MultiGetAddressBook(ctx, book, &carddav.AddressBookMultiGet{
+		Paths:       []string{existingPath, book + "f81d4fae.vcf"},
+		DataRequest: carddav.AddressDataRequest{AllProp: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "Bola Ade", objects[0].Card.PreferredValue(govcard.FieldFormattedName))
+	assert.Equal(t, "contact-"+fmt.Sprint(existing.ID), objects[0].Card.Value(govcard.FieldUID))
+
+	res := do(t, "DELETE", existingPath, u.Email, "")
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	third, err := c.SyncCollection(ctx, book, &carddav.SyncQuery{SyncToken: second.SyncToken})
+	require.NoError(t, err)
+	assert.Empty(t, third.Updated)
+	assert.Equal(t, []string{existingPath}, third.Deleted)
+
+	fourth, err := c.SyncCollection(ctx, book, &carddav.SyncQuery{SyncToken: third.SyncToken})
+	require.NoError(t, err)
+	assert.Empty(t, fourth.Updated)
+	assert.Empty(t, fourth.Deleted)
+
+	res = do(t, "REPORT", book, u.Email, `<?xml version="1.0"?>
+<d:sync-collection xmlns:d="DAV:"><d:sync-token>bogus</d:sync-token><d:prop><d:getetag/></d:prop></d:sync-collection>`)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Contains(t, readBody(t, res), "valid-sync-token")
+
+	res = do(t, "REPORT", book, u.Email, `<?xml version="1.0"?>
+<card:addressbook-multiget xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+  <d:prop><d:getetag/></d:prop><d:href>`+existingPath+`</d:href>
+</card:addressbook-multiget>`)
+	assert.Equal(t, http.StatusMultiStatus, res.StatusCode)
+	assert.Contains(t, readBody(t, res), "<d:status>HTTP/1.1 404 Not Found</d:status>")
+
+	_, err = c.QueryAddressBook(ctx, book, &carddav.AddressBookQuery{})
+	assert.Error(t, err)
+}
+
+func TestPut(t *testing.T) {
+	u := newUser(t, "put@example.com")
+	book := fmt.Sprintf("/carddav/addressbooks/%d/contacts/", u.ID)
+	var card strings.Builder
+	require.NoError(t, govcard.NewEncoder(&card).Encode(newCard("dee-1", "Dee Eze", "dee@example.com")))
+
+	res := do(t, "PUT", book+"dee.vcf", u.Email, card.String(), "If-None-Match", "*")
+	require.Equal(t, http.StatusCreated, res.StatusCode, readBody(t, res))
+	etag := res.Header.Get("ETag")
+	res = do(t, "PUT", book+"dee.vcf", u.Email, card.String(), "If-None-Match", "*")
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res = do(t, "GET", book+"dee.vcf", u.Email, "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	updated := strings.Replace(card.String(), "Dee Eze", "Dee Eze-Nwosu", 1)
+	res = do(t, "PUT", book+"dee.vcf", u.Email, updated, "If-Match", etag)
+	require.Equal(t, http.StatusNoContent, res.StatusCode, readBody(t, res))
+	assert.NotEqual(t, etag, res.Header.Get("ETag"))
+	res = do(t, "PUT", book+"dee.vcf", u.Email, updated, "If-Match", etag)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res = do(t, "DELETE", book+"dee.vcf", u.Email, "", "If-Match", etag)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res = do(t, "GET", book+"dee.vcf", u.Email, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, readBody(t, res), "FN:Dee Eze-Nwosu")
+
+	// another card can't take the same UID
+	res = do(t, "PUT", book+"copy.vcf", u.Email, strings.Replace(card.String(), "dee@example.com", "copy@example.com", 1))
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Contains(t, readBody(t, res), "no-uid-conflict")
+
+	res = do(t, "PUT", book+"bad.vcf", u.Email, "not a vcard")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Contains(t, readBody(t, res), "valid-address-data")
+	noEmail := strings.Replace(strings.Replace(card.String(), "EMAIL:dee@example.com\r\n", "", 1), "dee-1", "dee-2", 1)
+	res = do(t, "PUT", book+"bad.vcf", u.Email, noEmail)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	res = do(t, "PUT", book+"big.vcf", u.Email, strings.Repeat("x", maxResourceSize+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	res = do(t, "GET", book+"missing.vcf", u.Email, "")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	res = do(t, "DELETE", book, u.Email, "")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
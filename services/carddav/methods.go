@@ -0,0 +1,493 @@
+package carddav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/user"
+	"grpc-contact-manager/services/vcard"
+
+	"gorm.io/gorm"
+)
+
+// Preconditions reported in DAV error bodies.
+var (
+	conditionSupportedReport = xml.Name{Space: nsDAV, Local: "supported-report"}
+	conditionValidSyncToken  = xml.Name{Space: nsDAV, Local: "valid-sync-token"}
+	conditionValidData       = xml.Name{Space: nsCardDAV, Local: "valid-address-data"}
+	conditionNoUIDConflict   = xml.Name{Space: nsCardDAV, Local: "no-uid-conflict"}
+)
+
+// session serves the requests of an authenticated user.
+type session struct {
+	h    *Handler
+	user *user.User
+	// orgNames and token are loaded on first use
+	orgNames map[uint]string
+	token    string
+}
+
+// contact returns the user's contact with the id, which must not be deleted.
+func (s *session) contact(id uint) (*contact.Contact, error) {
+	var c contact.Contact
+	err := s.h.Contacts.Conn.Where("user_id = ?", s.user.ID).First(&c, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// find returns the card of the address object with the name.
+func (s *session) find(name string) (*card, error) {
+	o, err := s.h.Objects.Find(s.user.ID, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		c, err := s.contact(o.ContactID)
+		if err == nil {
+			return &card{contact: *c, object: *o}, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	// the contact may be served under its default name
+	id, ok := defaultContactID(name)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c, err := s.contact(id)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.h.Objects.ForContacts(s.user.ID, []uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if objects[id].Name != name {
+		return nil, ErrNotFound
+	}
+	return &card{contact: *c, object: objects[id]}, nil
+}
+
+// eachCard calls fn for every contact of the user, reading them in batches.
+func (s *session) eachCard(r *http.Request, fn func(c *card) error) error {
+	return s.h.Contacts.Export(r.Context(), s.user.ID, 0, 0, func(batch []contact.Contact) error {
+		return s.withObjects(batch, fn)
+	})
+}
+
+// withObjects calls fn for the card of every contact.
+func (s *session) withObjects(contacts []contact.Contact, fn func(c *card) error) error {
+	ids := make([]uint, len(contacts))
+	for i := range contacts {
+		ids[i] = contacts[i].ID
+	}
+	objects, err := s.h.Objects.ForContacts(s.user.ID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range contacts {
+		if err := fn(&card{contact: contacts[i], object: objects[contacts[i].ID]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objectResource is the resource of the card.
+func (s *session) objectResource(c *card) resource {
+	return resource{kind: kindObject, userID: s.user.ID, name: c.object.Name}
+}
+
+// findObject returns the card of the object resource, writing the error response when there
+// is none.
+func (s *session) findObject(w http.ResponseWriter, res resource) (*card, bool) {
+	c, err := s.find(res.name)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return c, true
+}
+
+// propfind lists the properties of the resource, and of its members unless the depth is 0.
+func (s *session) propfind(w http.ResponseWriter, r *http.Request, res resource) {
+	var req propfindRequest
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var c *card
+	if res.kind == kindObject {
+		var ok bool
+		if c, ok = s.findObject(w, res); !ok {
+			return
+		}
+	}
+	sel := &req.propSelection
+	if req.XMLName.Local == "" {
+		// an empty body asks for all properties
+		sel.AllProp = &struct{}{}
+	}
+	m := newMultistatus(w)
+	err := s.respond(m, res, c, sel)
+	if err == nil && r.Header.Get("Depth") != "0" {
+		switch res.kind {
+		case kindHome:
+			err = s.respond(m, resource{kind: kindAddressBook, userID: s.user.ID}, nil, sel)
+		case kindAddressBook:
+			err = s.eachCard(r, func(c *card) error {
+				return s.respond(m, s.objectResource(c), c, sel)
+			})
+		}
+	}
+	m.finish("", err)
+}
+
+// proppatch refuses to change properties, which are all computed.
+func (s *session) proppatch(w http.ResponseWriter, r *http.Request, res resource) {
+	var req proppatchRequest
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if res.kind == kindObject {
+		if _, ok := s.findObject(w, res); !ok {
+			return
+		}
+	}
+	var names []xml.Name
+	for _, sel := range append(req.Set, req.Remove...) {
+		names = append(names, sel.names()...)
+	}
+	m := newMultistatus(w)
+	m.w.WriteString(`<d:response>` + hrefElement(s.h.path(res)))
+	if len(names) > 0 {
+		m.propstat(names, http.StatusForbidden)
+	} else {
+		m.w.WriteString(statusElement(http.StatusOK))
+	}
+	m.w.WriteString(`</d:response>`)
+	m.finish("", nil)
+}
+
+// report serves the addressbook-multiget and sync-collection reports of the address book.
+func (s *session) report(w http.ResponseWriter, r *http.Request, res resource) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxResourceSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var root element
+	if err := xml.Unmarshal(body, &root); err != nil {
+		http.Error(w, errInvalidBody.Error(), http.StatusBadRequest)
+		return
+	}
+	if res.kind != kindAddressBook {
+		writeError(w, http.StatusForbidden, conditionSupportedReport)
+		return
+	}
+	switch root.XMLName {
+	case reportMultiget:
+		var req multigetRequest
+		if err := xml.Unmarshal(body, &req); err != nil {
+			http.Error(w, errInvalidBody.Error(), http.StatusBadRequest)
+			return
+		}
+		s.multiget(w, &req)
+	case reportSyncCollection:
+		var req syncCollectionRequest
+		if err := xml.Unmarshal(body, &req); err != nil {
+			http.Error(w, errInvalidBody.Error(), http.StatusBadRequest)
+			return
+		}
+		s.syncCollection(w, r, &req)
+	default:
+		writeError(w, http.StatusForbidden, conditionSupportedReport)
+	}
+}
+
+// multiget returns the properties of the address objects listed, and 404 for the unknown ones.
+func (s *session) multiget(w http.ResponseWriter, req *multigetRequest) {
+	m := newMultistatus(w)
+	var err error
+	for _, href := range req.Hrefs {
+		u, perr := url.Parse(strings.TrimSpace(href))
+		if perr != nil {
+			m.status(href, http.StatusNotFound)
+			continue
+		}
+		res, ok := s.h.parse(u.Path)
+		if !ok || res.kind != kindObject || res.userID != s.user.ID {
+			m.status(u.Path, http.StatusNotFound)
+			continue
+		}
+		var c *card
+		c, err = s.find(res.name)
+		if errors.Is(err, ErrNotFound) {
+			m.status(u.Path, http.StatusNotFound)
+			err = nil
+			continue
+		}
+		if err == nil {
+			err = s.respond(m, res, c, &req.propSelection)
+		}
+		if err != nil {
+			break
+		}
+	}
+	m.finish("", err)
+}
+
+// syncCollection returns the address objects changed since the sync token, all of them when
+// it is empty, and 404 for those deleted.
+func (s *session) syncCollection(w http.ResponseWriter, r *http.Request, req *syncCollectionRequest) {
+	if req.SyncToken == "" {
+		token, err := s.syncToken()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m := newMultistatus(w)
+		err = s.eachCard(r, func(c *card) error {
+			return s.respond(m, s.objectResource(c), c, &req.propSelection)
+		})
+		m.finish(token, err)
+		return
+	}
+	cs, err := s.changes(req.SyncToken)
+	if errors.Is(err, errInvalidSyncToken) || errors.Is(err, changelog.ErrResyncRequired) {
+		writeError(w, http.StatusForbidden, conditionValidSyncToken)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m := newMultistatus(w)
+	err = s.withObjects(cs.Deleted, func(c *card) error {
+		m.status(s.h.path(s.objectResource(c)), http.StatusNotFound)
+		return nil
+	})
+	if err == nil {
+		err = s.withObjects(append(cs.Created, cs.Updated...), func(c *card) error {
+			return s.respond(m, s.objectResource(c), c, &req.propSelection)
+		})
+	}
+	m.finish(s.token, err)
+}
+
+// get serves the vCard of an address object.
+func (s *session) get(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindObject {
+		http.Error(w, "not an address object", http.StatusMethodNotAllowed)
+		return
+	}
+	c, ok := s.findObject(w, res)
+	if !ok {
+		return
+	}
+	data, err := s.render(c)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", c.etag())
+	w.Header().Set("Last-Modified", c.contact.UpdatedAt.UTC().Format(http.TimeFormat))
+	if v := r.Header.Get("If-None-Match"); v != "" && matchETag(v, c.etag()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// put creates or replaces the contact of an address object from a vCard of any version.
+// Photos are ignored.
+func (s *session) put(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindObject {
+		http.Error(w, "not an address object", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxResourceSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxResourceSize {
+		http.Error(w, "vcard is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	existing, err := s.find(res.name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.checkPreconditions(r, existing) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	e, err := vcard.NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		writeError(w, http.StatusForbidden, conditionValidData)
+		return
+	}
+	if e.UID != "" {
+		conflict, err := s.uidConflict(e.UID, existing)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if conflict {
+			writeError(w, http.StatusForbidden, conditionNoUIDConflict)
+			return
+		}
+	}
+	c := e.Contact
+	c.UserID = s.user.ID
+
+	// the organization is resolved along with the write, so a rejected card creates none
+	if existing != nil {
+		c.ID, c.Version, c.CreatedAt = existing.contact.ID, existing.contact.Version, existing.contact.CreatedAt
+		var invalid error
+		err := s.h.Contacts.Transaction(func(tx *contact.DB) error {
+			if err := tx.ResolveOrganization(&c, e.Organization); err != nil {
+				return err
+			}
+			invalid = tx.Update(&c)
+			return invalid
+		})
+		if errors.Is(invalid, contact.ErrVersionConflict) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if invalid != nil {
+			writeError(w, http.StatusForbidden, conditionValidData)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if e.UID != "" && e.UID != existing.object.UID {
+			if err := s.h.Objects.SetUID(&existing.object, e.UID); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		w.Header().Set("ETag", (&card{contact: c}).etag())
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var created *contact.Contact
+	var invalid error
+	err = s.h.Contacts.Conn.Transaction(func(tx *gorm.DB) error {
+		contacts := &contact.DB{Conn: tx, Actor: s.h.Contacts.Actor}
+		if err := contacts.ResolveOrganization(&c, e.Organization); err != nil {
+			return err
+		}
+		created, invalid = contacts.Create(c)
+		if invalid != nil {
+			return invalid
+		}
+		o := Object{UserID: s.user.ID, ContactID: created.ID, Name: res.name, UID: e.UID}
+		if o.UID == "" {
+			o.UID = DefaultObject(s.user.ID, created.ID).UID
+		}
+		return (&DB{Conn: tx}).Save(&o)
+	})
+	if invalid != nil {
+		writeError(w, http.StatusForbidden, conditionValidData)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", (&card{contact: *created}).etag())
+	w.WriteHeader(http.StatusCreated)
+}
+
+// uidConflict tells if the UID belongs to another of the user's contacts.
+func (s *session) uidConflict(uid string, existing *card) (bool, error) {
+	o, err := s.h.Objects.FindByUID(s.user.ID, uid)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if existing != nil && o.ContactID == existing.contact.ID {
+		return false, nil
+	}
+	_, err = s.contact(o.ContactID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// delete deletes the contact of an address object.
+func (s *session) delete(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindObject {
+		http.Error(w, "only address objects can be deleted", http.StatusForbidden)
+		return
+	}
+	c, ok := s.findObject(w, res)
+	if !ok {
+		return
+	}
+	if !s.checkPreconditions(r, c) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if _, err := s.h.Contacts.Delete(s.user.ID, c.contact.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPreconditions checks the If-Match and If-None-Match headers against the card, which is
+// nil when the object doesn't exist.
+func (s *session) checkPreconditions(r *http.Request, c *card) bool {
+	if v := r.Header.Get("If-Match"); v != "" && (c == nil || !matchETag(v, c.etag())) {
+		return false
+	}
+	if v := r.Header.Get("If-None-Match"); v != "" && c != nil && matchETag(v, c.etag()) {
+		return false
+	}
+	return true
+}
+
+// matchETag tells if the etag is in the list of an If-Match or If-None-Match header.
+func matchETag(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
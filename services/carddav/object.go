@@ -0,0 +1,136 @@
+package carddav
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+
+	// ErrNotFound is returned when the user has no address object with the name.
+	ErrNotFound = errors.New("address object not found")
+)
+
+// Object records the name and UID clients gave a contact they created, so the contact is
+// served back where it was put. Contacts without an object are named after their id.
+type Object struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserID    uint   `json:"user_id" gorm:"column:user_id;uniqueIndex:idx_carddav_user_name;index:idx_carddav_user_uid"`
+	ContactID uint   `json:"contact_id" gorm:"column:contact_id;uniqueIndex"`
+	Name      string `json:"name" gorm:"uniqueIndex:idx_carddav_user_name"`
+	UID       string `json:"uid" gorm:"column:uid;index:idx_carddav_user_uid"`
+}
+
+// TableName keeps the table apart from other kinds of objects.
+func (Object) TableName() string {
+	return "carddav_objects"
+}
+
+// DB - address object repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the address object repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the address objects table
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Object{})
+}
+
+// Find returns the user's object with the name.
+func (d *DB) Find(userID uint, name string) (*Object, error) {
+	return d.first("user_id = ? AND name = ?", userID, name)
+}
+
+// FindByUID returns the user's object with the UID.
+func (d *DB) FindByUID(userID uint, uid string) (*Object, error) {
+	return d.first("user_id = ? AND uid = ?", userID, uid)
+}
+
+func (d *DB) first(query string, args ...interface{}) (*Object, error) {
+	var o Object
+	err := d.Conn.Where(query, args...).First(&o).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ForContacts returns the objects of the given contacts by contact id. Contacts without an
+// object get their default one.
+func (d *DB) ForContacts(userID uint, contactIDs []uint) (map[uint]Object, error) {
+	objects := make(map[uint]Object, len(contactIDs))
+	if len(contactIDs) == 0 {
+		return objects, nil
+	}
+	var found []Object
+	err := d.Conn.Where("user_id = ? AND contact_id IN ?", userID, contactIDs).Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range found {
+		objects[o.ContactID] = o
+	}
+	for _, id := range contactIDs {
+		if _, ok := objects[id]; !ok {
+			objects[id] = DefaultObject(userID, id)
+		}
+	}
+	return objects, nil
+}
+
+// Save records the object, replacing the one of the same name, whose contact was deleted.
+func (d *DB) Save(o *Object) error {
+	old, err := d.Find(o.UserID, o.Name)
+	if errors.Is(err, ErrNotFound) {
+		return d.Conn.Create(o).Error
+	}
+	if err != nil {
+		return err
+	}
+	o.ID = old.ID
+	return d.Conn.Save(o).Error
+}
+
+// SetUID changes the UID of the contact's object.
+func (d *DB) SetUID(o *Object, uid string) error {
+	o.UID = uid
+	if o.ID == 0 {
+		return d.Conn.Create(o).Error
+	}
+	return d.Conn.Model(o).Update("uid", uid).Error
+}
+
+// DefaultObject is the object of a contact that wasn't put by a client.
+func DefaultObject(userID, contactID uint) Object {
+	return Object{
+		UserID:    userID,
+		ContactID: contactID,
+		Name:      fmt.Sprintf("%d.vcf", contactID),
+		UID:       fmt.Sprintf("contact-%d", contactID),
+	}
+}
+
+// defaultContactID returns the id of the contact a default object name refers to.
+func defaultContactID(name string) (uint, bool) {
+	id, err := strconv.ParseUint(strings.TrimSuffix(name, ".vcf"), 10, 32)
+	if err != nil || id == 0 || !strings.HasSuffix(name, ".vcf") {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,207 @@
+package carddav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/vcard"
+)
+
+// contentType is the media type of the cards served.
+const contentType = vcard.ContentType + "; charset=utf-8"
+
+// card is a contact served as an address object.
+type card struct {
+	contact contact.Contact
+	object  Object
+	// data is the vCard, rendered on first use
+	data []byte
+}
+
+func (c *card) etag() string {
+	return fmt.Sprintf(`"%d-%d"`, c.contact.ID, c.contact.Version)
+}
+
+// allProps are the properties returned for allprop, by kind of resource. Sync tokens and the
+// address data are only returned when asked for.
+var allProps = map[kind][]xml.Name{
+	kindRoot:        {propResourceType, propDisplayName, propCurrentUserPrincipal, propCurrentUserPrivileges},
+	kindPrincipal:   {propResourceType, propDisplayName, propCurrentUserPrincipal, propPrincipalURL, propAddressBookHomeSet, propCurrentUserPrivileges},
+	kindHome:        {propResourceType, propDisplayName, propCurrentUserPrincipal, propOwner, propCurrentUserPrivileges},
+	kindAddressBook: {propResourceType, propDisplayName, propCurrentUserPrincipal, propOwner, propCurrentUserPrivileges, propSupportedReports, propSupportedAddressData, propMaxResourceSize, propCTag},
+	kindObject:      {propResourceType, propCurrentUserPrincipal, propOwner, propCurrentUserPrivileges, propETag, propContentType, propContentLength, propLastModified},
+}
+
+// extraProps are the properties that are only returned when asked for.
+var extraProps = map[kind][]xml.Name{
+	kindAddressBook: {propSyncToken},
+	kindObject:      {propAddressData},
+}
+
+// respond writes the properties of the resource selected by sel, and c is the card of objects.
+func (s *session) respond(m *multistatus, res resource, c *card, sel *propSelection) error {
+	href := s.h.path(res)
+	if sel.PropName != nil {
+		var found []property
+		for _, name := range append(allProps[res.kind], extraProps[res.kind]...) {
+			found = append(found, property{name: name})
+		}
+		m.response(href, found, nil)
+		return nil
+	}
+	names := sel.names()
+	if sel.AllProp != nil || len(names) == 0 {
+		names = allProps[res.kind]
+	}
+	var found []property
+	var missing []xml.Name
+	for _, name := range names {
+		value, ok, err := s.property(res, c, name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			found = append(found, property{name: name, value: value})
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	m.response(href, found, missing)
+	return nil
+}
+
+// property returns the value of the property of the resource as inner xml, or false when
+// the resource doesn't have it.
+func (s *session) property(res resource, c *card, name xml.Name) (string, bool, error) {
+	h := s.h
+	switch name {
+	case propResourceType:
+		switch res.kind {
+		case kindPrincipal:
+			return `<d:principal/>`, true, nil
+		case kindAddressBook:
+			return `<d:collection/><card:addressbook/>`, true, nil
+		case kindObject:
+			return "", true, nil
+		}
+		return `<d:collection/>`, true, nil
+	case propDisplayName:
+		switch res.kind {
+		case kindRoot:
+			return "CardDAV", true, nil
+		case kindPrincipal:
+			return escape(s.user.Name), true, nil
+		case kindHome:
+			return "Address books", true, nil
+		case kindAddressBook:
+			return "Contacts", true, nil
+		}
+	case propCurrentUserPrincipal:
+		return hrefElement(h.principalPath(s.user.ID)), true, nil
+	case propPrincipalURL:
+		if res.kind == kindPrincipal {
+			return hrefElement(h.principalPath(s.user.ID)), true, nil
+		}
+	case propAddressBookHomeSet:
+		if res.kind == kindPrincipal {
+			return hrefElement(h.homePath(s.user.ID)), true, nil
+		}
+	case propOwner:
+		if res.kind != kindRoot && res.kind != kindPrincipal {
+			return hrefElement(h.principalPath(s.user.ID)), true, nil
+		}
+	case propCurrentUserPrivileges:
+		if res.kind == kindAddressBook || res.kind == kindObject {
+			return `<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>` +
+				`<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>` +
+				`<d:privilege><d:unbind/></d:privilege>`, true, nil
+		}
+		return `<d:privilege><d:read/></d:privilege>`, true, nil
+	case propSupportedReports:
+		if res.kind == kindAddressBook {
+			return `<d:supported-report><d:report><card:addressbook-multiget/></d:report></d:supported-report>` +
+				`<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>`, true, nil
+		}
+	case propSupportedAddressData:
+		if res.kind == kindAddressBook {
+			return `<card:address-data-type content-type="text/vcard" version="3.0"/>`, true, nil
+		}
+	case propMaxResourceSize:
+		if res.kind == kindAddressBook {
+			return strconv.Itoa(maxResourceSize), true, nil
+		}
+	case propSyncToken, propCTag:
+		if res.kind == kindAddressBook {
+			token, err := s.syncToken()
+			return escape(token), err == nil, err
+		}
+	case propETag:
+		if c != nil {
+			return escape(c.etag()), true, nil
+		}
+	case propContentType:
+		if c != nil {
+			return contentType, true, nil
+		}
+	case propContentLength:
+		if c != nil {
+			data, err := s.render(c)
+			return strconv.Itoa(len(data)), err == nil, err
+		}
+	case propLastModified:
+		if c != nil {
+			return c.contact.UpdatedAt.UTC().Format(http.TimeFormat), true, nil
+		}
+	case propAddressData:
+		if c != nil {
+			data, err := s.render(c)
+			return escape(string(data)), err == nil, err
+		}
+	}
+	return "", false, nil
+}
+
+// render returns the card as a vCard 3.0 with its UID and organization.
+func (s *session) render(c *card) ([]byte, error) {
+	if c.data != nil {
+		return c.data, nil
+	}
+	e := &vcard.Entry{Contact: c.contact, UID: c.object.UID}
+	if id := c.contact.OrganizationID; id != nil {
+		names, err := s.organizationNames()
+		if err != nil {
+			return nil, err
+		}
+		e.Organization = names[*id]
+	}
+	var buf bytes.Buffer
+	enc, err := vcard.NewEncoder(&buf, vcard.Version3)
+	if err == nil {
+		err = enc.Encode(e)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.data = buf.Bytes()
+	return c.data, nil
+}
+
+// organizationNames maps the ids of the user's organizations to their names, loading them once.
+func (s *session) organizationNames() (map[uint]string, error) {
+	if s.orgNames != nil {
+		return s.orgNames, nil
+	}
+	orgs, err := s.h.Orgs.FindByUserID(s.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.orgNames = make(map[uint]string, len(orgs))
+	for _, o := range orgs {
+		s.orgNames[o.ID] = o.Name
+	}
+	return s.orgNames, nil
+}
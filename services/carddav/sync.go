@@ -0,0 +1,62 @@
+package carddav
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/contact"
+)
+
+// syncTokenPrefix starts the sync tokens, which end with the change sequence number of the user's
+// contacts, see changelog.
+const syncTokenPrefix = "http://grpc-contact-manager/ns/sync/"
+
+var errInvalidSyncToken = errors.New("invalid sync token")
+
+// syncToken returns the token of the current state of the address book, computed once per
+// request so changes made while a report is written are reported by the next one.
+func (s *session) syncToken() (string, error) {
+	if s.token != "" {
+		return s.token, nil
+	}
+	seq, err := (&changelog.DB{Conn: s.h.Contacts.Conn}).Current(s.user.ID)
+	if err != nil {
+		return "", err
+	}
+	s.token = formatSyncToken(seq)
+	return s.token, nil
+}
+
+func formatSyncToken(seq uint64) string {
+	return syncTokenPrefix + strconv.FormatUint(seq, 10)
+}
+
+// parseSyncToken returns the change sequence number of the token.
+func parseSyncToken(token string) (uint64, error) {
+	if !strings.HasPrefix(token, syncTokenPrefix) {
+		return 0, errInvalidSyncToken
+	}
+	seq, err := strconv.ParseUint(strings.TrimPrefix(token, syncTokenPrefix), 10, 64)
+	if err != nil {
+		return 0, errInvalidSyncToken
+	}
+	return seq, nil
+}
+
+// changes returns the changes to the user's contacts since the token, and makes the token they
+// lead to the one of the session. changelog.ErrResyncRequired is returned for tokens too old to
+// tell, including the time based tokens handed out before the change log.
+func (s *session) changes(token string) (*contact.ChangeSet, error) {
+	since, err := parseSyncToken(token)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := s.h.Contacts.Changes(s.user.ID, since)
+	if err != nil {
+		return nil, err
+	}
+	s.token = formatSyncToken(cs.Seq)
+	return cs, nil
+}
@@ -0,0 +1,212 @@
+package carddav
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var errInvalidBody = errors.New("invalid xml body")
+
+// Properties served.
+var (
+	propResourceType          = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName           = xml.Name{Space: nsDAV, Local: "displayname"}
+	propCurrentUserPrincipal  = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL          = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner                 = xml.Name{Space: nsDAV, Local: "owner"}
+	propCurrentUserPrivileges = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReports      = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propSyncToken             = xml.Name{Space: nsDAV, Local: "sync-token"}
+	propETag                  = xml.Name{Space: nsDAV, Local: "getetag"}
+	propContentType           = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propContentLength         = xml.Name{Space: nsDAV, Local: "getcontentlength"}
+	propLastModified          = xml.Name{Space: nsDAV, Local: "getlastmodified"}
+	propAddressBookHomeSet    = xml.Name{Space: nsCardDAV, Local: "addressbook-home-set"}
+	propSupportedAddressData  = xml.Name{Space: nsCardDAV, Local: "supported-address-data"}
+	propMaxResourceSize       = xml.Name{Space: nsCardDAV, Local: "max-resource-size"}
+	propAddressData           = xml.Name{Space: nsCardDAV, Local: "address-data"}
+	propCTag                  = xml.Name{Space: nsCalServer, Local: "getctag"}
+)
+
+// Reports served.
+var (
+	reportMultiget       = xml.Name{Space: nsCardDAV, Local: "addressbook-multiget"}
+	reportSyncCollection = xml.Name{Space: nsDAV, Local: "sync-collection"}
+)
+
+// prefixes of the namespaces in responses.
+var prefixes = map[string]string{
+	nsDAV:       "d",
+	nsCardDAV:   "card",
+	nsCalServer: "cs",
+}
+
+// element is an element named by the first field of the struct it's decoded into.
+type element struct {
+	XMLName xml.Name
+}
+
+// propSelection is what a request asks of the properties: their names only, all the common
+// ones, or the listed ones.
+type propSelection struct {
+	PropName *struct{} `xml:"DAV: propname"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	Prop     *struct {
+		Names []element `xml:",any"`
+	} `xml:"DAV: prop"`
+}
+
+func (p *propSelection) names() []xml.Name {
+	if p.Prop == nil {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Prop.Names))
+	for i, e := range p.Prop.Names {
+		names[i] = e.XMLName
+	}
+	return names
+}
+
+type propfindRequest struct {
+	XMLName xml.Name `xml:"DAV: propfind"`
+	propSelection
+}
+
+type proppatchRequest struct {
+	XMLName xml.Name        `xml:"DAV: propertyupdate"`
+	Set     []propSelection `xml:"DAV: set"`
+	Remove  []propSelection `xml:"DAV: remove"`
+}
+
+type multigetRequest struct {
+	XMLName xml.Name `xml:"urn:ietf:params:xml:ns:carddav addressbook-multiget"`
+	propSelection
+	Hrefs []string `xml:"DAV: href"`
+}
+
+type syncCollectionRequest struct {
+	XMLName   xml.Name `xml:"DAV: sync-collection"`
+	SyncToken string   `xml:"DAV: sync-token"`
+	propSelection
+}
+
+// decodeBody decodes the xml body into v, leaving v untouched when the body is empty.
+func decodeBody(r *http.Request, v interface{}) error {
+	err := xml.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return errInvalidBody
+	}
+	return nil
+}
+
+// multistatus streams a 207 Multi-Status response.
+type multistatus struct {
+	w *bufio.Writer
+}
+
+func newMultistatus(w http.ResponseWriter) *multistatus {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	m := &multistatus{w: bufio.NewWriter(w)}
+	m.w.WriteString(xml.Header)
+	m.w.WriteString(`<d:multistatus`)
+	for _, space := range []string{nsDAV, nsCardDAV, nsCalServer} {
+		m.w.WriteString(` xmlns:` + prefixes[space] + `="` + space + `"`)
+	}
+	m.w.WriteString(`>`)
+	return m
+}
+
+// property is a property with its value as inner xml.
+type property struct {
+	name  xml.Name
+	value string
+}
+
+// response writes the properties found for href, and the names of those it doesn't have.
+func (m *multistatus) response(href string, found []property, missing []xml.Name) {
+	m.w.WriteString(`<d:response>` + hrefElement(href))
+	if len(found) > 0 {
+		m.w.WriteString(`<d:propstat><d:prop>`)
+		for _, p := range found {
+			m.w.WriteString(markup(p.name, p.value))
+		}
+		m.w.WriteString(`</d:prop>` + statusElement(http.StatusOK) + `</d:propstat>`)
+	}
+	if len(missing) > 0 {
+		m.propstat(missing, http.StatusNotFound)
+	}
+	m.w.WriteString(`</d:response>`)
+}
+
+// propstat writes the names of the properties with the status.
+func (m *multistatus) propstat(names []xml.Name, code int) {
+	m.w.WriteString(`<d:propstat><d:prop>`)
+	for _, name := range names {
+		m.w.WriteString(markup(name, ""))
+	}
+	m.w.WriteString(`</d:prop>` + statusElement(code) + `</d:propstat>`)
+}
+
+// status writes a response with only a status, such as 404 for deleted objects.
+func (m *multistatus) status(href string, code int) {
+	m.w.WriteString(`<d:response>` + hrefElement(href) + statusElement(code) + `</d:response>`)
+}
+
+// markup returns an element with the inner xml, declaring its namespace when it has no prefix.
+func markup(name xml.Name, inner string) string {
+	tag, attrs := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, attrs = "x:"+name.Local, ` xmlns:x="`+escape(name.Space)+`"`
+	}
+	if inner == "" {
+		return "<" + tag + attrs + "/>"
+	}
+	return "<" + tag + attrs + ">" + inner + "</" + tag + ">"
+}
+
+func hrefElement(path string) string {
+	return `<d:href>` + escape((&url.URL{Path: path}).EscapedPath()) + `</d:href>`
+}
+
+func statusElement(code int) string {
+	return `<d:status>HTTP/1.1 ` + strconv.Itoa(code) + " " + escape(http.StatusText(code)) + `</d:status>`
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// writeError writes a DAV error body with the failed precondition.
+func writeError(w http.ResponseWriter, code int, condition xml.Name) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(code)
+	io.WriteString(w, xml.Header+`<d:error xmlns:d="DAV:" xmlns:card="`+nsCardDAV+`">`+markup(condition, "")+`</d:error>`)
+}
+
+// finish ends the response, after the sync token when there is one. When err is set the
+// response is cut short, since its status was already sent, so the client doesn't mistake it
+// for a complete one.
+func (m *multistatus) finish(syncToken string, err error) {
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if syncToken != "" {
+		m.w.WriteString(`<d:sync-token>` + escape(syncToken) + `</d:sync-token>`)
+	}
+	m.w.WriteString(`</d:multistatus>`)
+	m.w.Flush()
+}
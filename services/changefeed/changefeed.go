@@ -0,0 +1,105 @@
+// Package changefeed tells subscribers that the data of a user changed, on this instance or, through
+// Postgres, on all the instances sharing the database.
+package changefeed
+
+import (
+	"sync"
+)
+
+// Topics of an event
+const (
+	// Contacts is published when the user's contacts changed. The changes are read from the change log.
+	Contacts = "contacts"
+	// Users is published when the user's account changed.
+	Users = "users"
+)
+
+// Event tells that something of the topic changed for the user. Events carry no changes, so
+// events waiting to be received are coalesced into one and a slow subscriber never holds up
+// the publishers.
+type Event struct {
+	Topic  string `json:"topic"`
+	UserID uint   `json:"user_id"`
+}
+
+// Feed delivers the published events to their subscribers.
+type Feed interface {
+	// Publish tells the subscribers of the event about it. It is called once the changes are committed.
+	Publish(e Event)
+	// Subscribe starts receiving the given event. The subscription must be closed once done with.
+	Subscribe(e Event) *Subscription
+}
+
+// Subscription receives a value on C when its event was published since C was last received from.
+type Subscription struct {
+	C <-chan struct{}
+
+	c     chan struct{}
+	close func()
+	once  sync.Once
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.once.Do(s.close)
+}
+
+func (s *Subscription) notify() {
+	select {
+	case s.c <- struct{}{}:
+	default:
+		// an event is already waiting
+	}
+}
+
+// Memory is a feed within a single instance, such as one running on SQLite.
+type Memory struct {
+	mu   sync.Mutex
+	subs map[Event]map[*Subscription]struct{}
+}
+
+// NewMemory creates an in-memory feed without subscribers.
+func NewMemory() *Memory {
+	return &Memory{subs: map[Event]map[*Subscription]struct{}{}}
+}
+
+// Publish notifies the subscribers of the event.
+func (m *Memory) Publish(e Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for s := range m.subs[e] {
+		s.notify()
+	}
+}
+
+// Subscribe starts receiving the event.
+func (m *Memory) Subscribe(e Event) *Subscription {
+	c := make(chan struct{}, 1)
+	s := &Subscription{C: c, c: c}
+	s.close = func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subs[e], s)
+		if len(m.subs[e]) == 0 {
+			delete(m.subs, e)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subs[e] == nil {
+		m.subs[e] = map[*Subscription]struct{}{}
+	}
+	m.subs[e][s] = struct{}{}
+	return s
+}
+
+// notifyAll notifies every subscriber, for when events may have been missed.
+func (m *Memory) notifyAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, subs := range m.subs {
+		for s := range subs {
+			s.notify()
+		}
+	}
+}
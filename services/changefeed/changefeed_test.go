@@ -0,0 +1,41 @@
+package changefeed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	contacts := Event{Topic: Contacts, UserID: 1}
+	a := m.Subscribe(contacts)
+	b := m.Subscribe(contacts)
+	account := m.Subscribe(Event{Topic: Users, UserID: 1})
+	other := m.Subscribe(Event{Topic: Contacts, UserID: 2})
+
+	// events waiting to be received are coalesced
+	m.Publish(contacts)
+	m.Publish(contacts)
+	for _, s := range []*Subscription{a, b} {
+		assert.Len(t, s.C, 1)
+		<-s.C
+	}
+	assert.Empty(t, account.C)
+	assert.Empty(t, other.C)
+
+	a.Close()
+	a.Close()
+	m.Publish(contacts)
+	assert.Empty(t, a.C)
+	assert.Len(t, b.C, 1)
+
+	m.notifyAll()
+	assert.Len(t, account.C, 1)
+	assert.Len(t, other.C, 1)
+
+	for _, s := range []*Subscription{b, account, other} {
+		s.Close()
+	}
+	assert.Empty(t, m.subs)
+}
@@ -0,0 +1,114 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Channel is the Postgres notification channel the events are sent on.
+const Channel = "changefeed"
+
+// DefaultRetryDelay is how long Listen waits before reconnecting by default.
+const DefaultRetryDelay = 5 * time.Second
+
+// listener is the connection the notifications are received on.
+type listener interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// Postgres is a feed shared by the instances using the same database. Events are sent with
+// NOTIFY and received by the Listen loop of every instance, which passes them to its subscribers.
+type Postgres struct {
+	// Conn sends the notifications.
+	Conn *gorm.DB
+	// RetryDelay is how long Listen waits before reconnecting after losing its connection.
+	RetryDelay time.Duration
+
+	local   *Memory
+	connect func(ctx context.Context) (listener, error)
+}
+
+// NewPostgres creates a feed sending its events through conn and listening for them on a
+// connection of its own to the database at dsn.
+func NewPostgres(conn *gorm.DB, dsn string) *Postgres {
+	return &Postgres{
+		Conn:       conn,
+		RetryDelay: DefaultRetryDelay,
+		local:      NewMemory(),
+		connect: func(ctx context.Context) (listener, error) {
+			c, err := pgx.Connect(ctx, dsn)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := c.Exec(ctx, "LISTEN "+Channel); err != nil {
+				c.Close(ctx)
+				return nil, err
+			}
+			return c, nil
+		},
+	}
+}
+
+// Publish sends the event to every instance. When it can't be sent the subscribers of this
+// instance are still notified.
+func (p *Postgres) Publish(e Event) {
+	payload, err := json.Marshal(e)
+	if err == nil {
+		err = p.Conn.Exec("SELECT pg_notify(?, ?)", Channel, string(payload)).Error
+	}
+	if err != nil {
+		log.WithError(err).WithField("topic", e.Topic).Warn("publishing a change")
+		p.local.Publish(e)
+	}
+}
+
+// Subscribe starts receiving the event, once Listen runs.
+func (p *Postgres) Subscribe(e Event) *Subscription {
+	return p.local.Subscribe(e)
+}
+
+// Listen receives the events of all the instances and passes them to the subscribers, until ctx
+// is done. A lost connection is made again after RetryDelay. All subscribers are notified once
+// listening starts, since they may have missed events meanwhile.
+func (p *Postgres) Listen(ctx context.Context) {
+	for {
+		err := p.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).Warn("listening for changes")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.RetryDelay):
+		}
+	}
+}
+
+func (p *Postgres) listen(ctx context.Context) error {
+	l, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer l.Close(context.Background())
+	p.local.notifyAll()
+	for {
+		n, err := l.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			log.WithError(err).WithField("payload", n.Payload).Warn("reading a change")
+			continue
+		}
+		p.local.Publish(e)
+	}
+}
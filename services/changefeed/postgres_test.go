@@ -0,0 +1,84 @@
+package changefeed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeListener hands out the notifications sent on its channel, and fails once it is closed.
+type fakeListener struct {
+	notifications chan *pgconn.Notification
+}
+
+func (l *fakeListener) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case n, ok := <-l.notifications:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return n, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *fakeListener) Close(context.Context) error {
+	return nil
+}
+
+func TestPostgresListen(t *testing.T) {
+	connections := make(chan *fakeListener, 2)
+	p := &Postgres{
+		RetryDelay: time.Millisecond,
+		local:      NewMemory(),
+		connect: func(ctx context.Context) (listener, error) {
+			l := &fakeListener{notifications: make(chan *pgconn.Notification)}
+			connections <- l
+			return l, nil
+		},
+	}
+	sub := p.Subscribe(Event{Topic: Contacts, UserID: 1})
+	defer sub.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Listen(ctx)
+		close(done)
+	}()
+
+	l := <-connections
+	// listening started, so anything missed is to be read
+	<-sub.C
+	l.notifications <- &pgconn.Notification{Channel: Channel, Payload: `{"topic":"contacts","user_id":2}`}
+	l.notifications <- &pgconn.Notification{Channel: Channel, Payload: `not json`}
+	l.notifications <- &pgconn.Notification{Channel: Channel, Payload: `{"topic":"contacts","user_id":1}`}
+	<-sub.C
+
+	// a lost connection is made again, and subscribers are told they may have missed events
+	close(l.notifications)
+	<-connections
+	<-sub.C
+
+	cancel()
+	<-done
+}
+
+func TestPostgresPublishFallsBack(t *testing.T) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:"))
+	require.NoError(t, err)
+	p := NewPostgres(conn, "")
+	sub := p.Subscribe(Event{Topic: Users, UserID: 1})
+	defer sub.Close()
+
+	// SQLite has no pg_notify, so the event only reaches this instance
+	p.Publish(Event{Topic: Users, UserID: 1})
+	assert.Len(t, sub.C, 1)
+}
@@ -0,0 +1,189 @@
+// Package changelog numbers the changes made to the contacts of each user, so clients can ask
+// for what changed since the last number they saw.
+package changelog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actions of an entry
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+const (
+	// Retention is how long entries are kept. Clients that haven't synced for longer have to resync in full.
+	Retention = 30 * 24 * time.Hour
+	// DefaultPruneInterval is how often RunPruner removes the entries past the retention.
+	DefaultPruneInterval = time.Hour
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+
+	// ErrResyncRequired is returned when the changes since a sequence number can't be told,
+	// because the entries were pruned or the number was never handed out.
+	ErrResyncRequired = errors.New("full resync required")
+)
+
+// Entry records that a contact changed. Seq increases by one with every entry of the user.
+type Entry struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	UserID    uint      `json:"user_id" gorm:"column:user_id;uniqueIndex:idx_contact_changes_user_seq"`
+	Seq       uint64    `json:"seq" gorm:"uniqueIndex:idx_contact_changes_user_seq"`
+	ContactID uint      `json:"contact_id" gorm:"column:contact_id"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName keeps the entries apart from the field changes of the contact revisions.
+func (Entry) TableName() string {
+	return "contact_changes"
+}
+
+// sequence holds the last number handed out to a user, and the last one pruned.
+type sequence struct {
+	UserID uint `gorm:"column:user_id;primaryKey;autoIncrement:false"`
+	Value  uint64
+	Pruned uint64
+}
+
+func (sequence) TableName() string {
+	return "change_sequences"
+}
+
+// DB - change log repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the change log repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the change log tables
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Entry{}, sequence{})
+}
+
+// Record appends an entry for each of the user's contacts within the transaction. The user's
+// sequence row stays locked until the transaction ends, so entries are committed in order.
+func Record(tx *gorm.DB, userID uint, action string, contactIDs ...uint) error {
+	if len(contactIDs) == 0 {
+		return nil
+	}
+	if userID == 0 {
+		return errInvalidUserID
+	}
+	n := uint64(len(contactIDs))
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("change_sequences.value + ?", n)}),
+	}).Create(&sequence{UserID: userID, Value: n}).Error
+	if err != nil {
+		return err
+	}
+	var s sequence
+	if err := tx.Where("user_id = ?", userID).First(&s).Error; err != nil {
+		return err
+	}
+	entries := make([]Entry, len(contactIDs))
+	for i, id := range contactIDs {
+		entries[i] = Entry{UserID: userID, Seq: s.Value - n + uint64(i) + 1, ContactID: id, Action: action}
+	}
+	return tx.Create(&entries).Error
+}
+
+// Current returns the last number handed out to the user, 0 when nothing was recorded yet.
+func (d *DB) Current(userID uint) (uint64, error) {
+	s, err := d.sequence(userID)
+	if err != nil {
+		return 0, err
+	}
+	return s.Value, nil
+}
+
+// Since returns the user's entries numbered after since, in order, along with the number they
+// lead to: the last one handed out, or that of the last entry when limit entries were returned.
+// No limit applies when it is 0. ErrResyncRequired is returned when since was pruned or is ahead
+// of the sequence.
+func (d *DB) Since(userID uint, since uint64, limit int) ([]Entry, uint64, error) {
+	s, err := d.sequence(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if since < s.Pruned || since > s.Value {
+		return nil, 0, ErrResyncRequired
+	}
+	var entries []Entry
+	q := d.Conn.Where("user_id = ? AND seq > ? AND seq <= ?", userID, since, s.Value).Order("seq")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit > 0 && len(entries) == limit {
+		return entries, entries[len(entries)-1].Seq, nil
+	}
+	return entries, s.Value, nil
+}
+
+// Prune removes the entries recorded before the given time. Users whose entries are pruned can
+// only sync from the last pruned number on.
+func (d *DB) Prune(before time.Time) (int64, error) {
+	var pruned int64
+	err := d.Conn.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&sequence{}).
+			Where("EXISTS (SELECT 1 FROM contact_changes WHERE contact_changes.user_id = change_sequences.user_id AND contact_changes.created_at < ?)", before).
+			UpdateColumn("pruned", gorm.Expr("(SELECT MAX(seq) FROM contact_changes WHERE contact_changes.user_id = change_sequences.user_id AND contact_changes.created_at < ?)", before)).Error
+		if err != nil {
+			return err
+		}
+		res := tx.Where("created_at < ?", before).Delete(&Entry{})
+		pruned = res.RowsAffected
+		return res.Error
+	})
+	return pruned, err
+}
+
+// RunPruner prunes the entries older than Retention every interval until ctx is done.
+func (d *DB) RunPruner(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPruneInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := d.Prune(time.Now().Add(-Retention))
+		if err != nil {
+			log.WithError(err).Error("pruning the contact change log")
+		} else if n > 0 {
+			log.WithField("entries", n).Info("pruned the contact change log")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *DB) sequence(userID uint) (*sequence, error) {
+	var s sequence
+	err := d.Conn.Where("user_id = ?", userID).Limit(1).Find(&s).Error
+	return &s, err
+}
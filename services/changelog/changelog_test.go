@@ -0,0 +1,103 @@
+package changelog
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db *DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db = d
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRecord(t *testing.T) {
+	require.NoError(t, Record(db.Conn, 1, Create, 10))
+	require.NoError(t, db.Conn.Transaction(func(tx *gorm.DB) error {
+		return Record(tx, 1, Update, 11, 12)
+	}))
+	require.NoError(t, Record(db.Conn, 2, Delete, 20))
+	assert.Equal(t, errInvalidUserID, Record(db.Conn, 0, Create, 1))
+
+	// sequences are kept per user
+	entries, seq, err := db.Since(1, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), seq)
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint64(2), entries[0].Seq)
+	assert.Equal(t, uint(11), entries[0].ContactID)
+	assert.Equal(t, uint64(3), entries[1].Seq)
+	// a limited read leads to the last entry returned
+	entries, seq, err = db.Since(1, 0, 2)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, uint64(2), seq)
+
+	seq, err = db.Current(2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), seq)
+	seq, err = db.Current(3)
+	require.NoError(t, err)
+	assert.Zero(t, seq)
+
+	_, _, err = db.Since(1, 4, 0)
+	assert.Equal(t, ErrResyncRequired, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestPrune(t *testing.T) {
+	require.NoError(t, Record(db.Conn, 1, Create, 10, 11))
+	require.NoError(t, db.Conn.Model(&Entry{}).Where("seq = 1").Update("created_at", time.Now().Add(-2*Retention)).Error)
+	require.NoError(t, Record(db.Conn, 2, Create, 20))
+
+	n, err := db.Prune(time.Now().Add(-Retention))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// the changes since the pruned entry are still known, not those before
+	_, _, err = db.Since(1, 0, 0)
+	assert.Equal(t, ErrResyncRequired, err)
+	entries, _, err := db.Since(1, 1, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	entries, _, err = db.Since(2, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func cleanup() error {
+	for _, table := range []string{"contact_changes", "change_sequences"} {
+		if err := db.Conn.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package contact
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// MaxBatchSize is the largest number of items a batch may hold.
+const MaxBatchSize = 500
+
+var (
+	// ErrBatchTooLarge is returned for batches over MaxBatchSize items.
+	ErrBatchTooLarge = errors.New("batch holds too many items")
+	// ErrBatchAborted is the result of the items of an atomic batch that was rolled back
+	// because another item failed.
+	ErrBatchAborted = errors.New("batch aborted because another item failed")
+)
+
+// Batch runs fn for each of the n items of a batch within a single transaction, passing it
+// a repository bound to the transaction. Each item runs in its own savepoint, so a failed
+// item leaves no trace. When atomic is set the first failure rolls the whole batch back;
+// otherwise the other items are still committed. The returned slice holds the result of
+// every item; the error is only set when the batch itself couldn't run.
+func (db *DB) Batch(n int, atomic bool, fn func(tx *DB, i int) error) ([]error, error) {
+	if n > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+	results := make([]error, n)
+	failed := -1
+	held := map[uint]bool{}
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		for i := 0; i < n; i++ {
+			results[i] = tx.Transaction(func(item *gorm.DB) error {
+				return fn(&DB{Conn: item, Actor: db.Actor, held: held}, i)
+			})
+			if results[i] != nil && atomic {
+				failed = i
+				return results[i]
+			}
+		}
+		return nil
+	})
+	if failed >= 0 {
+		for i := range results {
+			if i != failed {
+				results[i] = ErrBatchAborted
+			}
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for userID := range held {
+		db.Publish(userID)
+	}
+	return results, nil
+}
+
+// Transaction runs fn within a transaction, passing it a repository bound to the transaction.
+// The changes are published once it is committed.
+func (db *DB) Transaction(fn func(tx *DB) error) error {
+	held := map[uint]bool{}
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		return fn(&DB{Conn: tx, Actor: db.Actor, held: held})
+	})
+	if err != nil {
+		return err
+	}
+	for userID := range held {
+		db.Publish(userID)
+	}
+	return nil
+}
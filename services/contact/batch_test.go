@@ -0,0 +1,63 @@
+package contact
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchContacts(userID uint, n int) []Contact {
+	contacts := make([]Contact, n)
+	for i := range contacts {
+		contacts[i] = Contact{
+			UserID:   userID,
+			Fullname: fmt.Sprintf("Contact %d", i),
+			Email:    fmt.Sprintf("contact%d@gmail.com", i),
+			Phone:    "+2347033304280",
+			Address:  "33, Tioya Street, Ibadan",
+		}
+	}
+	return contacts
+}
+
+func TestBatch(t *testing.T) {
+	userID := uint(9)
+	create := func(contacts []Contact) func(tx *DB, i int) error {
+		return func(tx *DB, i int) error {
+			_, err := tx.Create(contacts[i])
+			return err
+		}
+	}
+
+	contacts := batchContacts(userID, 3)
+	contacts[1].Phone = ""
+	results, err := db.Batch(len(contacts), true, create(contacts))
+	require.NoError(t, err)
+	assert.Equal(t, []error{ErrBatchAborted, errEmptyPhone, ErrBatchAborted}, results)
+	found, err := db.FindByUserID(uint32(userID))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	results, err = db.Batch(len(contacts), false, create(contacts))
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, errEmptyPhone, nil}, results)
+	found, err = db.FindByUserID(uint32(userID))
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	// items see the changes of the earlier items of the batch
+	contacts = batchContacts(userID+1, 2)
+	contacts[1].Email = contacts[0].Email
+	results, err = db.Batch(len(contacts), false, create(contacts))
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, errContactExists}, results)
+
+	_, err = db.Batch(MaxBatchSize+1, false, create(nil))
+	assert.Equal(t, ErrBatchTooLarge, err)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
@@ -1,8 +1,13 @@
 package contact
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	"grpc-contact-manager/services/changefeed"
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/organization"
 	"grpc-contact-manager/services/user"
 
 	"gorm.io/gorm"
@@ -17,6 +22,10 @@ var (
 	errContactExists      = errors.New("contact with this email exists")
 	errNotUserContact     = errors.New("user has no access to contact")
 	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidOrg         = errors.New("organization not found")
+
+	// ErrVersionConflict is returned when updating a contact that was changed since it was read.
+	ErrVersionConflict = errors.New("contact was modified by someone else")
 )
 
 type Contact struct {
@@ -27,6 +36,13 @@ type Contact struct {
 	Phone    string `json:"phone"`
 	Address  string `json:"address"`
 	Email    string `json:"email" gorm:"column:email;index:idx_email"`
+	// LastContactedAt is the time of the latest logged interaction, kept here so contacts can be sorted by it.
+	LastContactedAt *time.Time `json:"last_contacted_at" gorm:"column:last_contacted_at;index"`
+	OrganizationID  *uint      `json:"organization_id" gorm:"column:organization_id;index"`
+	JobTitle        string     `json:"job_title"`
+	Department      string     `json:"department"`
+	// Version is incremented on every change to the fields above, see Update.
+	Version uint `json:"version" gorm:"not null;default:1"`
 	// Email    string `json:"email" gorm:"column:email index:unique"`
 	// TODO: Revisit the multiple column index, so a user doesn't add a contact with more than 1 same email
 	// UserIDEmail string `json:"-" gorm:"uniqueIndex:idx_user_id_email"`
@@ -35,6 +51,14 @@ type Contact struct {
 // DB - db connection abstraction
 type DB struct {
 	Conn *gorm.DB
+	// Actor is recorded on the revisions written through this DB. The contact's
+	// user is recorded when it is empty.
+	Actor string
+	// Feed, when set, is told about the changes committed through this DB.
+	Feed changefeed.Feed
+
+	// held collects the users to publish to once the batch this DB runs in is committed.
+	held map[uint]bool
 }
 
 // New creates a new instance of the contact repository
@@ -45,9 +69,20 @@ func New(conn *gorm.DB) (*DB, error) {
 	return &DB{Conn: conn}, nil
 }
 
+// As returns a copy of the repository recording changes as made by the given actor.
+func (db *DB) As(actor string) *DB {
+	return &DB{Conn: db.Conn, Actor: actor, Feed: db.Feed}
+}
+
 // Migrate Creates new contact table
 func (d *DB) Migrate() error {
-	return d.Conn.AutoMigrate(Contact{})
+	if err := (&organization.DB{Conn: d.Conn}).Migrate(); err != nil {
+		return err
+	}
+	if err := (&changelog.DB{Conn: d.Conn}).Migrate(); err != nil {
+		return err
+	}
+	return d.Conn.AutoMigrate(Contact{}, Revision{})
 }
 
 // Create adds a new contact record for the given user.
@@ -64,8 +99,31 @@ func (db *DB) Create(contact Contact) (*Contact, error) {
 	if c.ID != 0 {
 		return nil, errContactExists
 	}
-	result := db.Conn.Create(&contact)
-	return &contact, result.Error
+	if err := db.checkOrganization(&contact); err != nil {
+		return nil, err
+	}
+	if domain := organization.EmailDomain(contact.Email); contact.OrganizationID == nil && domain != "" {
+		// suggest the organization the user already has for the email domain
+		orgs, err := db.organizations().FindByDomain(contact.UserID, domain)
+		if err != nil {
+			return nil, err
+		}
+		if len(orgs) == 1 {
+			contact.OrganizationID = &orgs[0].ID
+		}
+	}
+	contact.Version = 1
+	err = db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&contact).Error; err != nil {
+			return err
+		}
+		return db.record(tx, &contact, &contact, ActionCreate, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.Publish(contact.UserID)
+	return &contact, nil
 }
 
 // FindByUserID returns all the contacts for a given user ID
@@ -84,6 +142,99 @@ func (db *DB) FindByID(userID, id uint) (*Contact, error) {
 	return &contact, err
 }
 
+// DefaultExportBatchSize is the number of rows Export reads at a time by default.
+const DefaultExportBatchSize = 500
+
+// Export calls fn for every contact of the user with an ID above afterID, in ID order. Rows are
+// read in batches of batchSize keyed on the last ID seen, so memory use doesn't grow with the
+// number of contacts. Export stops at the first error of fn or when ctx is done.
+func (db *DB) Export(ctx context.Context, userID, afterID uint, batchSize int, fn func(batch []Contact) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultExportBatchSize
+	}
+	batch := make([]Contact, 0, batchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		err := db.Conn.WithContext(ctx).Where("user_id = ? AND id > ?", userID, afterID).
+			Order("id").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+// FindRecentlyContacted returns the contacts of the user, most recently contacted first.
+// Contacts that were never contacted come last.
+func (db *DB) FindRecentlyContacted(userID uint32) ([]Contact, error) {
+	var contacts []Contact
+	res := db.Conn.Where("user_id = ?", userID).
+		Order("CASE WHEN last_contacted_at IS NULL THEN 1 ELSE 0 END, last_contacted_at DESC, id").
+		Find(&contacts)
+	return contacts, res.Error
+}
+
+// FindByOrganization returns the contacts of the user that belong to the organization.
+func (db *DB) FindByOrganization(userID, organizationID uint) ([]Contact, error) {
+	var contacts []Contact
+	res := db.Conn.Where("user_id = ? AND organization_id = ?", userID, organizationID).Order("full_name").Find(&contacts)
+	return contacts, res.Error
+}
+
+// DeleteOrganization removes the user's organization and detaches its contacts from it. Each
+// detached contact gets a new version and a revision, as if it was updated.
+func (db *DB) DeleteOrganization(userID, organizationID uint) (*organization.Organization, error) {
+	var o *organization.Organization
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		found, err := (&organization.DB{Conn: tx}).Find(userID, organizationID)
+		if err != nil {
+			return err
+		}
+		var contacts []Contact
+		if err := tx.Where("user_id = ? AND organization_id = ?", userID, organizationID).Find(&contacts).Error; err != nil {
+			return err
+		}
+		for i := range contacts {
+			old := contacts[i]
+			c := &contacts[i]
+			c.OrganizationID = nil
+			c.Version++
+			if err := tx.Model(c).Select("organization_id", "version").Updates(c).Error; err != nil {
+				return err
+			}
+			if err := db.record(tx, &old, c, ActionUpdate, false); err != nil {
+				return err
+			}
+		}
+		// deleted contacts are detached too, so they aren't restored into a missing organization
+		err = tx.Unscoped().Model(&Contact{}).
+			Where("user_id = ? AND organization_id = ?", userID, organizationID).
+			UpdateColumn("organization_id", nil).Error
+		if err != nil {
+			return err
+		}
+		o = found
+		return tx.Delete(found).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.Publish(userID)
+	return o, nil
+}
+
 // Search search the full name and email for the given string
 func (db *DB) Search(userID uint32, search string) ([]Contact, error) {
 	var contacts []Contact
@@ -91,9 +242,106 @@ func (db *DB) Search(userID uint32, search string) ([]Contact, error) {
 	return contacts, res.Error
 }
 
-// Update the value of a contact
+// updateColumns are the columns written by Update.
+var updateColumns = []string{"full_name", "email", "phone", "address", "organization_id", "job_title", "department", "version"}
+
+// Update the value of a contact and records which fields changed. contact.Version must
+// be the version the changes were made against: ErrVersionConflict is returned when the
+// contact was changed since. On success contact.Version is the new version.
 func (db *DB) Update(contact *Contact) error {
-	return db.Conn.Save(&contact).Error
+	if err := contact.validate(); err != nil {
+		return err
+	}
+	if err := db.checkOrganization(contact); err != nil {
+		return err
+	}
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		var old Contact
+		if err := tx.First(&old, contact.ID).Error; err != nil {
+			return err
+		}
+		if old.Version != contact.Version {
+			return ErrVersionConflict
+		}
+		if len(diff(&old, contact)) == 0 {
+			return nil
+		}
+		contact.Version++
+		res := tx.Model(contact).Where("version = ?", old.Version).Select(updateColumns).Updates(contact)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			contact.Version--
+			return ErrVersionConflict
+		}
+		return db.record(tx, &old, contact, ActionUpdate, false)
+	})
+	if err != nil {
+		return err
+	}
+	db.Publish(contact.UserID)
+	return nil
+}
+
+// Delete soft deletes the user's contact. Its revisions are kept so it can be reverted.
+func (db *DB) Delete(userID, id uint) (*Contact, error) {
+	contact, err := db.FindByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(contact).Error; err != nil {
+			return err
+		}
+		return db.record(tx, contact, contact, ActionDelete, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.Publish(userID)
+	return contact, nil
+}
+
+// Publish tells the feed about committed changes to the user's contacts, such as those made
+// through other repositories. Within a batch it waits for the batch to be committed.
+func (db *DB) Publish(userID uint) {
+	if db.held != nil {
+		db.held[userID] = true
+		return
+	}
+	if db.Feed != nil {
+		db.Feed.Publish(changefeed.Event{Topic: changefeed.Contacts, UserID: userID})
+	}
+}
+
+// checkOrganization makes sure the contact's organization, if any, belongs to the same user.
+func (db *DB) checkOrganization(c *Contact) error {
+	if c.OrganizationID == nil {
+		return nil
+	}
+	if _, err := db.organizations().Find(c.UserID, *c.OrganizationID); err != nil {
+		if errors.Is(err, organization.ErrNotFound) {
+			return errInvalidOrg
+		}
+		return err
+	}
+	return nil
+}
+
+// ResolveOrganization sets the contact's organization to the user's one with the given name,
+// creating it when the user has none. An empty name leaves the contact as it is.
+func (db *DB) ResolveOrganization(c *Contact, name string) error {
+	id, err := db.organizations().Resolve(c.UserID, name)
+	if err != nil || id == nil {
+		return err
+	}
+	c.OrganizationID = id
+	return nil
+}
+
+func (db *DB) organizations() *organization.DB {
+	return &organization.DB{Conn: db.Conn}
 }
 
 func (c *Contact) validate() error {
@@ -1,9 +1,13 @@
 package contact
 
 import (
+	"context"
 	"log"
 	"os"
 	"testing"
+	"time"
+
+	"grpc-contact-manager/services/organization"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -216,6 +220,169 @@ func TestUpdate(t *testing.T) {
 	})
 }
 
+func TestUpdateVersionConflict(t *testing.T) {
+	userID := uint(1)
+	createForSearch(t, userID)
+	first, err := db.FindByID(userID, 1)
+	require.NoError(t, err)
+	second, err := db.FindByID(userID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), first.Version)
+
+	first.Phone = "08155040074"
+	require.NoError(t, db.Update(first))
+	assert.Equal(t, uint(2), first.Version)
+
+	// the second client still has version 1
+	second.Address = "Lagos"
+	assert.Equal(t, ErrVersionConflict, db.Update(second))
+	assert.Equal(t, uint(1), second.Version)
+
+	found, err := db.FindByID(userID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "08155040074", found.Phone)
+	assert.Equal(t, "33, Tioya Street, Ibadan", found.Address)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
+func TestFindRecentlyContacted(t *testing.T) {
+	userID := uint(1)
+	createForSearch(t, userID)
+	contacts, err := db.FindByUserID(uint32(userID))
+	require.NoError(t, err)
+	require.Len(t, contacts, 2)
+
+	contacted := time.Now()
+	require.NoError(t, db.Conn.Model(&contacts[1]).Update("last_contacted_at", contacted).Error)
+
+	res, err := db.FindRecentlyContacted(uint32(userID))
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, contacts[1].ID, res[0].ID)
+	require.NotNil(t, res[0].LastContactedAt)
+	assert.Nil(t, res[1].LastContactedAt)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
+func TestCreateSuggestsOrganization(t *testing.T) {
+	org, err := db.organizations().Create(organization.Organization{UserID: 1, Name: "Acme", Domain: "acme.com"})
+	require.NoError(t, err)
+
+	c, err := db.Create(Contact{
+		UserID:   1,
+		Fullname: "Jane Doe",
+		Email:    "jane@acme.com",
+		Phone:    "+2347033304280",
+		Address:  "33, Tioya Street, Ibadan",
+		JobTitle: "Engineer",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.OrganizationID)
+	assert.Equal(t, org.ID, *c.OrganizationID)
+
+	members, err := db.FindByOrganization(1, org.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+
+	// organizations of other users can't be used
+	other, err := db.organizations().Create(organization.Organization{UserID: 2, Name: "Globex"})
+	require.NoError(t, err)
+	c.OrganizationID = &other.ID
+	assert.Equal(t, errInvalidOrg, db.Update(c))
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+		require.Nil(t, db.Conn.Exec("DELETE FROM organizations").Error)
+	})
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	org, err := db.organizations().Create(organization.Organization{UserID: 1, Name: "Acme", Domain: "acme.com"})
+	require.NoError(t, err)
+	c, err := db.Create(Contact{
+		UserID:   1,
+		Fullname: "Jane Doe",
+		Email:    "jane@acme.com",
+		Phone:    "+2347033304280",
+		Address:  "33, Tioya Street, Ibadan",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.OrganizationID)
+
+	_, err = db.DeleteOrganization(1, org.ID)
+	require.NoError(t, err)
+	found, err := db.FindByID(1, c.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.OrganizationID)
+	// the contact changed, so copies fetched before no longer match
+	assert.Equal(t, c.Version+1, found.Version)
+	revisions, err := db.Revisions(1, c.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, ActionUpdate, revisions[0].Action)
+	assert.Equal(t, []string{"organization_id"}, revisions[0].FieldList())
+
+	_, err = db.DeleteOrganization(1, org.ID)
+	assert.Equal(t, organization.ErrNotFound, err)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+		require.Nil(t, db.Conn.Exec("DELETE FROM organizations").Error)
+	})
+}
+
+func TestExport(t *testing.T) {
+	userID := uint(12)
+	for _, c := range batchContacts(userID, 7) {
+		_, err := db.Create(c)
+		require.NoError(t, err)
+	}
+	_, err := db.Create(batchContacts(userID+1, 1)[0])
+	require.NoError(t, err)
+
+	var (
+		sizes []int
+		ids   []uint
+	)
+	err = db.Export(context.Background(), userID, 0, 3, func(batch []Contact) error {
+		sizes = append(sizes, len(batch))
+		for _, c := range batch {
+			assert.Equal(t, userID, c.UserID)
+			ids = append(ids, c.ID)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 3, 1}, sizes)
+	require.Len(t, ids, 7)
+
+	// resume after the third contact
+	var resumed int
+	err = db.Export(context.Background(), userID, ids[2], 0, func(batch []Contact) error {
+		resumed += len(batch)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, resumed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = db.Export(ctx, userID, 0, 2, func(batch []Contact) error {
+		cancel()
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
 func createForSearch(t *testing.T, userID uint) {
 	contacts := []Contact{
 		{
@@ -243,5 +410,10 @@ func createForSearch(t *testing.T, userID uint) {
 }
 
 func cleanup() error {
+	for _, table := range []string{"contact_changes", "change_sequences", "revisions"} {
+		if err := db.Conn.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
 	return db.Conn.Exec("DELETE FROM contacts").Error
 }
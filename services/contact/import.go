@@ -0,0 +1,220 @@
+package contact
+
+import (
+	"errors"
+)
+
+// Duplicate policies of an import. A duplicate is a contact of the user with the same email.
+const (
+	// ImportSkip leaves existing contacts untouched.
+	ImportSkip = "skip"
+	// ImportUpdate replaces the fields of existing contacts with the imported non-empty values.
+	ImportUpdate = "update"
+)
+
+const (
+	// ImportChunkSize is the number of rows written per transaction.
+	ImportChunkSize = 100
+	// MaxImportErrors caps the row errors kept in an import summary. Failed rows are still counted.
+	MaxImportErrors = 1000
+)
+
+var errInvalidPolicy = errors.New("duplicate policy must be skip or update")
+
+// ImportError is the error of a row of an import. Rows are numbered from 1 in the order they
+// were added, or as given to AddAt.
+type ImportError struct {
+	Row int
+	Err error
+}
+
+// ImportSummary counts the outcome of the rows of an import.
+type ImportSummary struct {
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+	Errors  []ImportError
+}
+
+// Importer imports contacts for a user. Rows are validated as they are added and written
+// in chunks of ImportChunkSize, each chunk in its own transaction.
+type Importer struct {
+	// OnWrite, when set, is called for every created or updated row once its chunk is
+	// committed. An error is reported for the row, though the contact stays written.
+	OnWrite func(row int, c *Contact) error
+
+	db      *DB
+	userID  uint
+	policy  string
+	rows    int
+	pending []pendingRow
+	summary ImportSummary
+}
+
+type pendingRow struct {
+	row          int
+	contact      Contact
+	organization string
+}
+
+type outcome int
+
+const (
+	created outcome = iota
+	updated
+	skipped
+)
+
+// NewImporter starts an import for the user with the given duplicate policy. ImportSkip is used when it is empty.
+func (db *DB) NewImporter(userID uint, policy string) (*Importer, error) {
+	if userID == 0 {
+		return nil, errInvalidUserID
+	}
+	if policy == "" {
+		policy = ImportSkip
+	}
+	if policy != ImportSkip && policy != ImportUpdate {
+		return nil, errInvalidPolicy
+	}
+	return &Importer{db: db, userID: userID, policy: policy}, nil
+}
+
+// Add validates the next row and queues it, writing the queued rows once a chunk is full.
+// Invalid rows are counted as failed. The error is only set when a chunk couldn't be written.
+func (im *Importer) Add(c Contact) error {
+	return im.AddAt(im.rows+1, c)
+}
+
+// AddAt is Add for callers numbering the rows themselves, such as by line in a file.
+func (im *Importer) AddAt(row int, c Contact) error {
+	return im.AddInOrganization(row, c, "")
+}
+
+// AddInOrganization is AddAt for a row naming its organization. The organization is resolved
+// when the row is written, within the row's transaction, so rows that fail or are skipped
+// leave no organization behind.
+func (im *Importer) AddInOrganization(row int, c Contact, organization string) error {
+	im.rows = row
+	c.UserID = im.userID
+	if err := c.validate(); err != nil {
+		im.fail(row, err)
+		return nil
+	}
+	im.pending = append(im.pending, pendingRow{row: row, contact: c, organization: organization})
+	if len(im.pending) >= ImportChunkSize {
+		return im.Flush()
+	}
+	return nil
+}
+
+// Flush writes the queued rows in a single transaction. Rows that fail are rolled back on their own.
+func (im *Importer) Flush() error {
+	if len(im.pending) == 0 {
+		return nil
+	}
+	outcomes := make([]outcome, len(im.pending))
+	results, err := im.db.Batch(len(im.pending), false, func(tx *DB, i int) error {
+		o, err := im.write(tx, &im.pending[i])
+		outcomes[i] = o
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, err := range results {
+		p := &im.pending[i]
+		if err == nil && outcomes[i] != skipped && im.OnWrite != nil {
+			err = im.OnWrite(p.row, &p.contact)
+		}
+		switch {
+		case err != nil:
+			im.fail(p.row, err)
+		case outcomes[i] == skipped:
+			im.summary.Skipped++
+		case outcomes[i] == created:
+			im.summary.Created++
+		default:
+			im.summary.Updated++
+		}
+	}
+	im.pending = im.pending[:0]
+	return nil
+}
+
+// Pending returns the number of rows waiting to be written.
+func (im *Importer) Pending() int {
+	return len(im.pending)
+}
+
+// Close writes the remaining rows and returns the summary of the import.
+func (im *Importer) Close() (*ImportSummary, error) {
+	if err := im.Flush(); err != nil {
+		return nil, err
+	}
+	return &im.summary, nil
+}
+
+// write creates or updates the row, leaving the written contact in it.
+func (im *Importer) write(tx *DB, p *pendingRow) (outcome, error) {
+	c := &p.contact
+	var existing Contact
+	res := tx.Conn.Where("user_id = ? AND email = ?", c.UserID, c.Email).Limit(1).Find(&existing)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		if err := tx.ResolveOrganization(c, p.organization); err != nil {
+			return created, err
+		}
+		written, err := tx.Create(*c)
+		if err != nil {
+			return created, err
+		}
+		*c = *written
+		return created, nil
+	}
+	if im.policy == ImportSkip {
+		return skipped, nil
+	}
+	if err := tx.ResolveOrganization(c, p.organization); err != nil {
+		return updated, err
+	}
+	mergeNonEmpty(&existing, c)
+	if err := tx.Update(&existing); err != nil {
+		return updated, err
+	}
+	*c = existing
+	return updated, nil
+}
+
+// Fail counts a row the caller couldn't read as failed.
+func (im *Importer) Fail(row int, err error) {
+	im.rows = row
+	im.fail(row, err)
+}
+
+func (im *Importer) fail(row int, err error) {
+	im.summary.Failed++
+	if len(im.summary.Errors) < MaxImportErrors {
+		im.summary.Errors = append(im.summary.Errors, ImportError{Row: row, Err: err})
+	}
+}
+
+// mergeNonEmpty copies the non-empty editable fields of src to dst.
+func mergeNonEmpty(dst, src *Contact) {
+	for _, f := range []struct{ dst, src *string }{
+		{&dst.Fullname, &src.Fullname},
+		{&dst.Phone, &src.Phone},
+		{&dst.Address, &src.Address},
+		{&dst.JobTitle, &src.JobTitle},
+		{&dst.Department, &src.Department},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	if src.OrganizationID != nil {
+		dst.OrganizationID = src.OrganizationID
+	}
+}
@@ -0,0 +1,102 @@
+package contact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImporter(t *testing.T) {
+	userID := uint(11)
+	existing, err := db.Create(Contact{UserID: userID, Fullname: "Alugbin Abiodun", Email: "contact0@gmail.com", Phone: "+2347033304280", Address: "Ibadan", JobTitle: "Engineer"})
+	require.NoError(t, err)
+
+	rows := batchContacts(userID, ImportChunkSize+2)
+	rows[0].Phone = "08155040074"
+	rows[0].JobTitle = ""
+	rows[5].Email = ""
+	rows[ImportChunkSize+1].Email = rows[1].Email
+
+	_, err = db.NewImporter(userID, "replace")
+	assert.Equal(t, errInvalidPolicy, err)
+
+	im, err := db.NewImporter(userID, ImportUpdate)
+	require.NoError(t, err)
+	written := map[int]uint{}
+	im.OnWrite = func(row int, c *Contact) error {
+		written[row] = c.ID
+		if row == 3 {
+			return errEmptyName
+		}
+		return nil
+	}
+	for _, c := range rows {
+		require.NoError(t, im.Add(c))
+	}
+	summary, err := im.Close()
+	require.NoError(t, err)
+	assert.Equal(t, ImportChunkSize-2, summary.Created)
+	assert.Equal(t, 2, summary.Updated)
+	assert.Equal(t, 0, summary.Skipped)
+	assert.Equal(t, 2, summary.Failed)
+	assert.Equal(t, []ImportError{{Row: 6, Err: errEmptyEmail}, {Row: 3, Err: errEmptyName}}, summary.Errors)
+	assert.Len(t, written, ImportChunkSize+1)
+	assert.Equal(t, existing.ID, written[1])
+	assert.NotZero(t, written[3])
+
+	// the empty job title of the row doesn't wipe the existing one
+	found, err := db.FindByID(userID, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "08155040074", found.Phone)
+	assert.Equal(t, "Engineer", found.JobTitle)
+
+	im, err = db.NewImporter(userID, "")
+	require.NoError(t, err)
+	require.NoError(t, im.Add(rows[0]))
+	im.Fail(3, errEmptyName)
+	require.NoError(t, im.AddAt(5, Contact{Fullname: "Jane Doe"}))
+	require.NoError(t, im.Add(rows[1]))
+	summary, err = im.Close()
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Skipped)
+	assert.Equal(t, []ImportError{{Row: 3, Err: errEmptyName}, {Row: 5, Err: errEmptyEmail}}, summary.Errors)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
+func TestImporterOrganizations(t *testing.T) {
+	userID := uint(12)
+	rows := batchContacts(userID, 4)
+	_, err := db.Create(rows[2])
+	require.NoError(t, err)
+	rows[3].Email = ""
+
+	im, err := db.NewImporter(userID, ImportSkip)
+	require.NoError(t, err)
+	require.NoError(t, im.AddInOrganization(1, rows[0], "Acme"))
+	require.NoError(t, im.AddInOrganization(2, rows[1], "ACME"))
+	// neither the skipped duplicate nor the invalid row leaves its organization behind
+	require.NoError(t, im.AddInOrganization(3, rows[2], "Globex"))
+	require.NoError(t, im.AddInOrganization(4, rows[3], "Initech"))
+	summary, err := im.Close()
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Created)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, 1, summary.Failed)
+
+	orgs, err := db.organizations().FindByUserID(userID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "Acme", orgs[0].Name)
+	members, err := db.FindByOrganization(userID, orgs[0].ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	t.Cleanup(func() {
+		require.Nil(t, db.Conn.Exec("DELETE FROM organizations").Error)
+		require.Nil(t, cleanup())
+	})
+}
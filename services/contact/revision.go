@@ -0,0 +1,301 @@
+package contact
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"grpc-contact-manager/services/changelog"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ActionCreate marks the revision written when a contact is created.
+	ActionCreate = "create"
+	// ActionUpdate marks the revision written when a contact is updated.
+	ActionUpdate = "update"
+	// ActionDelete marks the revision written when a contact is deleted.
+	ActionDelete = "delete"
+	// ActionRevert marks the revision written when a contact is reverted to an earlier revision.
+	ActionRevert = "revert"
+	// ActionMerge marks the revision written when other contacts are merged into a contact.
+	ActionMerge = "merge"
+	// ActionUnmerge marks the revision written when a merge is undone.
+	ActionUnmerge = "unmerge"
+)
+
+var (
+	errRevisionImmutable = errors.New("revisions can't be changed")
+
+	// ErrRevisionNotFound is returned when a revision doesn't exist or belongs to another user.
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrNoSnapshot is returned when reverting to a revision recorded before snapshots were kept.
+	ErrNoSnapshot = errors.New("revision has no snapshot to revert to")
+)
+
+// Revision records a change made to a contact. Revisions are never updated or deleted, and
+// outlive the contact when it is soft deleted.
+type Revision struct {
+	ID        uint   `json:"id" gorm:"primarykey"`
+	ContactID uint   `json:"contact_id" gorm:"column:contact_id;index"`
+	UserID    uint   `json:"user_id" gorm:"column:user_id;index"`
+	Action    string `json:"action"`
+	Fields    string `json:"fields"`
+	// Version is the version of the contact after the change.
+	Version uint `json:"version"`
+	// Actor is who made the change, see UserActor.
+	Actor string `json:"actor"`
+	// Changes is the JSON encoded list of field changes.
+	Changes string `json:"-"`
+	// Snapshot is the JSON encoded state of the contact after the change.
+	Snapshot  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Change is the change of a single field in a revision.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Snapshot holds the user editable fields of a contact.
+type Snapshot struct {
+	Fullname       string `json:"full_name"`
+	Email          string `json:"email"`
+	Phone          string `json:"phone"`
+	Address        string `json:"address"`
+	OrganizationID *uint  `json:"organization_id"`
+	JobTitle       string `json:"job_title"`
+	Department     string `json:"department"`
+}
+
+// UserActor is the actor recorded for changes made by a user.
+func UserActor(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// BeforeUpdate keeps revisions immutable.
+func (r *Revision) BeforeUpdate(tx *gorm.DB) error {
+	return errRevisionImmutable
+}
+
+// BeforeDelete keeps revisions immutable.
+func (r *Revision) BeforeDelete(tx *gorm.DB) error {
+	return errRevisionImmutable
+}
+
+// FieldList returns the names of the fields changed by the revision.
+func (r *Revision) FieldList() []string {
+	if r.Fields == "" {
+		return nil
+	}
+	return strings.Split(r.Fields, ",")
+}
+
+// ChangeList returns the field changes of the revision.
+func (r *Revision) ChangeList() ([]Change, error) {
+	if r.Changes == "" {
+		return nil, nil
+	}
+	var changes []Change
+	err := json.Unmarshal([]byte(r.Changes), &changes)
+	return changes, err
+}
+
+// State returns the state of the contact right after the revision.
+func (r *Revision) State() (*Snapshot, error) {
+	if r.Snapshot == "" {
+		return nil, ErrNoSnapshot
+	}
+	var s Snapshot
+	if err := json.Unmarshal([]byte(r.Snapshot), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Revisions returns the revisions of the contact, newest first. They are returned for deleted contacts too.
+func (db *DB) Revisions(userID, contactID uint) ([]Revision, error) {
+	var revisions []Revision
+	res := db.Conn.Where("user_id = ? AND contact_id = ?", userID, contactID).Order("id DESC").Find(&revisions)
+	return revisions, res.Error
+}
+
+// FindRevision returns a revision of one of the user's contacts.
+func (db *DB) FindRevision(userID, id uint) (*Revision, error) {
+	var r Revision
+	err := db.Conn.Where("user_id = ?", userID).First(&r, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Revert puts the contact back in the state it had right after the given revision, and restores
+// it if it was deleted since. The revert is recorded as a new revision.
+func (db *DB) Revert(userID, revisionID uint) (*Contact, error) {
+	r, err := db.FindRevision(userID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := r.State()
+	if err != nil {
+		return nil, err
+	}
+	var contact Contact
+	err = db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).First(&contact, r.ContactID).Error; err != nil {
+			return err
+		}
+		old := contact
+		state.apply(&contact)
+		if contact.OrganizationID != nil {
+			if err := db.checkOrganization(&contact); err != nil {
+				if !errors.Is(err, errInvalidOrg) {
+					return err
+				}
+				// the organization was deleted since
+				contact.OrganizationID = nil
+			}
+		}
+		contact.DeletedAt = gorm.DeletedAt{}
+		contact.Version++
+		if err := tx.Unscoped().Save(&contact).Error; err != nil {
+			return err
+		}
+		// reverting a deleted contact is recorded even when no field changed
+		return db.record(tx, &old, &contact, ActionRevert, old.DeletedAt.Valid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.Publish(userID)
+	return &contact, nil
+}
+
+// RecordRevision records the change from old to new made by the given action within the transaction.
+// Nothing is recorded when no field changed, unless the contact was deleted or restored.
+func RecordRevision(tx *gorm.DB, old, new *Contact, action string) error {
+	force := action == ActionDelete || old.DeletedAt.Valid && !new.DeletedAt.Valid
+	return (&DB{Conn: tx}).record(tx, old, new, action, force)
+}
+
+// record writes the revision for the change from old to new, unless nothing changed and force is false.
+// The change is also appended to the user's change log.
+func (db *DB) record(tx *gorm.DB, old, new *Contact, action string, force bool) error {
+	changes := diff(old, new)
+	if len(changes) == 0 && !force {
+		return nil
+	}
+	if err := changelog.Record(tx, new.UserID, changeAction(old, action), new.ID); err != nil {
+		return err
+	}
+	r := Revision{
+		ContactID: new.ID,
+		UserID:    new.UserID,
+		Action:    action,
+		Version:   new.Version,
+		Actor:     db.actor(new),
+	}
+	if len(changes) > 0 {
+		fields := make([]string, len(changes))
+		for i, c := range changes {
+			fields[i] = c.Field
+		}
+		r.Fields = strings.Join(fields, ",")
+		encoded, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		r.Changes = string(encoded)
+	}
+	snapshot, err := json.Marshal(snapshotOf(new))
+	if err != nil {
+		return err
+	}
+	r.Snapshot = string(snapshot)
+	return tx.Create(&r).Error
+}
+
+// changeAction is the change log action of a revision. Restoring a deleted contact creates it
+// again for the clients that synced the delete.
+func changeAction(old *Contact, action string) string {
+	switch {
+	case action == ActionDelete:
+		return changelog.Delete
+	case action == ActionCreate || old.DeletedAt.Valid:
+		return changelog.Create
+	default:
+		return changelog.Update
+	}
+}
+
+func (db *DB) actor(c *Contact) string {
+	if db.Actor != "" {
+		return db.Actor
+	}
+	return UserActor(c.UserID)
+}
+
+func snapshotOf(c *Contact) Snapshot {
+	return Snapshot{
+		Fullname:       c.Fullname,
+		Email:          c.Email,
+		Phone:          c.Phone,
+		Address:        c.Address,
+		OrganizationID: c.OrganizationID,
+		JobTitle:       c.JobTitle,
+		Department:     c.Department,
+	}
+}
+
+func (s *Snapshot) apply(c *Contact) {
+	c.Fullname = s.Fullname
+	c.Email = s.Email
+	c.Phone = s.Phone
+	c.Address = s.Address
+	c.OrganizationID = s.OrganizationID
+	c.JobTitle = s.JobTitle
+	c.Department = s.Department
+}
+
+// changedFields lists the user editable fields that differ between the two contacts.
+func changedFields(old, new *Contact) []string {
+	var fields []string
+	for _, c := range diff(old, new) {
+		fields = append(fields, c.Field)
+	}
+	return fields
+}
+
+// diff returns the changes of the user editable fields between the two contacts.
+func diff(old, new *Contact) []Change {
+	var changes []Change
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{Field: field, Old: o, New: n})
+		}
+	}
+	add("full_name", old.Fullname, new.Fullname)
+	add("email", old.Email, new.Email)
+	add("phone", old.Phone, new.Phone)
+	add("address", old.Address, new.Address)
+	add("organization_id", formatID(old.OrganizationID), formatID(new.OrganizationID))
+	add("job_title", old.JobTitle, new.JobTitle)
+	add("department", old.Department, new.Department)
+	return changes
+}
+
+func formatID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
@@ -0,0 +1,114 @@
+package contact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedFields(t *testing.T) {
+	old := Contact{Fullname: "Alugbin Abiodun", Email: "tolaabbey009@gmail.com", Phone: "+2347033304280", Address: "Ibadan"}
+	table := []struct {
+		name   string
+		update func(c *Contact)
+		want   []string
+	}{
+		{name: "Nothing", update: func(c *Contact) {}, want: nil},
+		{name: "Name", update: func(c *Contact) { c.Fullname = "Updated" }, want: []string{"full_name"}},
+		{name: "Phone and Address", update: func(c *Contact) { c.Phone, c.Address = "0815", "Lagos" }, want: []string{"phone", "address"}},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			c := old
+			tt.update(&c)
+			assert.Equal(t, tt.want, changedFields(&old, &c))
+		})
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	userID := uint(1)
+	createForSearch(t, userID)
+	c, err := db.FindByID(userID, 1)
+	require.NoError(t, err)
+
+	c.Phone = "08155040074"
+	require.NoError(t, db.Update(c))
+	// saving without changes doesn't add a revision
+	require.NoError(t, db.Update(c))
+
+	revisions, err := db.Revisions(userID, c.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, ActionUpdate, revisions[0].Action)
+	assert.Equal(t, []string{"phone"}, revisions[0].FieldList())
+	assert.Equal(t, ActionCreate, revisions[1].Action)
+	assert.Empty(t, revisions[1].FieldList())
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
+func TestRevert(t *testing.T) {
+	userID := uint(7)
+	c, err := db.Create(Contact{UserID: userID, Fullname: "Alugbin Abiodun", Email: "tolaabbey009@gmail.com", Phone: "+2347033304280", Address: "Ibadan"})
+	require.NoError(t, err)
+	c.Phone, c.Address = "08155040074", "Lagos"
+	require.NoError(t, db.As("sync:carddav").Update(c))
+
+	revisions, err := db.Revisions(userID, c.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "sync:carddav", revisions[0].Actor)
+	assert.Equal(t, UserActor(userID), revisions[1].Actor)
+	changes, err := revisions[0].ChangeList()
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Field: "phone", Old: "+2347033304280", New: "08155040074"}, {Field: "address", Old: "Ibadan", New: "Lagos"}}, changes)
+
+	_, err = db.Delete(userID, c.ID)
+	require.NoError(t, err)
+	_, err = db.FindByID(userID, c.ID)
+	assert.Error(t, err)
+
+	// the history survives the delete and reverting restores the contact
+	reverted, err := db.Revert(userID, revisions[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "+2347033304280", reverted.Phone)
+	assert.Equal(t, "Ibadan", reverted.Address)
+	found, err := db.FindByID(userID, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ibadan", found.Address)
+
+	revisions, err = db.Revisions(userID, c.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+	assert.Equal(t, ActionRevert, revisions[0].Action)
+	assert.Equal(t, []string{"phone", "address"}, revisions[0].FieldList())
+	assert.Equal(t, ActionDelete, revisions[1].Action)
+
+	_, err = db.Revert(userID+1, revisions[0].ID)
+	assert.Equal(t, ErrRevisionNotFound, err)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
+func TestRevisionImmutable(t *testing.T) {
+	c, err := db.Create(Contact{UserID: 8, Fullname: "Alugbin Abiodun", Email: "tolaabbey009@gmail.com", Phone: "+2347033304280", Address: "Ibadan"})
+	require.NoError(t, err)
+	revisions, err := db.Revisions(8, c.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+
+	r := revisions[0]
+	r.Action = ActionUpdate
+	assert.Equal(t, errRevisionImmutable, db.Conn.Save(&r).Error)
+	assert.Equal(t, errRevisionImmutable, db.Conn.Delete(&r).Error)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
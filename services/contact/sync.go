@@ -0,0 +1,127 @@
+package contact
+
+import (
+	"grpc-contact-manager/services/changelog"
+)
+
+// ChangeSet is what changed in the user's contacts up to Seq, the change sequence number to sync from next.
+type ChangeSet struct {
+	Created []Contact
+	Updated []Contact
+	// Deleted holds the deleted contacts the client may have seen.
+	Deleted []Contact
+	Seq     uint64
+}
+
+// Event is a change to a contact, given in the contact's current state. Seq is the number of the
+// contact's latest change, so clients resuming from it miss nothing.
+type Event struct {
+	Action  string
+	Contact Contact
+	Seq     uint64
+}
+
+// EventPage is a page of events read from the change log.
+type EventPage struct {
+	Events []Event
+	// Seq is the change sequence number to read the next page from.
+	Seq uint64
+	// More is set when the page was cut short, so the next one is due right away.
+	More bool
+}
+
+// FullSync returns all the user's contacts as created, with the sequence number to sync from next.
+func (db *DB) FullSync(userID uint) (*ChangeSet, error) {
+	// the number is read first so changes made meanwhile are sent again rather than missed
+	seq, err := db.LatestSeq(userID)
+	if err != nil {
+		return nil, err
+	}
+	var contacts []Contact
+	if err := db.Conn.Where("user_id = ?", userID).Order("id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return &ChangeSet{Created: contacts, Seq: seq}, nil
+}
+
+// Changes returns the user's contacts created, updated and deleted after the change sequence number
+// since. changelog.ErrResyncRequired is returned when those changes are no longer known.
+func (db *DB) Changes(userID uint, since uint64) (*ChangeSet, error) {
+	page, err := db.Events(userID, since, 0)
+	if err != nil {
+		return nil, err
+	}
+	cs := &ChangeSet{Seq: page.Seq}
+	for _, e := range page.Events {
+		switch e.Action {
+		case changelog.Create:
+			cs.Created = append(cs.Created, e.Contact)
+		case changelog.Update:
+			cs.Updated = append(cs.Updated, e.Contact)
+		default:
+			cs.Deleted = append(cs.Deleted, e.Contact)
+		}
+	}
+	return cs, nil
+}
+
+// Events returns the changes to the user's contacts after the change sequence number since, one
+// event per contact in the order of their latest change. At most limit changes are read from the
+// log, all of them when it is 0. A contact created and deleted within the page isn't reported.
+func (db *DB) Events(userID uint, since uint64, limit int) (*EventPage, error) {
+	entries, seq, err := db.changes().Since(userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	page := &EventPage{Seq: seq, More: limit > 0 && len(entries) == limit}
+	if len(entries) == 0 {
+		return page, nil
+	}
+	latest := map[uint]uint64{}
+	created := map[uint]bool{}
+	var ids []uint
+	for _, e := range entries {
+		if _, seen := latest[e.ContactID]; !seen {
+			ids = append(ids, e.ContactID)
+		}
+		latest[e.ContactID] = e.Seq
+		created[e.ContactID] = created[e.ContactID] || e.Action == changelog.Create
+	}
+	var contacts []Contact
+	if err := db.Conn.Unscoped().Where("user_id = ? AND id IN ?", userID, ids).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Contact, len(contacts))
+	for i := range contacts {
+		byID[contacts[i].ID] = &contacts[i]
+	}
+	for _, e := range entries {
+		c, ok := byID[e.ContactID]
+		if !ok || latest[e.ContactID] != e.Seq {
+			continue
+		}
+		event := Event{Contact: *c, Seq: e.Seq}
+		switch {
+		case c.DeletedAt.Valid && created[c.ID]:
+			// created and deleted since, so the client never had it
+			continue
+		case c.DeletedAt.Valid:
+			event.Action = changelog.Delete
+		case created[c.ID]:
+			event.Action = changelog.Create
+		default:
+			event.Action = changelog.Update
+		}
+		page.Events = append(page.Events, event)
+	}
+	return page, nil
+}
+
+// LatestSeq returns the change sequence number of the latest change to the user's contacts.
+func (db *DB) LatestSeq(userID uint) (uint64, error) {
+	return db.changes().Current(userID)
+}
+
+func (db *DB) changes() *changelog.DB {
+	return &changelog.DB{Conn: db.Conn}
+}
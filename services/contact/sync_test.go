@@ -0,0 +1,120 @@
+package contact
+
+import (
+	"fmt"
+	"testing"
+
+	"grpc-contact-manager/services/changefeed"
+	"grpc-contact-manager/services/changelog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	userID := uint(14)
+	contacts := batchContacts(userID, 3)
+	kept, err := db.Create(contacts[0])
+	require.NoError(t, err)
+	edited, err := db.Create(contacts[1])
+	require.NoError(t, err)
+
+	full, err := db.FullSync(userID)
+	require.NoError(t, err)
+	require.Len(t, full.Created, 2)
+	assert.Equal(t, uint64(2), full.Seq)
+
+	edited.Phone = "08155040074"
+	require.NoError(t, db.Update(edited))
+	_, err = db.Delete(userID, kept.ID)
+	require.NoError(t, err)
+	added, err := db.Create(contacts[2])
+	require.NoError(t, err)
+	// created and deleted between syncs, so it isn't reported
+	gone, err := db.Create(Contact{UserID: userID, Fullname: "Gone", Email: "gone@example.com", Phone: "1", Address: "Ibadan"})
+	require.NoError(t, err)
+	_, err = db.Delete(userID, gone.ID)
+	require.NoError(t, err)
+
+	cs, err := db.Changes(userID, full.Seq)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), cs.Seq)
+	require.Len(t, cs.Created, 1)
+	assert.Equal(t, added.ID, cs.Created[0].ID)
+	require.Len(t, cs.Updated, 1)
+	assert.Equal(t, "08155040074", cs.Updated[0].Phone)
+	require.Len(t, cs.Deleted, 1)
+	assert.Equal(t, kept.ID, cs.Deleted[0].ID)
+
+	// a restored contact is created again for clients that synced its delete
+	revisions, err := db.Revisions(userID, kept.ID)
+	require.NoError(t, err)
+	_, err = db.Revert(userID, revisions[len(revisions)-1].ID)
+	require.NoError(t, err)
+	cs, err = db.Changes(userID, cs.Seq)
+	require.NoError(t, err)
+	require.Len(t, cs.Created, 1)
+	assert.Equal(t, kept.ID, cs.Created[0].ID)
+
+	cs, err = db.Changes(userID, cs.Seq)
+	require.NoError(t, err)
+	assert.Empty(t, cs.Created)
+	assert.Empty(t, cs.Updated)
+	assert.Empty(t, cs.Deleted)
+
+	_, err = db.Changes(userID, cs.Seq+1)
+	assert.Equal(t, changelog.ErrResyncRequired, err)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
+
+func TestEvents(t *testing.T) {
+	userID := uint(15)
+	feed := changefeed.NewMemory()
+	sub := feed.Subscribe(changefeed.Event{Topic: changefeed.Contacts, UserID: userID})
+	defer sub.Close()
+	contacts := &DB{Conn: db.Conn, Feed: feed}
+
+	first, err := contacts.Create(batchContacts(userID, 1)[0])
+	require.NoError(t, err)
+	assert.Len(t, sub.C, 1)
+	<-sub.C
+	results, err := contacts.Batch(2, true, func(tx *DB, i int) error {
+		_, err := tx.Create(Contact{UserID: userID, Fullname: "Batch", Email: fmt.Sprintf("batch%d@example.com", i), Phone: "1", Address: "Ibadan"})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, nil}, results)
+	assert.Len(t, sub.C, 1)
+	first.Phone = "08155040074"
+	require.NoError(t, contacts.Update(first))
+
+	// the first contact is reported at its latest change, after the batch
+	page, err := contacts.Events(userID, 0, 3)
+	require.NoError(t, err)
+	assert.True(t, page.More)
+	assert.Equal(t, uint64(3), page.Seq)
+	require.Len(t, page.Events, 3)
+	assert.Equal(t, changelog.Create, page.Events[0].Action)
+	assert.Equal(t, first.ID, page.Events[0].Contact.ID)
+
+	page, err = contacts.Events(userID, page.Seq, 3)
+	require.NoError(t, err)
+	assert.False(t, page.More)
+	assert.Equal(t, uint64(4), page.Seq)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, changelog.Update, page.Events[0].Action)
+	assert.Equal(t, uint64(4), page.Events[0].Seq)
+
+	page, err = contacts.Events(userID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Events, 3)
+	assert.Equal(t, first.ID, page.Events[2].Contact.ID)
+	assert.Equal(t, changelog.Create, page.Events[2].Action)
+
+	t.Cleanup(func() {
+		require.Nil(t, cleanup())
+	})
+}
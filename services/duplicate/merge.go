@@ -0,0 +1,457 @@
+package duplicate
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+
+	"gorm.io/gorm"
+)
+
+// Mergeable contact fields, named like the update mask paths of contacts.
+const (
+	FieldName         = "name"
+	FieldEmail        = "email"
+	FieldPhone        = "phone"
+	FieldAddress      = "address"
+	FieldOrganization = "organizationID"
+	FieldJobTitle     = "jobTitle"
+	FieldDepartment   = "department"
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+	errNothingToMerge     = errors.New("at least one contact must be merged into the survivor")
+	errMergeIntoSelf      = errors.New("a contact can't be merged into itself")
+	errUnknownField       = errors.New("unknown merge field")
+	errInvalidSource      = errors.New("field source must be one of the merged contacts")
+
+	// ErrNotFound is returned when a contact or merge doesn't exist or belongs to another user.
+	ErrNotFound = errors.New("contact not found")
+	// ErrAlreadyUndone is returned when undoing a merge twice.
+	ErrAlreadyUndone = errors.New("merge already undone")
+	// ErrChangedSinceMerge is returned when undoing a merge would lose changes made to the
+	// contacts since.
+	ErrChangedSinceMerge = errors.New("contacts changed since the merge")
+
+	fields = []string{FieldName, FieldEmail, FieldPhone, FieldAddress, FieldOrganization, FieldJobTitle, FieldDepartment}
+
+	// columns are the columns of the survivor written by a merge and its undo.
+	columns = []string{"full_name", "email", "phone", "address", "organization_id", "job_title", "department", "version"}
+
+	// references are the columns of other tables pointing at contacts. Their rows move to the
+	// survivor of a merge. Photos stay with their contact because their blobs are keyed by it.
+	references = []reference{
+		{Table: "notes", Column: "contact_id"},
+		{Table: "interactions", Column: "contact_id"},
+		{Table: "attachments", Column: "contact_id"},
+		{Table: "reminders", Column: "contact_id"},
+		{Table: "relationships", Column: "contact_id"},
+		{Table: "relationships", Column: "related_id"},
+	}
+)
+
+type reference struct {
+	Table  string
+	Column string
+}
+
+// Move records that a row of a referencing table was pointed from one contact to another.
+type Move struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	RowID  uint   `json:"row_id"`
+	From   uint   `json:"from"`
+}
+
+// Merge records a merge of contacts so it can be undone.
+type Merge struct {
+	gorm.Model
+	UserID     uint   `json:"user_id" gorm:"column:user_id;index"`
+	SurvivorID uint   `json:"survivor_id" gorm:"column:survivor_id;index"`
+	MergedIDs  string `json:"merged_ids"`
+	// Version is the survivor's version right after the merge.
+	Version uint `json:"version"`
+	// Before is the JSON encoded survivor as it was before the merge.
+	Before string `json:"-"`
+	// Moves is the JSON encoded list of rows moved to the survivor.
+	Moves string `json:"-"`
+	// Dropped lists the relationships deleted because they linked merged contacts to each other.
+	Dropped  string     `json:"-"`
+	UndoneAt *time.Time `json:"undone_at"`
+}
+
+// Merged returns the IDs of the contacts merged into the survivor.
+func (m *Merge) Merged() []uint {
+	var ids []uint
+	for _, s := range strings.Split(m.MergedIDs, ",") {
+		if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// Request describes a merge. Fields lists, per field, which contact the value is taken from.
+// Fields without an entry keep the survivor's value, or the first non-empty merged value
+// when the survivor has none.
+type Request struct {
+	UserID     uint
+	SurvivorID uint
+	MergedIDs  []uint
+	Fields     map[string]uint
+}
+
+// DB - duplicate detection and merge repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the duplicate repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the merges table
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Merge{})
+}
+
+// FindDuplicates scores the user's contacts against each other and returns the likely duplicates.
+func (d *DB) FindDuplicates(userID uint, minScore float64) ([]Pair, error) {
+	var contacts []contact.Contact
+	if err := d.Conn.Where("user_id = ?", userID).Order("id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	return Find(contacts, minScore), nil
+}
+
+// Merge combines the merged contacts into the survivor, moves their notes, interactions,
+// attachments, reminders and relationships over and deletes them. The merge is recorded so
+// Undo can restore the contacts as they were.
+func (d *DB) Merge(req Request) (*contact.Contact, *Merge, error) {
+	if err := req.validate(); err != nil {
+		return nil, nil, err
+	}
+	var (
+		survivor contact.Contact
+		record   Merge
+	)
+	err := d.Conn.Transaction(func(tx *gorm.DB) error {
+		ids := append([]uint{req.SurvivorID}, req.MergedIDs...)
+		var contacts []contact.Contact
+		if err := tx.Where("user_id = ? AND id IN ?", req.UserID, ids).Find(&contacts).Error; err != nil {
+			return err
+		}
+		if len(contacts) != len(ids) {
+			return ErrNotFound
+		}
+		byID := make(map[uint]*contact.Contact, len(contacts))
+		for i := range contacts {
+			byID[contacts[i].ID] = &contacts[i]
+		}
+		survivor = *byID[req.SurvivorID]
+		before, err := json.Marshal(survivor)
+		if err != nil {
+			return err
+		}
+
+		merged := make([]*contact.Contact, len(req.MergedIDs))
+		for i, id := range req.MergedIDs {
+			merged[i] = byID[id]
+		}
+		old := survivor
+		if err := combine(&survivor, merged, byID, req.Fields); err != nil {
+			return err
+		}
+
+		dropped, err := dropLinksBetween(tx, req.UserID, ids)
+		if err != nil {
+			return err
+		}
+		moves, err := moveReferences(tx, req.UserID, req.MergedIDs, req.SurvivorID)
+		if err != nil {
+			return err
+		}
+
+		// deleted like contact.DB.Delete, so their history and the change log show it
+		for _, m := range merged {
+			if err := tx.Delete(m).Error; err != nil {
+				return err
+			}
+			if err := contact.RecordRevision(tx, m, m, contact.ActionDelete); err != nil {
+				return err
+			}
+		}
+		survivor.Version++
+		if err := tx.Select(columns).Save(&survivor).Error; err != nil {
+			return err
+		}
+		if err := contact.RecordRevision(tx, &old, &survivor, contact.ActionMerge); err != nil {
+			return err
+		}
+
+		movesJSON, err := json.Marshal(moves)
+		if err != nil {
+			return err
+		}
+		droppedJSON, err := json.Marshal(dropped)
+		if err != nil {
+			return err
+		}
+		record = Merge{
+			UserID:     req.UserID,
+			SurvivorID: req.SurvivorID,
+			MergedIDs:  joinIDs(req.MergedIDs),
+			Version:    survivor.Version,
+			Before:     string(before),
+			Moves:      string(movesJSON),
+			Dropped:    string(droppedJSON),
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &survivor, &record, nil
+}
+
+// Undo reverts a merge: the survivor gets its old values back, the merged contacts are
+// restored and the moved rows still on the survivor are pointed back at them. The merge can't
+// be undone once the survivor was changed or a merged contact restored, as those changes
+// would be lost.
+func (d *DB) Undo(userID, mergeID uint) ([]contact.Contact, error) {
+	var restored []contact.Contact
+	err := d.Conn.Transaction(func(tx *gorm.DB) error {
+		var m Merge
+		err := tx.Where("user_id = ?", userID).First(&m, mergeID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if m.UndoneAt != nil {
+			return ErrAlreadyUndone
+		}
+
+		var before contact.Contact
+		if err := json.Unmarshal([]byte(m.Before), &before); err != nil {
+			return err
+		}
+		var current contact.Contact
+		err = tx.First(&current, m.SurvivorID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// the survivor was deleted since
+			return ErrChangedSinceMerge
+		}
+		if err != nil {
+			return err
+		}
+		merged := m.Merged()
+		var deleted []contact.Contact
+		if err := tx.Unscoped().Where("user_id = ? AND id IN ? AND deleted_at IS NOT NULL", userID, merged).Find(&deleted).Error; err != nil {
+			return err
+		}
+		if current.Version != m.Version || len(deleted) != len(merged) {
+			return ErrChangedSinceMerge
+		}
+		before.Version = current.Version + 1
+		if err := tx.Select(columns).Save(&before).Error; err != nil {
+			return err
+		}
+		if err := contact.RecordRevision(tx, &current, &before, contact.ActionUnmerge); err != nil {
+			return err
+		}
+
+		for i := range deleted {
+			old := deleted[i]
+			c := &deleted[i]
+			c.DeletedAt = gorm.DeletedAt{}
+			c.Version++
+			err := tx.Unscoped().Model(c).UpdateColumns(map[string]interface{}{"deleted_at": nil, "version": c.Version}).Error
+			if err != nil {
+				return err
+			}
+			if err := contact.RecordRevision(tx, &old, c, contact.ActionUnmerge); err != nil {
+				return err
+			}
+		}
+		var moves []Move
+		if err := json.Unmarshal([]byte(m.Moves), &moves); err != nil {
+			return err
+		}
+		for _, mv := range moves {
+			// rows moved elsewhere since stay where they are
+			err := tx.Table(mv.Table).Where("id = ? AND "+mv.Column+" = ?", mv.RowID, m.SurvivorID).Update(mv.Column, mv.From).Error
+			if err != nil {
+				return err
+			}
+		}
+		var dropped []uint
+		if err := json.Unmarshal([]byte(m.Dropped), &dropped); err != nil {
+			return err
+		}
+		if len(dropped) > 0 {
+			if err := tx.Unscoped().Table("relationships").Where("id IN ?", dropped).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		m.UndoneAt = &now
+		if err := tx.Save(&m).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", append([]uint{m.SurvivorID}, merged...)).Order("id").Find(&restored).Error
+	})
+	return restored, err
+}
+
+// combine fills the survivor's fields from the merged contacts.
+func combine(survivor *contact.Contact, merged []*contact.Contact, byID map[uint]*contact.Contact, sources map[string]uint) error {
+	for field, source := range sources {
+		if !knownField(field) {
+			return errUnknownField
+		}
+		from, ok := byID[source]
+		if !ok {
+			return errInvalidSource
+		}
+		copyField(survivor, from, field)
+	}
+	for _, field := range fields {
+		if _, chosen := sources[field]; chosen || !isEmpty(survivor, field) {
+			continue
+		}
+		for _, m := range merged {
+			if !isEmpty(m, field) {
+				copyField(survivor, m, field)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// dropLinksBetween deletes the relationships between contacts that are merged into one,
+// which would otherwise link the survivor to itself, and returns their IDs.
+func dropLinksBetween(tx *gorm.DB, userID uint, ids []uint) ([]uint, error) {
+	var dropped []uint
+	err := tx.Table("relationships").
+		Where("user_id = ? AND contact_id IN ? AND related_id IN ? AND deleted_at IS NULL", userID, ids, ids).
+		Pluck("id", &dropped).Error
+	if err != nil || len(dropped) == 0 {
+		return dropped, err
+	}
+	return dropped, tx.Table("relationships").Where("id IN ?", dropped).Update("deleted_at", time.Now()).Error
+}
+
+// moveReferences points the rows referencing the merged contacts at the survivor.
+func moveReferences(tx *gorm.DB, userID uint, from []uint, to uint) ([]Move, error) {
+	var moves []Move
+	for _, ref := range references {
+		var rows []struct {
+			ID   uint
+			From uint
+		}
+		err := tx.Table(ref.Table).Select("id, "+ref.Column+" AS \"from\"").
+			Where("user_id = ? AND "+ref.Column+" IN ?", userID, from).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		ids := make([]uint, len(rows))
+		for i, r := range rows {
+			ids[i] = r.ID
+			moves = append(moves, Move{Table: ref.Table, Column: ref.Column, RowID: r.ID, From: r.From})
+		}
+		if err := tx.Table(ref.Table).Where("id IN ?", ids).Update(ref.Column, to).Error; err != nil {
+			return nil, err
+		}
+	}
+	return moves, nil
+}
+
+func knownField(field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmpty(c *contact.Contact, field string) bool {
+	switch field {
+	case FieldName:
+		return c.Fullname == ""
+	case FieldEmail:
+		return c.Email == ""
+	case FieldPhone:
+		return c.Phone == ""
+	case FieldAddress:
+		return c.Address == ""
+	case FieldOrganization:
+		return c.OrganizationID == nil
+	case FieldJobTitle:
+		return c.JobTitle == ""
+	case FieldDepartment:
+		return c.Department == ""
+	}
+	return true
+}
+
+func copyField(dst, src *contact.Contact, field string) {
+	switch field {
+	case FieldName:
+		dst.Fullname = src.Fullname
+	case FieldEmail:
+		dst.Email = src.Email
+	case FieldPhone:
+		dst.Phone = src.Phone
+	case FieldAddress:
+		dst.Address = src.Address
+	case FieldOrganization:
+		dst.OrganizationID = src.OrganizationID
+	case FieldJobTitle:
+		dst.JobTitle = src.JobTitle
+	case FieldDepartment:
+		dst.Department = src.Department
+	}
+}
+
+func joinIDs(ids []uint) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(s, ",")
+}
+
+func (r *Request) validate() error {
+	if r.UserID == 0 {
+		return errInvalidUserID
+	}
+	if len(r.MergedIDs) == 0 {
+		return errNothingToMerge
+	}
+	seen := map[uint]bool{r.SurvivorID: true}
+	for _, id := range r.MergedIDs {
+		if seen[id] {
+			return errMergeIntoSelf
+		}
+		seen[id] = true
+	}
+	return nil
+}
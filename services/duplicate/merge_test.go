@@ -0,0 +1,191 @@
+package duplicate
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"grpc-contact-manager/services/attachment"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/relationship"
+	"grpc-contact-manager/services/reminder"
+	"grpc-contact-manager/services/timeline"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db        *DB
+	contactDB *contact.DB
+	notes     *timeline.DB
+	relations *relationship.DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if db, err = New(conn); err != nil {
+		log.Fatal(err)
+	}
+	if contactDB, err = contact.New(conn); err != nil {
+		log.Fatal(err)
+	}
+	if notes, err = timeline.New(conn); err != nil {
+		log.Fatal(err)
+	}
+	if relations, err = relationship.New(conn); err != nil {
+		log.Fatal(err)
+	}
+	reminders, err := reminder.New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, migrate := range []func() error{contactDB.Migrate, notes.Migrate, relations.Migrate, reminders.Migrate, db.Migrate} {
+		if err := migrate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := conn.AutoMigrate(attachment.Attachment{}); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func createContact(t *testing.T, c contact.Contact) *contact.Contact {
+	c.Address = "221B Baker Street"
+	created, err := contactDB.Create(c)
+	require.NoError(t, err)
+	return created
+}
+
+func TestFindDuplicates(t *testing.T) {
+	userID := uint(100)
+	a := createContact(t, contact.Contact{UserID: userID, Fullname: "John Smith", Email: "john@example.com", Phone: "555-000-0001"})
+	b := createContact(t, contact.Contact{UserID: userID, Fullname: "Jon Smith", Email: "John@Example.com", Phone: "555-000-0002"})
+	createContact(t, contact.Contact{UserID: userID, Fullname: "Alice Walker", Email: "alice@example.com", Phone: "555-000-0003"})
+	createContact(t, contact.Contact{UserID: userID + 1, Fullname: "John Smith", Email: "john@example.com", Phone: "555-000-0001"})
+
+	pairs, err := db.FindDuplicates(userID, DefaultMinScore)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, a.ID, pairs[0].A.ID)
+	assert.Equal(t, b.ID, pairs[0].B.ID)
+}
+
+func TestMergeAndUndo(t *testing.T) {
+	userID := uint(200)
+	survivor := createContact(t, contact.Contact{UserID: userID, Fullname: "John Smith", Email: "john@example.com", Phone: "555-000-0001"})
+	dup := createContact(t, contact.Contact{UserID: userID, Fullname: "Johnny Smith", Email: "john@work.com", Phone: "555-123-4567", JobTitle: "Engineer"})
+	friend := createContact(t, contact.Contact{UserID: userID, Fullname: "Alice Walker", Email: "alice@example.com", Phone: "555-000-0003"})
+
+	note, err := notes.AddNote(timeline.Note{UserID: userID, ContactID: dup.ID, Body: "Met at the conference"})
+	require.NoError(t, err)
+	_, err = relations.Link(relationship.Relationship{UserID: userID, ContactID: dup.ID, RelatedID: friend.ID, Type: relationship.Colleague, Reciprocal: true})
+	require.NoError(t, err)
+	_, err = relations.Link(relationship.Relationship{UserID: userID, ContactID: dup.ID, RelatedID: survivor.ID, Type: relationship.Colleague})
+	require.NoError(t, err)
+
+	merged, m, err := db.Merge(Request{
+		UserID:     userID,
+		SurvivorID: survivor.ID,
+		MergedIDs:  []uint{dup.ID},
+		Fields:     map[string]uint{FieldEmail: dup.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "John Smith", merged.Fullname)
+	assert.Equal(t, "john@work.com", merged.Email)
+	assert.Equal(t, "555-000-0001", merged.Phone)
+	assert.Equal(t, "Engineer", merged.JobTitle)
+	assert.Equal(t, []uint{dup.ID}, m.Merged())
+
+	_, err = contactDB.FindByID(userID, dup.ID)
+	assert.Error(t, err)
+	var moved timeline.Note
+	require.NoError(t, db.Conn.First(&moved, note.ID).Error)
+	assert.Equal(t, survivor.ID, moved.ContactID)
+	links, err := relations.FindByContact(userID, survivor.ID)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, friend.ID, links[0].RelatedID)
+	revisions, err := contactDB.Revisions(userID, survivor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, contact.ActionMerge, revisions[0].Action)
+	revisions, err = contactDB.Revisions(userID, dup.ID)
+	require.NoError(t, err)
+	assert.Equal(t, contact.ActionDelete, revisions[0].Action)
+
+	restored, err := db.Undo(userID, m.ID)
+	require.NoError(t, err)
+	require.Len(t, restored, 2)
+	assert.Equal(t, "john@example.com", restored[0].Email)
+	assert.Empty(t, restored[0].JobTitle)
+	assert.Equal(t, "john@work.com", restored[1].Email)
+	require.NoError(t, db.Conn.First(&moved, note.ID).Error)
+	assert.Equal(t, dup.ID, moved.ContactID)
+	revisions, err = contactDB.Revisions(userID, dup.ID)
+	require.NoError(t, err)
+	assert.Equal(t, contact.ActionUnmerge, revisions[0].Action)
+	links, err = relations.FindByContact(userID, dup.ID)
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	_, err = db.Undo(userID, m.ID)
+	assert.Equal(t, ErrAlreadyUndone, err)
+}
+
+func TestUndoAfterChanges(t *testing.T) {
+	userID := uint(400)
+	survivor := createContact(t, contact.Contact{UserID: userID, Fullname: "John Smith", Email: "john@example.com", Phone: "555-000-0001"})
+	dup := createContact(t, contact.Contact{UserID: userID, Fullname: "Johnny Smith", Email: "john@work.com", Phone: "555-123-4567"})
+	merged, m, err := db.Merge(Request{UserID: userID, SurvivorID: survivor.ID, MergedIDs: []uint{dup.ID}})
+	require.NoError(t, err)
+
+	// undoing would overwrite the phone number changed since
+	merged.Phone = "555-999-0000"
+	require.NoError(t, contactDB.Update(merged))
+	_, err = db.Undo(userID, m.ID)
+	assert.Equal(t, ErrChangedSinceMerge, err)
+	found, err := contactDB.FindByID(userID, survivor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "555-999-0000", found.Phone)
+
+	// or bring merged contacts back next to a deleted survivor
+	other := createContact(t, contact.Contact{UserID: userID, Fullname: "Jane Doe", Email: "jane@example.com", Phone: "555-000-0005"})
+	_, m, err = db.Merge(Request{UserID: userID, SurvivorID: other.ID, MergedIDs: []uint{survivor.ID}, Fields: map[string]uint{FieldName: survivor.ID}})
+	require.NoError(t, err)
+	_, err = contactDB.Delete(userID, other.ID)
+	require.NoError(t, err)
+	_, err = db.Undo(userID, m.ID)
+	assert.Equal(t, ErrChangedSinceMerge, err)
+}
+
+func TestMergeErrors(t *testing.T) {
+	userID := uint(300)
+	a := createContact(t, contact.Contact{UserID: userID, Fullname: "John Smith", Email: "john@example.com", Phone: "555-000-0001"})
+	b := createContact(t, contact.Contact{UserID: userID, Fullname: "Jon Smith", Email: "jon@example.com", Phone: "555-000-0004"})
+	other := createContact(t, contact.Contact{UserID: userID + 1, Fullname: "Jon Smith", Email: "jon@example.com", Phone: "555-000-0004"})
+
+	table := []struct {
+		req  Request
+		want error
+	}{
+		{req: Request{UserID: userID, SurvivorID: a.ID}, want: errNothingToMerge},
+		{req: Request{UserID: userID, SurvivorID: a.ID, MergedIDs: []uint{a.ID}}, want: errMergeIntoSelf},
+		{req: Request{UserID: userID, SurvivorID: a.ID, MergedIDs: []uint{other.ID}}, want: ErrNotFound},
+		{req: Request{UserID: userID, SurvivorID: a.ID, MergedIDs: []uint{b.ID}, Fields: map[string]uint{"nickname": b.ID}}, want: errUnknownField},
+		{req: Request{UserID: userID, SurvivorID: a.ID, MergedIDs: []uint{b.ID}, Fields: map[string]uint{FieldEmail: other.ID}}, want: errInvalidSource},
+	}
+	for i, row := range table {
+		_, _, err := db.Merge(row.req)
+		assert.Equal(t, row.want, err, fmt.Sprint(i))
+	}
+
+	_, err := db.Undo(userID, 999999)
+	assert.Equal(t, ErrNotFound, err)
+}
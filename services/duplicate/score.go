@@ -0,0 +1,238 @@
+package duplicate
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"grpc-contact-manager/services/contact"
+)
+
+const (
+	// DefaultMinScore is the lowest score reported as a likely duplicate.
+	DefaultMinScore = 0.5
+
+	emailWeight = 0.4
+	phoneWeight = 0.35
+	nameWeight  = 0.25
+
+	// nameOnlyThreshold lets near identical names count as a duplicate on their own.
+	nameOnlyThreshold = 0.95
+)
+
+// Reasons a pair of contacts was scored as duplicates
+const (
+	ReasonEmail = "email"
+	ReasonPhone = "phone"
+	ReasonName  = "name"
+)
+
+// Pair is a pair of contacts that are likely the same person.
+type Pair struct {
+	A       contact.Contact
+	B       contact.Contact
+	Score   float64
+	Reasons []string
+}
+
+// Score rates how likely two contacts are the same person, between 0 and 1, and why.
+func Score(a, b *contact.Contact) (float64, []string) {
+	var (
+		score   float64
+		reasons []string
+	)
+	if ea := NormalizeEmail(a.Email); ea != "" && ea == NormalizeEmail(b.Email) {
+		score += emailWeight
+		reasons = append(reasons, ReasonEmail)
+	}
+	if pa := NormalizePhone(a.Phone); pa != "" && pa == NormalizePhone(b.Phone) {
+		score += phoneWeight
+		reasons = append(reasons, ReasonPhone)
+	}
+	similarity := NameSimilarity(a.Fullname, b.Fullname)
+	score += nameWeight * similarity
+	if similarity >= 0.85 {
+		reasons = append(reasons, ReasonName)
+	}
+	if len(reasons) == 1 && reasons[0] == ReasonName && similarity >= nameOnlyThreshold && score < DefaultMinScore {
+		score = DefaultMinScore
+	}
+	return score, reasons
+}
+
+// Find returns the pairs of contacts scoring at least minScore, best first. Only contacts
+// sharing an email, a phone number or a name token are compared, so the cost stays close to
+// linear for realistic address books.
+func Find(contacts []contact.Contact, minScore float64) []Pair {
+	if minScore <= 0 {
+		minScore = DefaultMinScore
+	}
+	blocks := map[string][]int{}
+	for i := range contacts {
+		for _, key := range blockingKeys(&contacts[i]) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	type pairKey struct{ a, b int }
+	seen := map[pairKey]bool{}
+	var pairs []Pair
+	for _, members := range blocks {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				k := pairKey{members[x], members[y]}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				a, b := &contacts[k.a], &contacts[k.b]
+				if score, reasons := Score(a, b); score >= minScore {
+					pairs = append(pairs, Pair{A: *a, B: *b, Score: score, Reasons: reasons})
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].A.ID != pairs[j].A.ID {
+			return pairs[i].A.ID < pairs[j].A.ID
+		}
+		return pairs[i].B.ID < pairs[j].B.ID
+	})
+	return pairs
+}
+
+func blockingKeys(c *contact.Contact) []string {
+	var keys []string
+	if e := NormalizeEmail(c.Email); e != "" {
+		keys = append(keys, "e:"+e)
+	}
+	if p := NormalizePhone(c.Phone); p != "" {
+		keys = append(keys, "p:"+p)
+	}
+	for _, token := range nameTokens(c.Fullname) {
+		if len(token) > 1 {
+			keys = append(keys, "n:"+token)
+		}
+	}
+	return keys
+}
+
+// NormalizeEmail lower cases the address. For Gmail it also drops dots and "+tag" suffixes,
+// which Gmail ignores when delivering.
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		if plus := strings.Index(local, "+"); plus >= 0 {
+			local = local[:plus]
+		}
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// NormalizePhone keeps the digits of the number and compares on the last ten, so the same
+// number written with or without the country code or trunk prefix matches.
+func NormalizePhone(phone string) string {
+	var digits []rune
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 7 {
+		return ""
+	}
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return string(digits)
+}
+
+// NameSimilarity compares two names with Jaro-Winkler after lower casing them and sorting
+// their words, so "Doe, Jane" and "jane doe" are identical.
+func NameSimilarity(a, b string) float64 {
+	ta, tb := nameTokens(a), nameTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	sort.Strings(ta)
+	sort.Strings(tb)
+	return jaroWinkler(strings.Join(ta, " "), strings.Join(tb, " "))
+}
+
+func nameTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, min(len(ra), len(rb))) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
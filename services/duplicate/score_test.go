@@ -0,0 +1,85 @@
+package duplicate
+
+import (
+	"testing"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	table := []struct {
+		email string
+		want  string
+	}{
+		{email: "John@Example.com", want: "john@example.com"},
+		{email: " j.o.h.n+work@gmail.com ", want: "john@gmail.com"},
+		{email: "j.smith@example.com", want: "j.smith@example.com"},
+		{email: "", want: ""},
+	}
+	for _, row := range table {
+		assert.Equal(t, row.want, NormalizeEmail(row.email), row.email)
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	table := []struct {
+		phone string
+		want  string
+	}{
+		{phone: "+1 (555) 123-4567", want: "5551234567"},
+		{phone: "555.123.4567", want: "5551234567"},
+		{phone: "12345", want: ""},
+		{phone: "", want: ""},
+	}
+	for _, row := range table {
+		assert.Equal(t, row.want, NormalizePhone(row.phone), row.phone)
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, NameSimilarity("John Smith", "smith john"))
+	assert.Greater(t, NameSimilarity("Jon Smith", "John Smith"), 0.9)
+	assert.Less(t, NameSimilarity("John Smith", "Alice Walker"), 0.7)
+	assert.Equal(t, 0.0, NameSimilarity("", "John"))
+}
+
+func TestScore(t *testing.T) {
+	a := contact.Contact{Fullname: "John Smith", Email: "john.smith@gmail.com", Phone: "555-123-4567"}
+
+	score, reasons := Score(&a, &contact.Contact{Fullname: "Jon Smith", Email: "johnsmith@gmail.com"})
+	assert.GreaterOrEqual(t, score, DefaultMinScore)
+	assert.Contains(t, reasons, ReasonEmail)
+
+	score, reasons = Score(&a, &contact.Contact{Fullname: "Johnny Smith", Phone: "+1 555 123 4567"})
+	assert.GreaterOrEqual(t, score, DefaultMinScore)
+	assert.Contains(t, reasons, ReasonPhone)
+
+	score, reasons = Score(&a, &contact.Contact{Fullname: "John Smith"})
+	assert.GreaterOrEqual(t, score, DefaultMinScore)
+	assert.Equal(t, []string{ReasonName}, reasons)
+
+	score, _ = Score(&a, &contact.Contact{Fullname: "Alice Walker", Email: "alice@example.com"})
+	assert.Less(t, score, DefaultMinScore)
+}
+
+func TestFind(t *testing.T) {
+	contacts := []contact.Contact{
+		{Fullname: "John Smith", Email: "john@example.com"},
+		{Fullname: "Alice Walker", Phone: "555 987 6543"},
+		{Fullname: "Johnny Smith", Email: "JOHN@example.com"},
+		{Fullname: "Alicia Walker", Phone: "(555) 987-6543"},
+		{Fullname: "Bob Stone"},
+	}
+	for i := range contacts {
+		contacts[i].ID = uint(i + 1)
+	}
+
+	pairs := Find(contacts, DefaultMinScore)
+	if assert.Len(t, pairs, 2) {
+		ids := [][2]uint{{pairs[0].A.ID, pairs[0].B.ID}, {pairs[1].A.ID, pairs[1].B.ID}}
+		assert.ElementsMatch(t, [][2]uint{{1, 3}, {2, 4}}, ids)
+	}
+	assert.Empty(t, Find(contacts[4:], DefaultMinScore))
+}
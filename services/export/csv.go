@@ -0,0 +1,53 @@
+package export
+
+import (
+	"io"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/sheet"
+)
+
+func init() {
+	Register(Format{
+		Name:        "csv",
+		ContentType: sheet.CSVContentType,
+		Extension:   ".csv",
+		NewEncoder:  newCSVEncoder,
+	})
+}
+
+// csvEncoder writes contacts as a CSV file with all the columns, which reads back with the
+// default preset.
+type csvEncoder struct {
+	w  io.Writer
+	cw *sheet.CSVWriter
+}
+
+func newCSVEncoder(w io.Writer) Encoder {
+	return &csvEncoder{w: w}
+}
+
+func (e *csvEncoder) Encode(c *contact.Contact, organization string) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return e.cw.Write(&sheet.Row{Contact: *c, Organization: organization})
+}
+
+// Close writes the header of a file with no contacts and flushes the rows.
+func (e *csvEncoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return e.cw.Flush()
+}
+
+// start writes the header once.
+func (e *csvEncoder) start() error {
+	if e.cw != nil {
+		return nil
+	}
+	var err error
+	e.cw, err = sheet.NewCSVWriter(e.w, nil, false)
+	return err
+}
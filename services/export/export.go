@@ -0,0 +1,64 @@
+// Package export encodes contacts in the file formats they can be exported as. Formats register
+// themselves by name, so adding one takes a new encoder and nothing else.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+)
+
+// ErrUnknownFormat is returned for a format that isn't registered.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Encoder writes contacts one at a time, without holding them in memory.
+type Encoder interface {
+	// Encode writes the contact. organization is the name of its organization, if any.
+	Encode(c *contact.Contact, organization string) error
+	// Close writes whatever the format needs after the last contact. It doesn't close the
+	// underlying writer.
+	Close() error
+}
+
+// Format describes a file format contacts can be exported as.
+type Format struct {
+	// Name selects the format, such as "ldif".
+	Name        string
+	ContentType string
+	// Extension is the file name extension, with its dot.
+	Extension  string
+	NewEncoder func(w io.Writer) Encoder
+}
+
+var formats = map[string]Format{}
+
+// Register makes the format available by name. It panics if the name is taken.
+func Register(f Format) {
+	if _, ok := formats[f.Name]; ok {
+		panic("export: format " + f.Name + " registered twice")
+	}
+	formats[f.Name] = f
+}
+
+// Lookup returns the format registered under the name, ignoring case.
+func Lookup(name string) (Format, error) {
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return Format{}, fmt.Errorf("%w %q, expected one of %s", ErrUnknownFormat, name, strings.Join(Names(), ", "))
+	}
+	return f, nil
+}
+
+// Names lists the registered formats in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/sheet"
+	"grpc-contact-manager/services/vcard"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	assert.Equal(t, []string{"csv", "jsonl", "ldif", "vcard"}, Names())
+	f, err := Lookup("LDIF")
+	require.NoError(t, err)
+	assert.Equal(t, ".ldif", f.Extension)
+
+	_, err = Lookup("xml")
+	assert.True(t, errors.Is(err, ErrUnknownFormat))
+	assert.Contains(t, err.Error(), "csv, jsonl, ldif, vcard")
+	assert.Panics(t, func() { Register(Format{Name: "jsonl"}) })
+}
+
+func TestJSONL(t *testing.T) {
+	created := time.Date(2022, 3, 14, 15, 9, 26, 0, time.UTC)
+	org := uint(7)
+	var buf bytes.Buffer
+	enc := newJSONLEncoder(&buf)
+	c := contact.Contact{Fullname: "Jane <Doe>", Email: "jane@acme.com", OrganizationID: &org, Version: 2}
+	c.ID, c.CreatedAt, c.UpdatedAt = 1, created, created
+	require.NoError(t, enc.Encode(&c, "Acme"))
+	c = contact.Contact{Fullname: "Ola Bello", Phone: "08155040074"}
+	c.ID = 2
+	require.NoError(t, enc.Encode(&c, ""))
+	require.NoError(t, enc.Close())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"full_name":"Jane <Doe>"`)
+	assert.NotContains(t, lines[0], `"User"`)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	assert.Equal(t, float64(7), got["organization_id"])
+	assert.Equal(t, "Acme", got["organization"])
+	assert.Equal(t, "2022-03-14T15:09:26Z", got["created_at"])
+	assert.Nil(t, got["last_contacted_at"])
+	got = nil
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &got))
+	assert.Equal(t, "08155040074", got["phone"])
+	assert.NotContains(t, got, "organization")
+}
+
+func TestVCardAndCSV(t *testing.T) {
+	c := contact.Contact{Fullname: "Jane Doe", Email: "jane@acme.com", Phone: "+2347033304280", JobTitle: "Engineer"}
+
+	var buf bytes.Buffer
+	enc := newVCardEncoder(&buf)
+	require.NoError(t, enc.Encode(&c, "Acme"))
+	require.NoError(t, enc.Close())
+	e, err := vcard.NewDecoder(&buf).Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", e.Contact.Fullname)
+	assert.Equal(t, "jane@acme.com", e.Contact.Email)
+	assert.Equal(t, "Acme", e.Organization)
+
+	buf.Reset()
+	enc = newCSVEncoder(&buf)
+	require.NoError(t, enc.Close())
+	assert.True(t, strings.HasPrefix(buf.String(), "name,email,"), buf.String())
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+
+	// the file reads back with the default preset
+	buf.Reset()
+	enc = newCSVEncoder(&buf)
+	require.NoError(t, enc.Encode(&c, "Acme"))
+	require.NoError(t, enc.Close())
+	r, err := sheet.NewCSVReader(&buf, sheet.Presets["default"], "")
+	require.NoError(t, err)
+	row, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, c.Phone, row.Contact.Phone)
+	assert.Equal(t, c.JobTitle, row.Contact.JobTitle)
+	assert.Equal(t, "Acme", row.Organization)
+}
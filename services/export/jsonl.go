@@ -0,0 +1,68 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+)
+
+func init() {
+	Register(Format{
+		Name:        "jsonl",
+		ContentType: "application/x-ndjson",
+		Extension:   ".jsonl",
+		NewEncoder:  newJSONLEncoder,
+	})
+}
+
+// record is the JSON object of a contact, leaving out its user.
+type record struct {
+	ID              uint       `json:"id"`
+	Fullname        string     `json:"full_name"`
+	Email           string     `json:"email"`
+	Phone           string     `json:"phone"`
+	Address         string     `json:"address"`
+	OrganizationID  *uint      `json:"organization_id"`
+	Organization    string     `json:"organization,omitempty"`
+	JobTitle        string     `json:"job_title"`
+	Department      string     `json:"department"`
+	LastContactedAt *time.Time `json:"last_contacted_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Version         uint       `json:"version"`
+}
+
+// jsonlEncoder writes every contact as a JSON object on its own line.
+type jsonlEncoder struct {
+	enc *json.Encoder
+}
+
+func newJSONLEncoder(w io.Writer) Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlEncoder{enc: enc}
+}
+
+func (e *jsonlEncoder) Encode(c *contact.Contact, organization string) error {
+	return e.enc.Encode(record{
+		ID:              c.ID,
+		Fullname:        c.Fullname,
+		Email:           c.Email,
+		Phone:           c.Phone,
+		Address:         c.Address,
+		OrganizationID:  c.OrganizationID,
+		Organization:    organization,
+		JobTitle:        c.JobTitle,
+		Department:      c.Department,
+		LastContactedAt: c.LastContactedAt,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+		Version:         c.Version,
+	})
+}
+
+func (e *jsonlEncoder) Close() error {
+	return nil
+}
@@ -0,0 +1,155 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+)
+
+func init() {
+	Register(Format{
+		Name:        "ldif",
+		ContentType: "text/x-ldif",
+		Extension:   ".ldif",
+		NewEncoder:  newLDIFEncoder,
+	})
+}
+
+// ldifLineLength is the length lines are folded at.
+const ldifLineLength = 76
+
+// ldifEncoder writes contacts as inetOrgPerson entries of an LDIF file (RFC 2849), named like
+// the entries of Thunderbird address books so that they load into LDAP directories under any
+// base DN.
+type ldifEncoder struct {
+	w       io.Writer
+	buf     bytes.Buffer
+	started bool
+}
+
+func newLDIFEncoder(w io.Writer) Encoder {
+	return &ldifEncoder{w: w}
+}
+
+func (e *ldifEncoder) Encode(c *contact.Contact, organization string) error {
+	e.buf.Reset()
+	e.header()
+	cn := firstNonEmpty(c.Fullname, c.Email, c.Phone)
+	dn := "cn=" + escapeDN(cn)
+	if c.Email != "" {
+		dn += ",mail=" + escapeDN(c.Email)
+	}
+	e.attr("dn", dn)
+	for _, class := range []string{"top", "person", "organizationalPerson", "inetOrgPerson"} {
+		e.attr("objectClass", class)
+	}
+	given, surname := splitName(c.Fullname)
+	e.attr("cn", cn)
+	e.attr("sn", firstNonEmpty(surname, cn))
+	e.attr("givenName", given)
+	e.attr("mail", c.Email)
+	e.attr("telephoneNumber", c.Phone)
+	e.attr("postalAddress", postalAddress(c.Address))
+	e.attr("title", c.JobTitle)
+	e.attr("o", organization)
+	e.attr("ou", c.Department)
+	e.buf.WriteByte('\n')
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+// Close writes the version line of a file with no entries.
+func (e *ldifEncoder) Close() error {
+	if e.started {
+		return nil
+	}
+	e.buf.Reset()
+	e.header()
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+func (e *ldifEncoder) header() {
+	if !e.started {
+		e.buf.WriteString("version: 1\n\n")
+		e.started = true
+	}
+}
+
+// attr writes the attribute unless its value is empty, base64 encoding values that aren't
+// safe strings, and folds the line.
+func (e *ldifEncoder) attr(name, value string) {
+	if value == "" {
+		return
+	}
+	line := name + ": " + value
+	if !safeString(value) {
+		line = name + ":: " + base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	// continuation lines start with a space
+	for n := ldifLineLength; len(line) > n; n = ldifLineLength - 1 {
+		e.buf.WriteString(line[:n])
+		e.buf.WriteString("\n ")
+		line = line[n:]
+	}
+	e.buf.WriteString(line)
+	e.buf.WriteByte('\n')
+}
+
+// safeString reports whether the value can be written as is: ASCII without NUL, CR or LF, not
+// starting with a space, colon or less-than sign, and not ending with a space.
+func safeString(s string) bool {
+	if strings.HasPrefix(s, " ") || strings.HasPrefix(s, ":") || strings.HasPrefix(s, "<") || strings.HasSuffix(s, " ") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b == 0 || b == '\r' || b == '\n' || b > 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeDN escapes an attribute value of a distinguished name (RFC 4514).
+func escapeDN(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case strings.IndexByte(`,+"\<>;=`, c) >= 0,
+			c == '#' && i == 0,
+			c == ' ' && (i == 0 || i == len(s)-1):
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// postalAddress writes the lines of an address separated by dollar signs (RFC 4517).
+func postalAddress(address string) string {
+	address = strings.NewReplacer(`\`, `\5C`, `$`, `\24`).Replace(address)
+	lines := strings.FieldsFunc(address, func(r rune) bool { return r == '\n' || r == '\r' })
+	return strings.Join(lines, "$")
+}
+
+// splitName splits a full name at its last space into given name and surname.
+func splitName(name string) (given, surname string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexByte(name, ' '); i >= 0 {
+		return strings.TrimSpace(name[:i]), name[i+1:]
+	}
+	return "", name
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
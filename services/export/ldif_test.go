@@ -0,0 +1,75 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDIF(t *testing.T) {
+	var buf bytes.Buffer
+	enc := newLDIFEncoder(&buf)
+	require.NoError(t, enc.Encode(&contact.Contact{
+		Fullname:   "Doe, Jane Ann",
+		Email:      "jane.doe@acme.com",
+		Phone:      "+234 703 330 4280",
+		Address:    "12 Marina Road\nLagos",
+		JobTitle:   "Head of Engineering, Platform and Developer Experience, West Africa",
+		Department: "Engineering",
+	}, "Acme"))
+	require.NoError(t, enc.Encode(&contact.Contact{Fullname: "Adébáyọ̀", Phone: "0803"}, ""))
+	require.NoError(t, enc.Close())
+
+	assert.Equal(t, `version: 1
+
+dn: cn=Doe\, Jane Ann,mail=jane.doe@acme.com
+objectClass: top
+objectClass: person
+objectClass: organizationalPerson
+objectClass: inetOrgPerson
+cn: Doe, Jane Ann
+sn: Ann
+givenName: Doe, Jane
+mail: jane.doe@acme.com
+telephoneNumber: +234 703 330 4280
+postalAddress: 12 Marina Road$Lagos
+title: Head of Engineering, Platform and Developer Experience, West Africa
+o: Acme
+ou: Engineering
+
+dn:: Y249QWTDqWLDoXnhu43MgA==
+objectClass: top
+objectClass: person
+objectClass: organizationalPerson
+objectClass: inetOrgPerson
+cn:: QWTDqWLDoXnhu43MgA==
+sn:: QWTDqWLDoXnhu43MgA==
+telephoneNumber: 0803
+
+`, buf.String())
+}
+
+func TestLDIFFolding(t *testing.T) {
+	var buf bytes.Buffer
+	enc := newLDIFEncoder(&buf)
+	require.NoError(t, enc.Close())
+	assert.Equal(t, "version: 1\n\n", buf.String())
+
+	e := &ldifEncoder{}
+	e.attr("description", " "+strings.Repeat("a", 200))
+	lines := strings.Split(strings.TrimSuffix(e.buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Len(t, lines[0], ldifLineLength)
+	assert.True(t, strings.HasPrefix(lines[0], "description:: IGFh"))
+	for _, l := range lines[1:3] {
+		assert.Len(t, l, ldifLineLength)
+		assert.Equal(t, byte(' '), l[0])
+	}
+	assert.Equal(t, `\#1 a\,b\ `, escapeDN("#1 a,b "))
+	assert.Equal(t, `a\5Cb\24c$d`, postalAddress("a\\b$c\r\nd"))
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"io"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/vcard"
+)
+
+func init() {
+	Register(Format{
+		Name:        "vcard",
+		ContentType: vcard.ContentType,
+		Extension:   ".vcf",
+		NewEncoder:  newVCardEncoder,
+	})
+}
+
+// vcardEncoder writes contacts as vCard 4.0 cards, without their photos.
+type vcardEncoder struct {
+	enc *vcard.Encoder
+	err error
+}
+
+func newVCardEncoder(w io.Writer) Encoder {
+	enc, err := vcard.NewEncoder(w, vcard.Version4)
+	return &vcardEncoder{enc: enc, err: err}
+}
+
+func (e *vcardEncoder) Encode(c *contact.Contact, organization string) error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.enc.Encode(&vcard.Entry{Contact: *c, Organization: organization})
+}
+
+func (e *vcardEncoder) Close() error {
+	return e.err
+}
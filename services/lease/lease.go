@@ -0,0 +1,51 @@
+// Package lease locks the rows that workers running side by side pick up from a table, such as
+// due reminders and address books. A row is held until its locked_until column, and its
+// attempts column counts the claims.
+package lease
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Available scopes a query to the rows no worker holds at now.
+func Available(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("locked_until IS NULL OR locked_until < ?", now)
+	}
+}
+
+// Until returns when a lock taken at now for d ends. It is kept to the microseconds databases
+// store, so the worker holding a lock can recognize it.
+func Until(now time.Time, d time.Duration) time.Time {
+	return now.Add(d).Truncate(time.Microsecond)
+}
+
+// Claim locks the row of tx, scoped to a model, with the given ID until until, unless another
+// worker holds it at now. It tells whether the row was locked.
+func Claim(tx *gorm.DB, id uint, now, until time.Time) (bool, error) {
+	res := tx.Where("id = ?", id).Scopes(Available(now)).
+		Updates(map[string]interface{}{"locked_until": until, "attempts": gorm.Expr("attempts + 1")})
+	return res.RowsAffected == 1, res.Error
+}
+
+// Renew extends the lock held until held on the row of tx to until. It tells whether the lock
+// was still held, rather than expired and claimed by another worker.
+func Renew(tx *gorm.DB, held, until time.Time) (bool, error) {
+	res := tx.Where("locked_until = ?", held).Update("locked_until", until)
+	return res.RowsAffected == 1, res.Error
+}
+
+// Backoff doubles the delay before retrying a row with every failed attempt, starting at a
+// minute, up to max.
+func Backoff(attempts int, max time.Duration) time.Duration {
+	d := time.Minute
+	for i := 1; i < attempts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
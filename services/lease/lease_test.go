@@ -0,0 +1,82 @@
+package lease
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// job stands for a table polled by several workers.
+type job struct {
+	ID          uint
+	LockedUntil *time.Time
+	Attempts    int
+}
+
+var (
+	conn *gorm.DB
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	conn, err = gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := conn.AutoMigrate(job{}); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestClaimAndRenew(t *testing.T) {
+	j := job{}
+	require.NoError(t, conn.Create(&j).Error)
+	now := time.Now()
+	until := Until(now, time.Minute)
+
+	ok, err := Claim(conn.Model(&job{}), j.ID, now, until)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	var available []job
+	require.NoError(t, conn.Scopes(Available(now)).Find(&available).Error)
+	assert.Empty(t, available)
+
+	// another worker waits for the lock to expire
+	ok, err = Claim(conn.Model(&job{}), j.ID, now, Until(now, time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	renewed := Until(now.Add(30*time.Second), time.Minute)
+	ok, err = Renew(conn.Model(&job{}).Where("id = ?", j.ID), until, renewed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// once it expired, another worker claims it and the first one can't renew it anymore
+	later := renewed.Add(time.Second)
+	ok, err = Claim(conn.Model(&job{}), j.ID, later, Until(later, time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = Renew(conn.Model(&job{}).Where("id = ?", j.ID), renewed, Until(later, time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, conn.First(&j, j.ID).Error)
+	assert.Equal(t, 2, j.Attempts)
+
+	t.Cleanup(func() {
+		require.NoError(t, conn.Exec("DELETE FROM jobs").Error)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, Backoff(1, time.Hour))
+	assert.Equal(t, 4*time.Minute, Backoff(3, time.Hour))
+	assert.Equal(t, time.Hour, Backoff(20, time.Hour))
+}
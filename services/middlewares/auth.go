@@ -0,0 +1,151 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"grpc-contact-manager/services/user"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey string
+
+const (
+	userIDKey = "user_id"
+
+	userIDContextKey contextKey = "user_id"
+)
+
+var (
+	errMissingToken = errors.New("authorization token not provided")
+)
+
+// TicketRedeemer returns the user a single-use stream ticket was issued to.
+type TicketRedeemer func(ticket string) (uint, error)
+
+// Authenticate validates the bearer token on the request and stores the user ID in the gin context.
+func Authenticate() gin.HandlerFunc {
+	return AuthenticateStream("", nil)
+}
+
+// AuthenticateStream is Authenticate letting the GET requests to streamPath pass a ticket in the
+// ticket query parameter instead of the bearer token, as browsers can't set headers on
+// EventSource and WebSocket connections. The ticket is redeemed with redeem.
+func AuthenticateStream(streamPath string, redeem TicketRedeemer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var userID uint
+		var err error
+		header := c.GetHeader("Authorization")
+		if ticket := c.Query("ticket"); header == "" && ticket != "" && redeem != nil &&
+			c.Request.Method == http.MethodGet && c.Request.URL.Path == streamPath {
+			userID, err = redeem(ticket)
+		} else {
+			userID, err = userFromHeader(header)
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.Set(userIDKey, userID)
+		c.Request = c.Request.WithContext(ContextWithUserID(c.Request.Context(), userID))
+		c.Next()
+	}
+}
+
+// UserID returns the ID of the user authenticated by the Authenticate middleware.
+func UserID(c *gin.Context) uint {
+	return c.GetUint(userIDKey)
+}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user ID.
+func ContextWithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID stored in ctx.
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(uint)
+	return userID, ok && userID != 0
+}
+
+// UnaryAuthInterceptor authenticates unary calls to the given services using the bearer token in the call metadata.
+func UnaryAuthInterceptor(services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !protected(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticateContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates streaming calls to the given services using the bearer token in the call metadata.
+func StreamAuthInterceptor(services ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !protected(info.FullMethod, services) {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticateContext(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticateContext(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get("authorization"); len(values) > 0 {
+		header = values[0]
+	}
+	userID, err := userFromHeader(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return ContextWithUserID(ctx, userID), nil
+}
+
+// protected reports whether the full method name belongs to one of the services.
+func protected(method string, services []string) bool {
+	for _, s := range services {
+		if strings.HasPrefix(method, "/"+s+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func userFromHeader(header string) (uint, error) {
+	token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if token == "" {
+		return 0, errMissingToken
+	}
+	userID, err := user.ValidateToken(token)
+	if err != nil {
+		return 0, err
+	}
+	return uint(userID), nil
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams are the query parameters carrying credentials, kept out of the request logs.
+var redactedParams = []string{"ticket", "access_token"}
+
+// Logger logs the requests like the logger of gin.Default, redacting the credentials in their query.
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			p.TimeStamp.Format("2006/01/02 - 15:04:05"), p.StatusCode, p.Latency, p.ClientIP, p.Method,
+			redactQuery(p.Path), p.ErrorMessage)
+	})
+}
+
+// redactQuery replaces the values of the redacted parameters in the query of the path.
+func redactQuery(path string) string {
+	i := strings.IndexByte(path, '?')
+	if i < 0 {
+		return path
+	}
+	query, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		// the query can't be told apart, so none of it is logged
+		return path[:i]
+	}
+	redacted := false
+	for _, name := range redactedParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return path[:i+1] + query.Encode()
+}
@@ -0,0 +1,215 @@
+package organization
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+	errEmptyName          = errors.New("organization name must be provided")
+	errInvalidDomain      = errors.New("invalid organization domain")
+
+	// ErrNotFound is returned when the organization doesn't exist or belongs to another user.
+	ErrNotFound = errors.New("organization not found")
+	// ErrExists is returned when the user already has an organization with the domain.
+	ErrExists = errors.New("organization with this domain exists")
+
+	// freeMailDomains are shared by people of many organizations, so they never suggest one.
+	freeMailDomains = map[string]bool{
+		"gmail.com":      true,
+		"googlemail.com": true,
+		"yahoo.com":      true,
+		"hotmail.com":    true,
+		"outlook.com":    true,
+		"live.com":       true,
+		"icloud.com":     true,
+		"me.com":         true,
+		"aol.com":        true,
+		"proton.me":      true,
+		"protonmail.com": true,
+		"gmx.com":        true,
+		"yandex.com":     true,
+		"mail.com":       true,
+	}
+)
+
+// Organization is a company or other body the user's contacts belong to.
+type Organization struct {
+	gorm.Model
+	UserID  uint   `json:"user_id" gorm:"column:user_id;index"`
+	Name    string `json:"name"`
+	Domain  string `json:"domain" gorm:"index"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+	Notes   string `json:"notes"`
+}
+
+// DB - organization repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the organization repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the organizations table
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Organization{})
+}
+
+// Create adds an organization for the user.
+func (d *DB) Create(o Organization) (*Organization, error) {
+	o.Domain = NormalizeDomain(o.Domain)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	if err := d.checkDomain(&o); err != nil {
+		return nil, err
+	}
+	return &o, d.Conn.Create(&o).Error
+}
+
+// Find returns the organization with the given ID.
+func (d *DB) Find(userID, id uint) (*Organization, error) {
+	var o Organization
+	err := d.Conn.Where("user_id = ?", userID).First(&o, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// FindByUserID returns the organizations of the user ordered by name.
+func (d *DB) FindByUserID(userID uint) ([]Organization, error) {
+	var organizations []Organization
+	res := d.Conn.Where("user_id = ?", userID).Order("name").Find(&organizations)
+	return organizations, res.Error
+}
+
+// Resolve returns the id of the user's organization with the given name, ignoring case, and
+// creates it when the user has none. The id is nil when the name is empty. Run within the
+// transaction writing the contact, so no organization is left behind when the write fails.
+func (d *DB) Resolve(userID uint, name string) (*uint, error) {
+	if name == "" {
+		return nil, nil
+	}
+	var o Organization
+	res := d.Conn.Where("user_id = ? AND LOWER(name) = ?", userID, strings.ToLower(name)).Order("id").Limit(1).Find(&o)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		created, err := d.Create(Organization{UserID: userID, Name: name})
+		if err != nil {
+			return nil, err
+		}
+		o = *created
+	}
+	return &o.ID, nil
+}
+
+// FindByDomain returns the user's organizations with the given domain.
+func (d *DB) FindByDomain(userID uint, domain string) ([]Organization, error) {
+	var organizations []Organization
+	res := d.Conn.Where("user_id = ? AND domain = ?", userID, NormalizeDomain(domain)).Order("id").Find(&organizations)
+	return organizations, res.Error
+}
+
+// Update changes an organization.
+func (d *DB) Update(o *Organization) error {
+	o.Domain = NormalizeDomain(o.Domain)
+	if err := o.validate(); err != nil {
+		return err
+	}
+	if err := d.checkDomain(o); err != nil {
+		return err
+	}
+	return d.Conn.Save(o).Error
+}
+
+// Suggest returns the user's organizations matching the domain of the email. When there are none
+// it proposes an unsaved organization named after the domain, unless it is a free mail domain.
+func (d *DB) Suggest(userID uint, email string) ([]Organization, error) {
+	domain := EmailDomain(email)
+	if domain == "" {
+		return nil, nil
+	}
+	organizations, err := d.FindByDomain(userID, domain)
+	if err != nil || len(organizations) > 0 || freeMailDomains[domain] {
+		return organizations, err
+	}
+	return []Organization{{UserID: userID, Name: nameFromDomain(domain), Domain: domain}}, nil
+}
+
+// checkDomain makes sure no other organization of the user has the same domain.
+func (d *DB) checkDomain(o *Organization) error {
+	if o.Domain == "" {
+		return nil
+	}
+	var count int64
+	err := d.Conn.Model(&Organization{}).Where("user_id = ? AND domain = ? AND id <> ?", o.UserID, o.Domain, o.ID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+// EmailDomain returns the normalized domain of an email address.
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return NormalizeDomain(email[at+1:])
+}
+
+// NormalizeDomain lower cases the domain and strips any scheme, path and leading "www.".
+func NormalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	domain = strings.TrimPrefix(domain, "www.")
+	return strings.TrimSuffix(domain, ".")
+}
+
+// nameFromDomain turns "acme-corp.co.uk" into "Acme Corp".
+func nameFromDomain(domain string) string {
+	label := strings.SplitN(domain, ".", 2)[0]
+	words := strings.FieldsFunc(label, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func (o *Organization) validate() error {
+	if o.UserID == 0 {
+		return errInvalidUserID
+	}
+	if o.Name == "" {
+		return errEmptyName
+	}
+	if o.Domain != "" && (!strings.Contains(o.Domain, ".") || strings.ContainsAny(o.Domain, " @")) {
+		return errInvalidDomain
+	}
+	return nil
+}
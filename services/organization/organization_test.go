@@ -0,0 +1,162 @@
+package organization
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db *DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db = d
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestValidate(t *testing.T) {
+	table := []struct {
+		name         string
+		organization Organization
+		want         error
+	}{
+		{
+			name:         "All good",
+			organization: Organization{UserID: 1, Name: "Acme", Domain: "acme.com"},
+			want:         nil,
+		},
+		{
+			name:         "No Domain",
+			organization: Organization{UserID: 1, Name: "Acme"},
+			want:         nil,
+		},
+		{
+			name:         "Invalid User",
+			organization: Organization{Name: "Acme"},
+			want:         errInvalidUserID,
+		},
+		{
+			name:         "Empty Name",
+			organization: Organization{UserID: 1, Domain: "acme.com"},
+			want:         errEmptyName,
+		},
+		{
+			name:         "Invalid Domain",
+			organization: Organization{UserID: 1, Name: "Acme", Domain: "jane@acme"},
+			want:         errInvalidDomain,
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.organization.validate())
+		})
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	assert.Equal(t, "acme.com", NormalizeDomain(" https://www.Acme.com/about "))
+	assert.Equal(t, "acme.co.uk", NormalizeDomain("ACME.co.uk."))
+	assert.Equal(t, "acme.com", EmailDomain("Jane.Doe@Acme.COM"))
+	assert.Equal(t, "", EmailDomain("not an email"))
+}
+
+func TestCreateAndUpdate(t *testing.T) {
+	o, err := db.Create(Organization{UserID: 1, Name: "Acme", Domain: "www.acme.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme.com", o.Domain)
+
+	_, err = db.Create(Organization{UserID: 1, Name: "Acme Again", Domain: "acme.com"})
+	assert.Equal(t, ErrExists, err)
+
+	// another user can have the same domain
+	_, err = db.Create(Organization{UserID: 2, Name: "Acme", Domain: "acme.com"})
+	require.NoError(t, err)
+
+	o.Phone = "+2347033304280"
+	require.NoError(t, db.Update(o))
+	found, err := db.Find(1, o.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "+2347033304280", found.Phone)
+
+	_, err = db.Find(3, o.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	o, err := db.Create(Organization{UserID: 1, Name: "Acme", Domain: "acme.com"})
+	require.NoError(t, err)
+
+	suggested, err := db.Suggest(1, "jane@acme.com")
+	require.NoError(t, err)
+	require.Len(t, suggested, 1)
+	assert.Equal(t, o.ID, suggested[0].ID)
+
+	// unknown domains get an unsaved proposal
+	suggested, err = db.Suggest(1, "john@globex-corp.co.uk")
+	require.NoError(t, err)
+	require.Len(t, suggested, 1)
+	assert.Zero(t, suggested[0].ID)
+	assert.Equal(t, "Globex Corp", suggested[0].Name)
+	assert.Equal(t, "globex-corp.co.uk", suggested[0].Domain)
+
+	suggested, err = db.Suggest(1, "john@gmail.com")
+	require.NoError(t, err)
+	assert.Empty(t, suggested)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestResolve(t *testing.T) {
+	o, err := db.Create(Organization{UserID: 1, Name: "Acme"})
+	require.NoError(t, err)
+	_, err = db.Create(Organization{UserID: 2, Name: "Globex"})
+	require.NoError(t, err)
+
+	id, err := db.Resolve(1, "")
+	require.NoError(t, err)
+	assert.Nil(t, id)
+	id, err = db.Resolve(1, "ACME")
+	require.NoError(t, err)
+	assert.Equal(t, o.ID, *id)
+
+	// the other user's organization isn't used
+	id, err = db.Resolve(1, "Globex")
+	require.NoError(t, err)
+	again, err := db.Resolve(1, "globex")
+	require.NoError(t, err)
+	assert.Equal(t, *id, *again)
+	orgs, err := db.FindByUserID(1)
+	require.NoError(t, err)
+	assert.Len(t, orgs, 2)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func cleanup() error {
+	return db.Conn.Exec("DELETE FROM organizations").Error
+}
@@ -0,0 +1,123 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelPadding is the space kept clear inside the edges of a label, in millimetres.
+const labelPadding = 3
+
+var (
+	// ErrUnknownTemplate is returned for a template missing from Templates.
+	ErrUnknownTemplate = errors.New("unknown label template")
+	// ErrInvalidSkip is returned when the labels to skip don't leave one free on the first sheet.
+	ErrInvalidSkip = errors.New("skip must leave a label free on the first sheet")
+)
+
+// Template is the layout of a sheet of labels. Lengths are in millimetres.
+type Template struct {
+	Name                  string
+	PageWidth, PageHeight float64
+	Columns, Rows         int
+	Width, Height         float64
+	Top, Left             float64
+	HorizontalPitch       float64
+	VerticalPitch         float64
+}
+
+// PerSheet returns the number of labels on a sheet.
+func (t Template) PerSheet() int {
+	return t.Columns * t.Rows
+}
+
+// Templates are the supported label sheets by name.
+var Templates = map[string]Template{
+	// Avery 5160: 30 labels of 1" x 2 5/8" on US Letter, also sold as 8160 and 5260
+	"avery5160": {
+		Name: "avery5160", PageWidth: 215.9, PageHeight: 279.4, Columns: 3, Rows: 10,
+		Width: 66.675, Height: 25.4, Top: 12.7, Left: 4.7625, HorizontalPitch: 69.85, VerticalPitch: 25.4,
+	},
+	// Avery 5163: 10 labels of 2" x 4" on US Letter
+	"avery5163": {
+		Name: "avery5163", PageWidth: 215.9, PageHeight: 279.4, Columns: 2, Rows: 5,
+		Width: 101.6, Height: 50.8, Top: 12.7, Left: 3.96875, HorizontalPitch: 104.775, VerticalPitch: 50.8,
+	},
+	// Avery L7160: 21 labels of 63.5 x 38.1 mm on A4
+	"averyl7160": {
+		Name: "averyl7160", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 7,
+		Width: 63.5, Height: 38.1, Top: 15.15, Left: 7.2, HorizontalPitch: 66.04, VerticalPitch: 38.1,
+	},
+}
+
+// LookupTemplate returns the template of the name, ignoring case, Avery 5160 when it is empty.
+func LookupTemplate(name string) (Template, error) {
+	if name == "" {
+		name = "avery5160"
+	}
+	t, ok := Templates[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(Templates))
+		for n := range Templates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Template{}, fmt.Errorf("%w %q, expected one of %s", ErrUnknownTemplate, name, strings.Join(names, ", "))
+	}
+	return t, nil
+}
+
+// Labels renders a mailing label for every entry with an address: the name, the organization
+// and the address lines, centred vertically. skip leaves the first labels of the first sheet
+// blank, so that partly used sheets can be printed on.
+func Labels(t Template, skip int, entries []Entry) (*Document, error) {
+	if skip < 0 || skip >= t.PerSheet() {
+		return nil, ErrInvalidSkip
+	}
+	doc, tr := newDocument("P", t.PageWidth, t.PageHeight)
+	pdf := doc.pdf
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	const size, lineHeight = 9, 4.2
+	maxLines := int((t.Height - 2*labelPadding) / lineHeight)
+	width := t.Width - 2*labelPadding
+	pos := skip
+	for _, e := range entries {
+		if strings.TrimSpace(e.Contact.Address) == "" {
+			continue
+		}
+		if pos%t.PerSheet() == 0 || pdf.PageNo() == 0 {
+			pdf.AddPage()
+		}
+		slot := pos % t.PerSheet()
+		x := t.Left + float64(slot%t.Columns)*t.HorizontalPitch + labelPadding
+		y := t.Top + float64(slot/t.Columns)*t.VerticalPitch
+
+		pdf.SetFont(font, "", size)
+		lines := []string{tr(e.Contact.Fullname)}
+		if e.Organization != "" {
+			lines = append(lines, tr(e.Organization))
+		}
+		lines = append(lines, addressLines(pdf, tr(e.Contact.Address), width)...)
+		if len(lines) > maxLines {
+			lines = lines[:maxLines]
+		}
+		y += (t.Height - float64(len(lines))*lineHeight) / 2
+		for i, line := range lines {
+			style := ""
+			if i == 0 {
+				style = "B"
+			}
+			pdf.SetFont(font, style, size)
+			pdf.SetXY(x, y+float64(i)*lineHeight)
+			pdf.CellFormat(width, lineHeight, fit(pdf, line, width), "", 0, "L", false, 0, "")
+		}
+		pos++
+	}
+	if pdf.PageNo() == 0 {
+		pdf.AddPage()
+	}
+	return doc, pdf.Error()
+}
@@ -0,0 +1,100 @@
+// Package pdf renders printable contact sheets and mailing labels. Text is set in the core PDF
+// fonts, which cover the Windows-1252 character set.
+package pdf
+
+import (
+	"io"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ContentType is the media type of PDF documents.
+const ContentType = "application/pdf"
+
+const (
+	font = "Helvetica"
+	// ellipsis ends truncated text, in Windows-1252
+	ellipsis = "\x85"
+)
+
+// Entry is a contact to print.
+type Entry struct {
+	Contact contact.Contact
+	// Organization is the name of the contact's organization.
+	Organization string
+}
+
+// Document is a rendered PDF document.
+type Document struct {
+	pdf *gofpdf.Fpdf
+}
+
+func newDocument(orientation string, width, height float64) (*Document, func(string) string) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: width, Ht: height},
+	})
+	pdf.SetCreator("grpc-contact-manager", true)
+	return &Document{pdf: pdf}, pdf.UnicodeTranslatorFromDescriptor("")
+}
+
+// WriteTo writes the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := d.pdf.Output(cw)
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// fit truncates the translated text to the width, ending it with an ellipsis.
+func fit(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+ellipsis) > width {
+		text = strings.TrimRight(text[:len(text)-1], " ,")
+	}
+	return text + ellipsis
+}
+
+// addressLines breaks the translated address into lines no wider than width. Line breaks in
+// the address are kept, and longer lines are broken after commas, so that
+// "12 Marina Road, Lagos Island, Lagos" can print as "12 Marina Road, Lagos Island" and "Lagos".
+func addressLines(pdf *gofpdf.Fpdf, address string, width float64) []string {
+	var lines []string
+	for _, line := range strings.FieldsFunc(address, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		current := ""
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if current == "" {
+				current = part
+			} else if next := current + ", " + part; pdf.GetStringWidth(next) <= width {
+				current = next
+			} else {
+				lines = append(lines, current)
+				current = part
+			}
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entries(n int) []Entry {
+	list := make([]Entry, n)
+	for i := range list {
+		list[i] = Entry{Contact: contact.Contact{
+			Fullname: fmt.Sprintf("Contact %d", i),
+			Email:    fmt.Sprintf("contact%d@gmail.com", i),
+			Address:  "33, Tioya Street, Ibadan, Oyo State, Nigeria",
+		}}
+	}
+	return list
+}
+
+// render writes the document without compression, so its text can be searched.
+func render(t *testing.T, doc *Document) string {
+	doc.pdf.SetCompression(false)
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	require.True(t, strings.HasPrefix(buf.String(), "%PDF-"))
+	return buf.String()
+}
+
+func TestLabels(t *testing.T) {
+	list := append(entries(31), Entry{Contact: contact.Contact{Fullname: "No Address"}})
+	list[0].Organization = "Acme"
+	list[0].Contact.Fullname = "Adébáyọ̀ Bello"
+
+	tmpl, err := LookupTemplate("")
+	require.NoError(t, err)
+	assert.Equal(t, 30, tmpl.PerSheet())
+	doc, err := Labels(tmpl, 0, list)
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.pdf.PageNo())
+	out := render(t, doc)
+	assert.Contains(t, out, "(Contact 30)")
+	assert.Contains(t, out, "(Acme)")
+	assert.Contains(t, out, "(33, Tioya Street, Ibadan, Oyo State)")
+	assert.Contains(t, out, "(Nigeria)")
+	assert.NotContains(t, out, "No Address")
+
+	doc, err = Labels(tmpl, 29, list[:2])
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.pdf.PageNo())
+	_, err = Labels(tmpl, 30, list)
+	assert.True(t, errors.Is(err, ErrInvalidSkip))
+
+	tmpl, err = LookupTemplate("AveryL7160")
+	require.NoError(t, err)
+	doc, err = Labels(tmpl, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, doc.pdf.PageNo())
+	_, err = LookupTemplate("avery1234")
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+}
+
+func TestContactSheet(t *testing.T) {
+	list := entries(40)
+	list[1].Contact.JobTitle = "Engineer"
+	list[1].Organization = "Acme"
+	list[2].Contact.Address = "12 Marina Road\nLagos"
+	doc, err := ContactSheet("Holiday cards", list)
+	require.NoError(t, err)
+	assert.Greater(t, doc.pdf.PageNo(), 1)
+	out := render(t, doc)
+	assert.Contains(t, out, "(Holiday cards)")
+	assert.Contains(t, out, "(Engineer - Acme)")
+	assert.Contains(t, out, "(12 Marina Road)")
+	assert.Contains(t, out, "(Page 1 of ")
+}
+
+func TestAddressLines(t *testing.T) {
+	doc, _ := newDocument("P", 100, 100)
+	doc.pdf.SetFont(font, "", 10)
+	width := doc.pdf.GetStringWidth("12 Marina Road, Lagos Island")
+	assert.Equal(t, []string{"12 Marina Road, Lagos Island", "Lagos"},
+		addressLines(doc.pdf, "12 Marina Road,Lagos Island, Lagos", width))
+	assert.Equal(t, []string{"Flat 2", "12 Marina Road"}, addressLines(doc.pdf, "Flat 2,\r\n12 Marina Road", width))
+
+	text := fit(doc.pdf, "A very long name that will not fit on a label", 30)
+	assert.True(t, strings.HasSuffix(text, ellipsis))
+	assert.LessOrEqual(t, doc.pdf.GetStringWidth(text), 30.0)
+}
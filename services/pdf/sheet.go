@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Contact sheets are printed on US Letter. Lengths are in millimetres.
+const (
+	sheetWidth  = 215.9
+	sheetHeight = 279.4
+	sheetMargin = 15
+)
+
+// ContactSheet renders the entries as a list under the title, one block per contact with the
+// name, job and organization, email, phone and address, and numbered pages.
+func ContactSheet(title string, entries []Entry) (*Document, error) {
+	doc, tr := newDocument("P", sheetWidth, sheetHeight)
+	pdf := doc.pdf
+	pdf.SetMargins(sheetMargin, sheetMargin, sheetMargin)
+	pdf.SetAutoPageBreak(false, sheetMargin)
+	pdf.AliasNbPages("")
+	width := sheetWidth - 2*sheetMargin
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont(font, "B", 16)
+		pdf.CellFormat(width, 9, fit(pdf, tr(title), width), "", 1, "L", false, 0, "")
+		pdf.SetDrawColor(0, 0, 0)
+		pdf.Line(sheetMargin, pdf.GetY(), sheetMargin+width, pdf.GetY())
+		pdf.Ln(4)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-sheetMargin + 3)
+		pdf.SetFont(font, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(width, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AddPage()
+	if len(entries) == 0 {
+		pdf.SetFont(font, "I", 10)
+		pdf.CellFormat(width, 6, "No contacts", "", 1, "L", false, 0, "")
+	}
+
+	const lineHeight = 5
+	for i, e := range entries {
+		c := e.Contact
+		pdf.SetFont(font, "", 10)
+		var details []string
+		if job := strings.Join(nonEmpty(c.JobTitle, c.Department), ", "); job != "" {
+			details = append(details, tr(job))
+		}
+		if e.Organization != "" {
+			details = append(details, tr(e.Organization))
+		}
+		lines := nonEmpty(tr(c.Email), tr(c.Phone))
+		lines = append(lines, addressLines(pdf, tr(c.Address), width)...)
+
+		height := 7 + float64(len(lines))*lineHeight + 4
+		if len(details) > 0 {
+			height += lineHeight
+		}
+		if pdf.GetY()+height > sheetHeight-sheetMargin {
+			pdf.AddPage()
+		} else if i > 0 {
+			pdf.SetDrawColor(200, 200, 200)
+			pdf.Line(sheetMargin, pdf.GetY(), sheetMargin+width, pdf.GetY())
+		}
+		pdf.Ln(2)
+		pdf.SetFont(font, "B", 12)
+		pdf.CellFormat(width, 7, fit(pdf, tr(c.Fullname), width), "", 1, "L", false, 0, "")
+		if len(details) > 0 {
+			pdf.SetFont(font, "I", 10)
+			pdf.SetTextColor(90, 90, 90)
+			pdf.CellFormat(width, lineHeight, fit(pdf, strings.Join(details, " - "), width), "", 1, "L", false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		}
+		pdf.SetFont(font, "", 10)
+		for _, line := range lines {
+			pdf.CellFormat(width, lineHeight, fit(pdf, line, width), "", 1, "L", false, 0, "")
+		}
+		pdf.Ln(2)
+	}
+	return doc, pdf.Error()
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/storage"
+
+	"gorm.io/gorm"
+)
+
+// MaxSize is the largest photo accepted for upload, in bytes.
+const MaxSize = 5 << 20
+
+// MaxPixels is the largest width × height of a photo. Compressed images can be much smaller
+// than what they take once decoded, so the byte limit alone isn't enough.
+const MaxPixels = 25_000_000
+
+var (
+	errConnNotInitialized  = errors.New("connection not initialized")
+	errStoreNotInitialized = errors.New("blob store not initialized")
+	errInvalidContactID    = errors.New("invalid contact id")
+	errInvalidUserID       = errors.New("invalid user id")
+	errEmptyPhoto          = errors.New("photo is empty")
+	errPhotoTooLarge       = fmt.Errorf("photo must not be larger than %d bytes", MaxSize)
+	errUnsupportedType     = errors.New("photo must be a jpeg, png or gif image")
+	errTooManyPixels       = fmt.Errorf("photo must not be larger than %d pixels", MaxPixels)
+
+	// ErrNoPhoto is returned when the contact has no photo.
+	ErrNoPhoto = errors.New("contact has no photo")
+
+	allowedTypes = map[string]bool{
+		"image/jpeg": true,
+		"image/png":  true,
+		"image/gif":  true,
+	}
+)
+
+// Photo holds the metadata of a contact photo. The image and its thumbnail live in the blob store.
+type Photo struct {
+	gorm.Model
+	UserID      uint   `json:"user_id" gorm:"column:user_id;index"`
+	ContactID   uint   `json:"contact_id" gorm:"column:contact_id;uniqueIndex"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// Key returns the blob store key of the original image.
+func (p *Photo) Key() string {
+	return fmt.Sprintf("photos/%d/%d/original", p.UserID, p.ContactID)
+}
+
+// ThumbnailKey returns the blob store key of the thumbnail.
+func (p *Photo) ThumbnailKey() string {
+	return fmt.Sprintf("photos/%d/%d/thumbnail", p.UserID, p.ContactID)
+}
+
+// DB - photo repository backed by a database connection and a blob store
+type DB struct {
+	Conn  *gorm.DB
+	Store storage.BlobStore
+}
+
+// New creates a new instance of the photo repository
+func New(conn *gorm.DB, store storage.BlobStore) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	if store == nil {
+		return nil, errStoreNotInitialized
+	}
+	return &DB{Conn: conn, Store: store}, nil
+}
+
+// Migrate creates the photos table
+func (d *DB) Migrate() error {
+	if err := (&changelog.DB{Conn: d.Conn}).Migrate(); err != nil {
+		return err
+	}
+	return d.Conn.AutoMigrate(Photo{})
+}
+
+// Save validates the image, stores it with a generated thumbnail and replaces any previous photo of the contact.
+func (d *DB) Save(userID, contactID uint, data []byte) (*Photo, error) {
+	if userID == 0 {
+		return nil, errInvalidUserID
+	}
+	if contactID == 0 {
+		return nil, errInvalidContactID
+	}
+	contentType, err := validate(data)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := Thumbnail(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := Photo{
+		UserID:      userID,
+		ContactID:   contactID,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if _, err := d.Store.Put(p.Key(), bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if _, err := d.Store.Put(p.ThumbnailKey(), bytes.NewReader(thumb)); err != nil {
+		return nil, err
+	}
+
+	var existing Photo
+	err = d.Conn.Where("contact_id = ?", contactID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	p.ID = existing.ID
+	p.CreatedAt = existing.CreatedAt
+	return &p, d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&p).Error; err != nil {
+			return err
+		}
+		return changelog.Record(tx, userID, changelog.Update, contactID)
+	})
+}
+
+// Find returns the photo metadata of the contact.
+func (d *DB) Find(userID, contactID uint) (*Photo, error) {
+	var p Photo
+	err := d.Conn.Where("user_id = ? AND contact_id = ?", userID, contactID).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoPhoto
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// HasPhoto returns the IDs of the given contacts that have a photo.
+func (d *DB) HasPhoto(contactIDs ...uint) (map[uint]bool, error) {
+	var ids []uint
+	if err := d.Conn.Model(&Photo{}).Where("contact_id IN ?", contactIDs).Pluck("contact_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// Open opens the stored image, or its thumbnail. The caller must close the returned reader.
+func (d *DB) Open(p *Photo, thumbnail bool) (io.ReadCloser, error) {
+	if thumbnail {
+		return d.Store.Get(p.ThumbnailKey())
+	}
+	return d.Store.Get(p.Key())
+}
+
+// Delete removes the photo of the contact together with the stored images.
+func (d *DB) Delete(userID, contactID uint) error {
+	p, err := d.Find(userID, contactID)
+	if err != nil {
+		return err
+	}
+	if err := d.Store.Delete(p.Key()); err != nil {
+		return err
+	}
+	if err := d.Store.Delete(p.ThumbnailKey()); err != nil {
+		return err
+	}
+	return d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(p).Error; err != nil {
+			return err
+		}
+		return changelog.Record(tx, userID, changelog.Update, contactID)
+	})
+}
+
+// validate checks the size of the image and returns its sniffed content type.
+func validate(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errEmptyPhoto
+	}
+	if len(data) > MaxSize {
+		return "", errPhotoTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return "", errUnsupportedType
+	}
+	return contentType, nil
+}
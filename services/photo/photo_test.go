@@ -0,0 +1,158 @@
+package photo
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"grpc-contact-manager/services/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db *DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "photos")
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := storage.NewLocal(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn, store)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db = d
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	data := testImage(t, 300, 200)
+	p, err := db.Save(1, 1, data)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, "image/png", p.ContentType)
+	assert.Equal(t, int64(len(data)), p.Size)
+
+	found, err := db.Find(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, p.ID, found.ID)
+
+	r, err := db.Open(found, false)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, data, b)
+
+	r, err = db.Open(found, true)
+	require.NoError(t, err)
+	thumb, _, err := image.Decode(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, ThumbnailSize, thumb.Bounds().Dx())
+	assert.Equal(t, ThumbnailSize, thumb.Bounds().Dy())
+
+	// uploading again replaces the existing photo
+	again, err := db.Save(1, 1, testImage(t, 50, 50))
+	require.NoError(t, err)
+	assert.Equal(t, p.ID, again.ID)
+
+	has, err := db.HasPhoto(1, 2)
+	require.NoError(t, err)
+	assert.True(t, has[1])
+	assert.False(t, has[2])
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestSaveInvalid(t *testing.T) {
+	table := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{
+			name: "Empty",
+			data: nil,
+			want: errEmptyPhoto,
+		},
+		{
+			name: "Too Large",
+			data: make([]byte, MaxSize+1),
+			want: errPhotoTooLarge,
+		},
+		{
+			name: "Not an Image",
+			data: []byte("%PDF-1.4 this is not an image"),
+			want: errUnsupportedType,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := db.Save(1, 1, tt.data)
+			assert.Nil(t, p)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	p, err := db.Save(1, 2, testImage(t, 20, 20))
+	require.NoError(t, err)
+
+	// other users can't delete the photo
+	assert.Equal(t, ErrNoPhoto, db.Delete(2, 2))
+
+	require.NoError(t, db.Delete(1, 2))
+	_, err = db.Find(1, 2)
+	assert.Equal(t, ErrNoPhoto, err)
+	_, err = db.Open(p, false)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func testImage(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func cleanup() error {
+	return db.Conn.Exec("DELETE FROM photos").Error
+}
@@ -0,0 +1,97 @@
+package photo
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"image"
+	_ "image/gif"  // register the gif decoder
+	_ "image/jpeg" // register the jpeg decoder
+	"image/png"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/draw"
+)
+
+// ThumbnailSize is the width and height of generated thumbnails, in pixels.
+const ThumbnailSize = 128
+
+// ThumbnailContentType is the content type of generated thumbnails.
+const ThumbnailContentType = "image/png"
+
+// InitialsContentType is the content type of the generated initials avatar.
+const InitialsContentType = "image/svg+xml"
+
+var avatarColors = []string{
+	"#1abc9c", "#2ecc71", "#3498db", "#9b59b6", "#34495e",
+	"#16a085", "#27ae60", "#2980b9", "#8e44ad", "#e67e22",
+	"#e74c3c", "#d35400", "#c0392b", "#7f8c8d",
+}
+
+// Thumbnail decodes the image and returns a square PNG thumbnail cropped from its center. Images
+// larger than MaxPixels are rejected before being decoded.
+func Thumbnail(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, errTooManyPixels
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		b.Min.X+(b.Dx()-side)/2,
+		b.Min.Y+(b.Dy()-side)/2,
+	))
+
+	dst := image.NewRGBA(image.Rect(0, 0, ThumbnailSize, ThumbnailSize))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// InitialsSVG renders an avatar with the initials of the name, used when a contact has no photo.
+func InitialsSVG(name string) []byte {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	color := avatarColors[h.Sum32()%uint32(len(avatarColors))]
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[1]d" viewBox="0 0 %[1]d %[1]d">`+
+		`<rect width="100%%" height="100%%" fill="%[2]s"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" fill="#ffffff" font-family="Helvetica, Arial, sans-serif" font-size="%[3]d" text-anchor="middle">%[4]s</text>`+
+		`</svg>`, ThumbnailSize, color, ThumbnailSize*2/5, html.EscapeString(Initials(name))))
+}
+
+// Initials returns the upper cased first letters of the first and last words of the name.
+func Initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "?"
+	}
+	initials := []rune{[]rune(words[0])[0]}
+	if len(words) > 1 {
+		initials = append(initials, []rune(words[len(words)-1])[0])
+	}
+	return strings.ToUpper(string(initials))
+}
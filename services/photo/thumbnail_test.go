@@ -0,0 +1,58 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitials(t *testing.T) {
+	table := []struct {
+		name string
+		want string
+	}{
+		{name: "Alugbin Abiodun", want: "AA"},
+		{name: "alugbin abiodun olutola", want: "AO"},
+		{name: "Cher", want: "C"},
+		{name: "  O'Neil, Émile ", want: "OÉ"},
+		{name: "", want: "?"},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Initials(tt.name))
+		})
+	}
+}
+
+func TestInitialsSVG(t *testing.T) {
+	svg := string(InitialsSVG("Alugbin Abiodun"))
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, ">AA</text>")
+	// the same name always gets the same color
+	assert.Equal(t, svg, string(InitialsSVG("Alugbin Abiodun")))
+}
+
+func TestThumbnailInvalidImage(t *testing.T) {
+	thumb, err := Thumbnail(bytes.NewReader([]byte("not an image")))
+	require.Error(t, err)
+	assert.Nil(t, thumb)
+}
+
+func TestThumbnailTooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), palette.Plan9), nil))
+	data := buf.Bytes()
+	// declare a 65535 x 65535 screen in the header of an image of a few bytes
+	binary.LittleEndian.PutUint16(data[6:], 0xffff)
+	binary.LittleEndian.PutUint16(data[8:], 0xffff)
+	thumb, err := Thumbnail(bytes.NewReader(data))
+	assert.Equal(t, errTooManyPixels, err)
+	assert.Nil(t, thumb)
+}
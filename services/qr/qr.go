@@ -0,0 +1,151 @@
+// Package qr draws QR codes as PNG or SVG images and reads them back from photos and screenshots.
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	// decoders of the images codes are read from
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/makiuchi-d/gozxing/qrcode/decoder"
+)
+
+// Media types of the images codes are drawn as.
+const (
+	PNGContentType = "image/png"
+	SVGContentType = "image/svg+xml"
+)
+
+// quietZone is the width of the blank border around a code, in modules.
+const quietZone = 4
+
+// MaxPixels is the largest width × height of an image codes are read from. Compressed images
+// can be much smaller than what they take once decoded, so limiting bytes isn't enough.
+const MaxPixels = 25_000_000
+
+var (
+	errEmpty = errors.New("text to encode is empty")
+
+	// ErrTooLong is returned for text that doesn't fit in a QR code.
+	ErrTooLong = errors.New("text is too long for a QR code")
+	// ErrNoCode is returned when no QR code can be read from an image.
+	ErrNoCode = errors.New("no QR code found in the image")
+	// ErrInvalidImage is returned for images that can't be decoded.
+	ErrInvalidImage = errors.New("image must be a png, jpeg or gif file")
+	// ErrTooManyPixels is returned for images larger than MaxPixels.
+	ErrTooManyPixels = fmt.Errorf("image must not be larger than %d pixels", MaxPixels)
+)
+
+// Code is a QR code, a square of dark and light modules.
+type Code struct {
+	modules *gozxing.BitMatrix
+}
+
+// Encode encodes the text as UTF-8 with medium error correction, so that codes still scan from
+// a cracked phone screen.
+func Encode(text string) (*Code, error) {
+	if text == "" {
+		return nil, errEmpty
+	}
+	modules, err := qrcode.NewQRCodeWriter().Encode(text, gozxing.BarcodeFormat_QR_CODE, 0, 0,
+		map[gozxing.EncodeHintType]interface{}{
+			gozxing.EncodeHintType_ERROR_CORRECTION: decoder.ErrorCorrectionLevel_M,
+			gozxing.EncodeHintType_CHARACTER_SET:    "UTF-8",
+			gozxing.EncodeHintType_MARGIN:           quietZone,
+		})
+	if _, ok := err.(gozxing.WriterException); ok {
+		return nil, ErrTooLong
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Code{modules: modules}, nil
+}
+
+// Size returns the number of modules on a side, the quiet zone included.
+func (c *Code) Size() int {
+	return c.modules.GetWidth()
+}
+
+// PNG draws the code as a grayscale PNG image of about size pixels on a side, rounded down to
+// whole pixels per module but never smaller than one pixel per module.
+func (c *Code) PNG(w io.Writer, size int) error {
+	n := c.Size()
+	scale := size / n
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewGray(image.Rect(0, 0, n*scale, n*scale))
+	for y := 0; y < n*scale; y++ {
+		for x := 0; x < n*scale; x++ {
+			v := color.Gray{Y: 255}
+			if c.modules.Get(x/scale, y/scale) {
+				v.Y = 0
+			}
+			img.SetGray(x, y, v)
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// SVG draws the code as a scalable image, one path for all the dark modules.
+func (c *Code) SVG(w io.Writer) error {
+	n := c.Size()
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n, n, n); err != nil {
+		return err
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if !c.modules.Get(x, y) {
+				continue
+			}
+			// runs of dark modules on a row make one rectangle
+			run := 1
+			for x+run < n && c.modules.Get(x+run, y) {
+				run++
+			}
+			if _, err := fmt.Fprintf(w, "M%d %dh%dv1h-%dz", x, y, run, run); err != nil {
+				return err
+			}
+			x += run - 1
+		}
+	}
+	_, err := io.WriteString(w, `"/></svg>`)
+	return err
+}
+
+// Decode reads the text of the QR code in the image. The size of the image is checked from its
+// header before it is decoded.
+func Decode(r io.Reader) (string, error) {
+	var head bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &head))
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return "", ErrTooManyPixels
+	}
+	img, _, err := image.Decode(io.MultiReader(&head, r))
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", ErrNoCode
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+	res, err := qrcode.NewQRCodeReader().Decode(bmp, hints)
+	if err != nil {
+		return "", ErrNoCode
+	}
+	return res.GetText(), nil
+}
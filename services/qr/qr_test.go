@@ -0,0 +1,98 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPNGRoundTrip(t *testing.T) {
+	text := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Adébáyọ̀ Bello\r\nEND:VCARD\r\n"
+	code, err := Encode(text)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, code.PNG(&buf, 300))
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	side := img.Bounds().Dx()
+	assert.Equal(t, side, img.Bounds().Dy())
+	assert.LessOrEqual(t, side, 300)
+	assert.Zero(t, side%code.Size())
+	got, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, text, got)
+
+	// a photo of the code, with a margin and lossy compression
+	photo := image.NewRGBA(image.Rect(0, 0, side+200, side+150))
+	for i := range photo.Pix {
+		photo.Pix[i] = 230
+	}
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			photo.Set(x+120, y+60, img.At(x, y))
+		}
+	}
+	buf.Reset()
+	require.NoError(t, jpeg.Encode(&buf, photo, &jpeg.Options{Quality: 60}))
+	got, err = Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, text, got)
+
+	// the image is never smaller than a pixel per module
+	buf.Reset()
+	require.NoError(t, code.PNG(&buf, 10))
+	img, err = png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, code.Size(), img.Bounds().Dx())
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode("https://example.com")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, code.SVG(&buf))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 33 33"`))
+	assert.True(t, strings.HasSuffix(svg, `"/></svg>`))
+	// the finder pattern in the top left corner starts with a row of seven dark modules
+	assert.Contains(t, svg, `d="M4 4h7v1h-7z`)
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Encode(strings.Repeat("x", 3000))
+	assert.True(t, errors.Is(err, ErrTooLong))
+	_, err = Encode("")
+	assert.Error(t, err)
+
+	_, err = Decode(strings.NewReader("not an image"))
+	assert.True(t, errors.Is(err, ErrInvalidImage))
+
+	blank := image.NewGray(image.Rect(0, 0, 100, 100))
+	for i := range blank.Pix {
+		blank.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, blank))
+	_, err = Decode(&buf)
+	assert.True(t, errors.Is(err, ErrNoCode))
+
+	// a header declaring a 65535 x 65535 screen is refused before the image is decoded
+	buf.Reset()
+	require.NoError(t, gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 1, 1), palette.Plan9), nil))
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint16(data[6:], 0xffff)
+	binary.LittleEndian.PutUint16(data[8:], 0xffff)
+	_, err = Decode(bytes.NewReader(data))
+	assert.Equal(t, ErrTooManyPixels, err)
+}
@@ -0,0 +1,252 @@
+package relationship
+
+import (
+	"errors"
+
+	"grpc-contact-manager/services/contact"
+
+	"gorm.io/gorm"
+)
+
+// Relationship types. A relationship from contact A to contact B of type Manager reads
+// "B is the manager of A".
+const (
+	Spouse    = "spouse"
+	Parent    = "parent"
+	Child     = "child"
+	Manager   = "manager"
+	Report    = "report"
+	Assistant = "assistant"
+	Executive = "executive"
+	Colleague = "colleague"
+	Custom    = "custom"
+)
+
+// MaxDepth caps how far Walk follows relationships.
+const MaxDepth = 10
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+	errInvalidContactID   = errors.New("invalid contact id")
+	errSelfRelationship   = errors.New("a contact can't be related to itself")
+	errInvalidType        = errors.New("invalid relationship type")
+	errEmptyLabel         = errors.New("custom relationships need a label")
+
+	// ErrNotFound is returned when the relationship doesn't exist or belongs to another user.
+	ErrNotFound = errors.New("relationship not found")
+	// ErrExists is returned when the contacts are already linked with the same type.
+	ErrExists = errors.New("contacts are already related")
+
+	// inverses maps each type to the type of the reverse relationship.
+	inverses = map[string]string{
+		Spouse:    Spouse,
+		Parent:    Child,
+		Child:     Parent,
+		Manager:   Report,
+		Report:    Manager,
+		Assistant: Executive,
+		Executive: Assistant,
+		Colleague: Colleague,
+		Custom:    Custom,
+	}
+)
+
+// Relationship links a contact to a related contact.
+type Relationship struct {
+	gorm.Model
+	UserID    uint   `json:"user_id" gorm:"column:user_id;index"`
+	ContactID uint   `json:"contact_id" gorm:"column:contact_id;index"`
+	RelatedID uint   `json:"related_id" gorm:"column:related_id;index"`
+	Type      string `json:"type"`
+	// Label names custom relationships, for example "godmother".
+	Label string `json:"label"`
+	// InverseLabel names the reverse of a reciprocal custom relationship, for example "godchild".
+	InverseLabel string `json:"inverse_label"`
+	// Reciprocal relationships are stored in both directions and unlinked together.
+	Reciprocal bool            `json:"reciprocal"`
+	Related    contact.Contact `json:"related" gorm:"foreignKey:RelatedID"`
+}
+
+// Inverse returns the relationship seen from the related contact.
+func (r *Relationship) Inverse() Relationship {
+	label, inverseLabel := r.InverseLabel, r.Label
+	if label == "" {
+		label = r.Label
+	}
+	return Relationship{
+		UserID:       r.UserID,
+		ContactID:    r.RelatedID,
+		RelatedID:    r.ContactID,
+		Type:         inverses[r.Type],
+		Label:        label,
+		InverseLabel: inverseLabel,
+		Reciprocal:   r.Reciprocal,
+	}
+}
+
+// Node is a contact reached while walking the relationship graph.
+type Node struct {
+	Contact contact.Contact
+	// Depth is the number of relationships between the start contact and this one.
+	Depth int
+	// Via is the relationship that led to the contact.
+	Via Relationship
+}
+
+// DB - relationship repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the relationship repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the relationships table
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Relationship{})
+}
+
+// Link relates two contacts of the user, adding the reverse relationship as well when it is reciprocal.
+func (d *DB) Link(r Relationship) (*Relationship, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	r.Related = contact.Contact{}
+	err := d.Conn.Transaction(func(tx *gorm.DB) error {
+		var owned int64
+		if err := tx.Model(&contact.Contact{}).Where("user_id = ? AND id IN ?", r.UserID, []uint{r.ContactID, r.RelatedID}).Count(&owned).Error; err != nil {
+			return err
+		}
+		if owned != 2 {
+			return errInvalidContactID
+		}
+
+		edges := []Relationship{r}
+		if r.Reciprocal {
+			edges = append(edges, r.Inverse())
+		}
+		for i := range edges {
+			var exists int64
+			err := tx.Model(&Relationship{}).
+				Where("user_id = ? AND contact_id = ? AND related_id = ? AND type = ? AND label = ?", r.UserID, edges[i].ContactID, edges[i].RelatedID, edges[i].Type, edges[i].Label).
+				Count(&exists).Error
+			if err != nil {
+				return err
+			}
+			if exists > 0 {
+				return ErrExists
+			}
+			if err := tx.Omit("Related").Create(&edges[i]).Error; err != nil {
+				return err
+			}
+		}
+		r = edges[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Unlink removes a relationship, and its reverse when the relationship is reciprocal.
+func (d *DB) Unlink(userID, id uint) (*Relationship, error) {
+	var r Relationship
+	err := d.Conn.Where("user_id = ?", userID).First(&r, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&r).Error; err != nil {
+			return err
+		}
+		if !r.Reciprocal {
+			return nil
+		}
+		inverse := r.Inverse()
+		return tx.Where("user_id = ? AND contact_id = ? AND related_id = ? AND type = ? AND label = ?", userID, inverse.ContactID, inverse.RelatedID, inverse.Type, inverse.Label).
+			Delete(&Relationship{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// FindByContact returns the relationships of the contact with the related contacts loaded. Deleted
+// contacts are left out, and their relationships come back if they are restored.
+func (d *DB) FindByContact(userID, contactID uint) ([]Relationship, error) {
+	var relationships []Relationship
+	res := d.Conn.Preload("Related").Scopes(liveRelated).Where("user_id = ? AND contact_id = ?", userID, contactID).Order("id").Find(&relationships)
+	return relationships, res.Error
+}
+
+// Walk follows relationships of the given types outwards from the contact, breadth first, up to
+// depth hops away. Every contact is returned once, at the depth it was first reached. All types
+// are followed when none are given. Deleted contacts are neither returned nor walked through.
+func (d *DB) Walk(userID, contactID uint, depth int, types ...string) ([]Node, error) {
+	if depth <= 0 {
+		depth = 1
+	}
+	if depth > MaxDepth {
+		depth = MaxDepth
+	}
+	seen := map[uint]bool{contactID: true}
+	frontier := []uint{contactID}
+	var nodes []Node
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		q := d.Conn.Preload("Related").Scopes(liveRelated).Where("user_id = ? AND contact_id IN ?", userID, frontier)
+		if len(types) > 0 {
+			q = q.Where("type IN ?", types)
+		}
+		var edges []Relationship
+		if err := q.Order("id").Find(&edges).Error; err != nil {
+			return nil, err
+		}
+		frontier = frontier[:0]
+		for _, e := range edges {
+			if seen[e.RelatedID] {
+				continue
+			}
+			seen[e.RelatedID] = true
+			frontier = append(frontier, e.RelatedID)
+			related := e.Related
+			e.Related = contact.Contact{}
+			nodes = append(nodes, Node{Contact: related, Depth: level, Via: e})
+		}
+	}
+	return nodes, nil
+}
+
+// liveRelated keeps the relationships whose related contact isn't deleted.
+func liveRelated(db *gorm.DB) *gorm.DB {
+	return db.Where("related_id IN (SELECT id FROM contacts WHERE deleted_at IS NULL)")
+}
+
+func (r *Relationship) validate() error {
+	if r.UserID == 0 {
+		return errInvalidUserID
+	}
+	if r.ContactID == 0 || r.RelatedID == 0 {
+		return errInvalidContactID
+	}
+	if r.ContactID == r.RelatedID {
+		return errSelfRelationship
+	}
+	if _, ok := inverses[r.Type]; !ok {
+		return errInvalidType
+	}
+	if r.Type == Custom && r.Label == "" {
+		return errEmptyLabel
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package relationship
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db        *DB
+	contactDB *contact.DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := contact.New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, contactDB = d, c
+	if err := contactDB.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestValidate(t *testing.T) {
+	table := []struct {
+		name         string
+		relationship Relationship
+		want         error
+	}{
+		{
+			name:         "All good",
+			relationship: Relationship{UserID: 1, ContactID: 1, RelatedID: 2, Type: Spouse},
+			want:         nil,
+		},
+		{
+			name:         "Invalid User",
+			relationship: Relationship{ContactID: 1, RelatedID: 2, Type: Spouse},
+			want:         errInvalidUserID,
+		},
+		{
+			name:         "Missing Related",
+			relationship: Relationship{UserID: 1, ContactID: 1, Type: Spouse},
+			want:         errInvalidContactID,
+		},
+		{
+			name:         "Self",
+			relationship: Relationship{UserID: 1, ContactID: 1, RelatedID: 1, Type: Spouse},
+			want:         errSelfRelationship,
+		},
+		{
+			name:         "Unknown Type",
+			relationship: Relationship{UserID: 1, ContactID: 1, RelatedID: 2, Type: "nemesis"},
+			want:         errInvalidType,
+		},
+		{
+			name:         "Custom Without Label",
+			relationship: Relationship{UserID: 1, ContactID: 1, RelatedID: 2, Type: Custom},
+			want:         errEmptyLabel,
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.relationship.validate())
+		})
+	}
+}
+
+func TestLinkReciprocal(t *testing.T) {
+	c := createContacts(t, 1, 2)
+	jane, john := c[0], c[1]
+
+	r, err := db.Link(Relationship{UserID: 1, ContactID: jane.ID, RelatedID: john.ID, Type: Manager, Reciprocal: true})
+	require.NoError(t, err)
+	assert.NotZero(t, r.ID)
+
+	_, err = db.Link(Relationship{UserID: 1, ContactID: jane.ID, RelatedID: john.ID, Type: Manager})
+	assert.Equal(t, ErrExists, err)
+
+	janes, err := db.FindByContact(1, jane.ID)
+	require.NoError(t, err)
+	require.Len(t, janes, 1)
+	assert.Equal(t, john.Fullname, janes[0].Related.Fullname)
+
+	johns, err := db.FindByContact(1, john.ID)
+	require.NoError(t, err)
+	require.Len(t, johns, 1)
+	assert.Equal(t, Report, johns[0].Type)
+	assert.Equal(t, jane.ID, johns[0].RelatedID)
+
+	// unlinking removes both directions
+	_, err = db.Unlink(1, r.ID)
+	require.NoError(t, err)
+	johns, err = db.FindByContact(1, john.ID)
+	require.NoError(t, err)
+	assert.Empty(t, johns)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestLinkOtherUsersContact(t *testing.T) {
+	mine := createContacts(t, 1, 1)
+	theirs := createContacts(t, 2, 1)
+
+	_, err := db.Link(Relationship{UserID: 1, ContactID: mine[0].ID, RelatedID: theirs[0].ID, Type: Colleague})
+	assert.Equal(t, errInvalidContactID, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestCustomInverse(t *testing.T) {
+	r := Relationship{UserID: 1, ContactID: 1, RelatedID: 2, Type: Custom, Label: "godmother", InverseLabel: "godchild"}
+	inverse := r.Inverse()
+	assert.Equal(t, uint(2), inverse.ContactID)
+	assert.Equal(t, Custom, inverse.Type)
+	assert.Equal(t, "godchild", inverse.Label)
+	assert.Equal(t, "godmother", inverse.InverseLabel)
+}
+
+func TestWalk(t *testing.T) {
+	// jane manages john and mary, john manages peter, peter is married to ann
+	c := createContacts(t, 1, 5)
+	jane, john, mary, peter, ann := c[0], c[1], c[2], c[3], c[4]
+	link(t, jane.ID, john.ID, Report)
+	link(t, jane.ID, mary.ID, Report)
+	link(t, john.ID, peter.ID, Report)
+	link(t, peter.ID, ann.ID, Spouse)
+	// a cycle back to the start is ignored
+	link(t, peter.ID, jane.ID, Colleague)
+
+	team, err := db.Walk(1, jane.ID, 1, Report)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint{john.ID, mary.ID}, ids(team))
+
+	team, err = db.Walk(1, jane.ID, 5, Report)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint{john.ID, mary.ID, peter.ID}, ids(team))
+	for _, n := range team {
+		if n.Contact.ID == peter.ID {
+			assert.Equal(t, 2, n.Depth)
+			assert.Equal(t, john.ID, n.Via.ContactID)
+		}
+	}
+
+	everyone, err := db.Walk(1, jane.ID, 5)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint{john.ID, mary.ID, peter.ID, ann.ID}, ids(everyone))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestDeletedRelatedContact(t *testing.T) {
+	c := createContacts(t, 1, 3)
+	jane, john, peter := c[0], c[1], c[2]
+	link(t, jane.ID, john.ID, Report)
+	link(t, john.ID, peter.ID, Report)
+	_, err := contactDB.Delete(1, john.ID)
+	require.NoError(t, err)
+
+	relationships, err := db.FindByContact(1, jane.ID)
+	require.NoError(t, err)
+	assert.Empty(t, relationships)
+	team, err := db.Walk(1, jane.ID, 5)
+	require.NoError(t, err)
+	assert.Empty(t, team)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func link(t *testing.T, from, to uint, relationshipType string) {
+	_, err := db.Link(Relationship{UserID: 1, ContactID: from, RelatedID: to, Type: relationshipType})
+	require.NoError(t, err)
+}
+
+func ids(nodes []Node) []uint {
+	res := make([]uint, len(nodes))
+	for i, n := range nodes {
+		res[i] = n.Contact.ID
+	}
+	return res
+}
+
+func createContacts(t *testing.T, userID uint, n int) []contact.Contact {
+	contacts := make([]contact.Contact, n)
+	for i := range contacts {
+		c, err := contactDB.Create(contact.Contact{
+			UserID:   userID,
+			Fullname: fmt.Sprintf("Contact %d-%d", userID, i),
+			Email:    fmt.Sprintf("contact%d@example.com", i),
+			Phone:    "+2347033304280",
+			Address:  "33, Tioya Street, Ibadan",
+		})
+		require.NoError(t, err)
+		contacts[i] = *c
+	}
+	return contacts
+}
+
+func cleanup() error {
+	for _, table := range []string{"relationships", "revisions", "contacts"} {
+		if err := db.Conn.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
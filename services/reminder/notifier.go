@@ -0,0 +1,87 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Notifier delivers due reminders to the user.
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+}
+
+// LogNotifier writes due reminders to the application log.
+type LogNotifier struct{}
+
+// Notify logs the reminder.
+func (LogNotifier) Notify(ctx context.Context, r Reminder) error {
+	log.WithFields(log.Fields{
+		"reminder_id": r.ID,
+		"user_id":     r.UserID,
+		"contact_id":  r.ContactID,
+		"due_at":      r.DueAt,
+	}).Info(r.Note)
+	return nil
+}
+
+// WebhookNotifier posts due reminders as JSON to a URL. Any non 2xx response is treated as a failed delivery.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with a bounded request timeout.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// webhookPayload is the body sent to the webhook.
+type webhookPayload struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	ContactID uint      `json:"contact_id"`
+	DueAt     time.Time `json:"due_at"`
+	Note      string    `json:"note"`
+	Attempt   int       `json:"attempt"`
+}
+
+// Notify posts the reminder to the webhook URL.
+func (w *WebhookNotifier) Notify(ctx context.Context, r Reminder) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		ContactID: r.ContactID,
+		DueAt:     r.DueAt,
+		Note:      r.Note,
+		Attempt:   r.Attempts,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// receivers can use the key to drop repeated deliveries of the same occurrence
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("reminder-%d-%d", r.ID, r.DueAt.Unix()))
+
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
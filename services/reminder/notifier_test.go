@@ -0,0 +1,40 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NotEmpty(t, r.Header.Get("Idempotency-Key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	r := Reminder{UserID: 1, ContactID: 2, DueAt: time.Now(), Note: "Call Jane"}
+	r.ID = 3
+	require.NoError(t, NewWebhookNotifier(srv.URL).Notify(context.Background(), r))
+	assert.Equal(t, uint(3), got.ID)
+	assert.Equal(t, "Call Jane", got.Note)
+}
+
+func TestWebhookNotifierFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookNotifier(srv.URL).Notify(context.Background(), Reminder{})
+	assert.EqualError(t, err, "webhook responded with status 502")
+}
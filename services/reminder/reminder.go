@@ -0,0 +1,261 @@
+package reminder
+
+import (
+	"errors"
+	"time"
+
+	"grpc-contact-manager/services/lease"
+
+	"gorm.io/gorm"
+)
+
+// Repeat rules
+const (
+	Never   = ""
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+	errInvalidContactID   = errors.New("invalid contact id")
+	errNoDueTime          = errors.New("due time must be provided")
+	errInvalidRepeat      = errors.New("repeat must be one of daily, weekly, monthly or yearly")
+	errInvalidInterval    = errors.New("repeat interval must not be negative")
+
+	// ErrNotFound is returned when the reminder doesn't exist or belongs to another user.
+	ErrNotFound = errors.New("reminder not found")
+
+	repeatRules = map[string]bool{
+		Never:   true,
+		Daily:   true,
+		Weekly:  true,
+		Monthly: true,
+		Yearly:  true,
+	}
+)
+
+// Reminder is a follow up due on a contact. Repeating reminders move to their next
+// due time once delivered, the others are marked done.
+type Reminder struct {
+	gorm.Model
+	UserID         uint       `json:"user_id" gorm:"column:user_id;index"`
+	ContactID      uint       `json:"contact_id" gorm:"column:contact_id;index"`
+	DueAt          time.Time  `json:"due_at" gorm:"index"`
+	Repeat         string     `json:"repeat"`
+	RepeatInterval int        `json:"repeat_interval"`
+	Note           string     `json:"note"`
+	Done           bool       `json:"done" gorm:"index"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	// LockedUntil is set while a scheduler delivers the reminder. A scheduler that dies
+	// mid delivery leaves the lock to expire, so the reminder is delivered again.
+	LockedUntil *time.Time `json:"-" gorm:"index"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error"`
+}
+
+// Next returns the first occurrence of the repeat rule after the given time, or the zero time for one-off reminders.
+func (r *Reminder) Next(after time.Time) time.Time {
+	if r.Repeat == Never {
+		return time.Time{}
+	}
+	interval := r.RepeatInterval
+	if interval == 0 {
+		interval = 1
+	}
+	next := r.DueAt
+	for !next.After(after) {
+		switch r.Repeat {
+		case Daily:
+			next = next.AddDate(0, 0, interval)
+		case Weekly:
+			next = next.AddDate(0, 0, 7*interval)
+		case Monthly:
+			next = next.AddDate(0, interval, 0)
+		case Yearly:
+			next = next.AddDate(interval, 0, 0)
+		default:
+			return time.Time{}
+		}
+	}
+	return next
+}
+
+// DB - reminder repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the reminder repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the reminders table
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Reminder{})
+}
+
+// Create adds a new reminder.
+func (d *DB) Create(r Reminder) (*Reminder, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	r.Done, r.DeliveredAt, r.LockedUntil, r.Attempts, r.LastError = false, nil, nil, 0, ""
+	return &r, d.Conn.Create(&r).Error
+}
+
+// Find returns the reminder with the given ID.
+func (d *DB) Find(userID, id uint) (*Reminder, error) {
+	var r Reminder
+	err := d.Conn.Where("user_id = ?", userID).First(&r, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// FindByUserID returns the reminders of the user ordered by due time, optionally limited to a contact.
+func (d *DB) FindByUserID(userID, contactID uint) ([]Reminder, error) {
+	var reminders []Reminder
+	q := d.Conn.Where("user_id = ?", userID)
+	if contactID != 0 {
+		q = q.Where("contact_id = ?", contactID)
+	}
+	return reminders, q.Order("due_at").Find(&reminders).Error
+}
+
+// Update changes the schedule and note of a reminder. Rescheduling a done reminder makes it pending
+// again, and a claimed one is released so the delivery in flight doesn't count for the new schedule.
+func (d *DB) Update(r *Reminder) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	r.Done, r.LockedUntil, r.Attempts, r.LastError = false, nil, 0, ""
+	return d.Conn.Model(r).Updates(map[string]interface{}{
+		"due_at":          r.DueAt,
+		"repeat":          r.Repeat,
+		"repeat_interval": r.RepeatInterval,
+		"note":            r.Note,
+		"done":            false,
+		"locked_until":    nil,
+		"attempts":        0,
+		"last_error":      "",
+	}).Error
+}
+
+// Delete removes a reminder.
+func (d *DB) Delete(userID, id uint) (*Reminder, error) {
+	r, err := d.Find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return r, d.Conn.Delete(r).Error
+}
+
+// Claim locks up to limit due reminders for delivery for the hold duration. Reminders locked
+// by another scheduler are skipped, so several instances can run side by side.
+func (d *DB) Claim(now time.Time, hold time.Duration, limit int) ([]Reminder, error) {
+	var due []Reminder
+	err := d.Conn.Scopes(lease.Available(now)).Where("done = ? AND due_at <= ?", false, now).
+		Order("due_at").Limit(limit).Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	until := lease.Until(now, hold)
+	claimed := due[:0]
+	for _, r := range due {
+		ok, err := lease.Claim(d.Conn.Model(&Reminder{}), r.ID, now, until)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.LockedUntil = &until
+			r.Attempts++
+			claimed = append(claimed, r)
+		}
+	}
+	return claimed, nil
+}
+
+// Renew extends the locks of the claimed reminders until until. A reminder whose lock was
+// claimed by another scheduler since, or that was rescheduled, is no longer held: its
+// LockedUntil is cleared so the caller skips it.
+func (d *DB) Renew(claimed []Reminder, until time.Time) error {
+	for i := range claimed {
+		r := &claimed[i]
+		if r.LockedUntil == nil {
+			continue
+		}
+		held, err := lease.Renew(d.claimed(r), *r.LockedUntil, until)
+		if err != nil {
+			return err
+		}
+		if held {
+			r.LockedUntil = &until
+		} else {
+			r.LockedUntil = nil
+		}
+	}
+	return nil
+}
+
+// Delivered marks a claimed reminder as delivered, moving repeating reminders to their next due time.
+// Nothing changes when the reminder was rescheduled since it was claimed.
+func (d *DB) Delivered(r *Reminder, at time.Time) error {
+	updates := map[string]interface{}{
+		"delivered_at": at,
+		"locked_until": nil,
+		"attempts":     0,
+		"last_error":   "",
+	}
+	if next := r.Next(at); !next.IsZero() {
+		updates["due_at"] = next
+	} else {
+		updates["done"] = true
+	}
+	return d.claimed(r).Updates(updates).Error
+}
+
+// Failed releases a claimed reminder so it is retried at or after retryAt, unless it was
+// rescheduled since it was claimed.
+func (d *DB) Failed(r *Reminder, cause error, retryAt time.Time) error {
+	return d.claimed(r).Updates(map[string]interface{}{
+		"locked_until": retryAt,
+		"last_error":   cause.Error(),
+	}).Error
+}
+
+// claimed scopes an update to the reminder as it was when claimed.
+func (d *DB) claimed(r *Reminder) *gorm.DB {
+	return d.Conn.Model(&Reminder{}).Where("id = ? AND due_at = ?", r.ID, r.DueAt)
+}
+
+func (r *Reminder) validate() error {
+	if r.UserID == 0 {
+		return errInvalidUserID
+	}
+	if r.ContactID == 0 {
+		return errInvalidContactID
+	}
+	if r.DueAt.IsZero() {
+		return errNoDueTime
+	}
+	if !repeatRules[r.Repeat] {
+		return errInvalidRepeat
+	}
+	if r.RepeatInterval < 0 {
+		return errInvalidInterval
+	}
+	return nil
+}
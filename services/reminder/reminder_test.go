@@ -0,0 +1,194 @@
+package reminder
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db *DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db = d
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestValidate(t *testing.T) {
+	due := time.Now().Add(time.Hour)
+	table := []struct {
+		name     string
+		reminder Reminder
+		want     error
+	}{
+		{
+			name:     "All good",
+			reminder: Reminder{UserID: 1, ContactID: 1, DueAt: due, Repeat: Weekly, Note: "Call Jane"},
+			want:     nil,
+		},
+		{
+			name:     "Invalid User",
+			reminder: Reminder{ContactID: 1, DueAt: due},
+			want:     errInvalidUserID,
+		},
+		{
+			name:     "Invalid Contact",
+			reminder: Reminder{UserID: 1, DueAt: due},
+			want:     errInvalidContactID,
+		},
+		{
+			name:     "No Due Time",
+			reminder: Reminder{UserID: 1, ContactID: 1},
+			want:     errNoDueTime,
+		},
+		{
+			name:     "Invalid Repeat",
+			reminder: Reminder{UserID: 1, ContactID: 1, DueAt: due, Repeat: "hourly"},
+			want:     errInvalidRepeat,
+		},
+		{
+			name:     "Negative Interval",
+			reminder: Reminder{UserID: 1, ContactID: 1, DueAt: due, Repeat: Daily, RepeatInterval: -1},
+			want:     errInvalidInterval,
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.reminder.validate())
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	due := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+	after := due.Add(time.Minute)
+	table := []struct {
+		name     string
+		repeat   string
+		interval int
+		want     time.Time
+	}{
+		{name: "Never", repeat: Never, want: time.Time{}},
+		{name: "Daily", repeat: Daily, want: due.AddDate(0, 0, 1)},
+		{name: "Every 2 Weeks", repeat: Weekly, interval: 2, want: due.AddDate(0, 0, 14)},
+		{name: "Monthly", repeat: Monthly, want: due.AddDate(0, 1, 0)},
+		{name: "Yearly", repeat: Yearly, want: due.AddDate(1, 0, 0)},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Reminder{DueAt: due, Repeat: tt.repeat, RepeatInterval: tt.interval}
+			assert.Equal(t, tt.want, r.Next(after))
+		})
+	}
+
+	// missed occurrences are skipped
+	r := Reminder{DueAt: due, Repeat: Daily}
+	assert.Equal(t, due.AddDate(0, 0, 10), r.Next(due.AddDate(0, 0, 9).Add(time.Minute)))
+}
+
+func TestCreateFindUpdateDelete(t *testing.T) {
+	r, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: time.Now().Add(time.Hour), Note: "Call Jane"})
+	require.NoError(t, err)
+
+	_, err = db.Find(2, r.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	r.Note = "Call Jane about the renewal"
+	r.Repeat = Monthly
+	require.NoError(t, db.Update(r))
+
+	found, err := db.Find(1, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Call Jane about the renewal", found.Note)
+	assert.Equal(t, Monthly, found.Repeat)
+
+	list, err := db.FindByUserID(1, 1)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = db.Delete(1, r.ID)
+	require.NoError(t, err)
+	_, err = db.Find(1, r.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestClaim(t *testing.T) {
+	now := time.Now()
+	due, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	_, err = db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	claimed, err := db.Claim(now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// a second scheduler doesn't get the locked reminder
+	claimed, err = db.Claim(now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// until the lease expires
+	claimed, err = db.Claim(now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestRescheduleClaimed(t *testing.T) {
+	now := time.Now()
+	r, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	claimed, err := db.Claim(now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	// the user moves the reminder while its delivery is in flight
+	r.DueAt = now.Add(-time.Second)
+	require.NoError(t, db.Update(r))
+	require.NoError(t, db.Delivered(&claimed[0], now))
+
+	found, err := db.Find(1, r.ID)
+	require.NoError(t, err)
+	assert.False(t, found.Done)
+	assert.Nil(t, found.DeliveredAt)
+	assert.Nil(t, found.LockedUntil)
+	claimed, err = db.Claim(now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func cleanup() error {
+	return db.Conn.Exec("DELETE FROM reminders").Error
+}
@@ -0,0 +1,107 @@
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"grpc-contact-manager/services/lease"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval is how often the scheduler looks for due reminders.
+	DefaultInterval = 30 * time.Second
+	// DefaultLease is how long a claimed reminder stays locked while being delivered. The
+	// reminders of a batch are locked again once half of it is spent, and a delivery is cut
+	// off after half of it.
+	DefaultLease = 2 * time.Minute
+	// DefaultBatchSize is the number of reminders claimed per poll.
+	DefaultBatchSize = 100
+	// maxBackoff caps the delay between retries of a failing delivery.
+	maxBackoff = time.Hour
+)
+
+// Scheduler delivers due reminders through a notifier. The delivery state lives in the
+// database, so reminders that came due while the service was down are delivered on start
+// and a reminder is only marked delivered after the notifier succeeded.
+type Scheduler struct {
+	DB        *DB
+	Notifier  Notifier
+	Interval  time.Duration
+	Lease     time.Duration
+	BatchSize int
+	now       func() time.Time
+}
+
+// NewScheduler creates a scheduler with the default interval, lease and batch size.
+func NewScheduler(db *DB, notifier Notifier) *Scheduler {
+	return &Scheduler{
+		DB:        db,
+		Notifier:  notifier,
+		Interval:  DefaultInterval,
+		Lease:     DefaultLease,
+		BatchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// Run delivers due reminders until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx); err != nil {
+			log.WithError(err).Error("delivering reminders")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and delivers the reminders that are due now and returns how many were delivered.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.DB.Claim(now, s.Lease, s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	until := lease.Until(now, s.Lease)
+	delivered := 0
+	for i := range due {
+		// slow deliveries must not let another instance claim the rest of the batch
+		if at := s.now(); at.After(until.Add(-s.Lease / 2)) {
+			until = lease.Until(at, s.Lease)
+			if err := s.DB.Renew(due[i:], until); err != nil {
+				return delivered, err
+			}
+		}
+		r := &due[i]
+		if r.LockedUntil == nil {
+			continue
+		}
+		if err := s.notify(ctx, r); err != nil {
+			log.WithError(err).WithField("reminder_id", r.ID).Warn("reminder delivery failed")
+			if err := s.DB.Failed(r, err, now.Add(lease.Backoff(r.Attempts, maxBackoff))); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := s.DB.Delivered(r, s.now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// notify delivers the reminder within half of the lease, so the reminders after it are
+// renewed before their locks expire.
+func (s *Scheduler) notify(ctx context.Context, r *Reminder) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Lease/2)
+	defer cancel()
+	return s.Notifier.Notify(ctx, *r)
+}
@@ -0,0 +1,150 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	delivered []Reminder
+	err       error
+	// slow is run by every delivery
+	slow func()
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, r Reminder) error {
+	if f.slow != nil {
+		f.slow()
+	}
+	if f.err != nil {
+		return f.err
+	}
+	f.delivered = append(f.delivered, r)
+	return nil
+}
+
+func TestSchedulerDelivers(t *testing.T) {
+	now := time.Now()
+	once, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute), Note: "Call Jane"})
+	require.NoError(t, err)
+	weekly, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute), Repeat: Weekly, Note: "Weekly sync"})
+	require.NoError(t, err)
+
+	n := &fakeNotifier{}
+	s := NewScheduler(db, n)
+	s.now = func() time.Time { return now }
+
+	delivered, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Len(t, n.delivered, 2)
+
+	found, err := db.Find(1, once.ID)
+	require.NoError(t, err)
+	assert.True(t, found.Done)
+	assert.NotNil(t, found.DeliveredAt)
+
+	found, err = db.Find(1, weekly.ID)
+	require.NoError(t, err)
+	assert.False(t, found.Done)
+	assert.True(t, found.DueAt.After(now))
+
+	// nothing is due anymore
+	delivered, err = s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestSchedulerRetriesFailedDelivery(t *testing.T) {
+	now := time.Now()
+	r, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute), Note: "Call Jane"})
+	require.NoError(t, err)
+
+	n := &fakeNotifier{err: errors.New("webhook down")}
+	s := NewScheduler(db, n)
+	s.now = func() time.Time { return now }
+
+	delivered, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	found, err := db.Find(1, r.ID)
+	require.NoError(t, err)
+	assert.False(t, found.Done)
+	assert.Equal(t, "webhook down", found.LastError)
+
+	// the retry waits for the backoff
+	n.err = nil
+	delivered, err = s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	s.now = func() time.Time { return now.Add(2 * time.Minute) }
+	delivered, err = s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestSchedulerRedeliversAfterCrash(t *testing.T) {
+	now := time.Now()
+	_, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute), Note: "Call Jane"})
+	require.NoError(t, err)
+
+	// a scheduler claims the reminder and dies before recording the delivery
+	_, err = db.Claim(now, DefaultLease, DefaultBatchSize)
+	require.NoError(t, err)
+
+	n := &fakeNotifier{}
+	s := NewScheduler(db, n)
+	s.now = func() time.Time { return now.Add(DefaultLease + time.Second) }
+	delivered, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Len(t, n.delivered, 1)
+	assert.Equal(t, 2, n.delivered[0].Attempts)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestSchedulerRenewsLease(t *testing.T) {
+	now := time.Now()
+	for _, note := range []string{"Call Jane", "Call John", "Call Joan"} {
+		_, err := db.Create(Reminder{UserID: 1, ContactID: 1, DueAt: now.Add(-time.Minute), Note: note})
+		require.NoError(t, err)
+	}
+
+	// every delivery takes most of the lease, and another instance polls meanwhile
+	clock := now
+	n := &fakeNotifier{}
+	n.slow = func() {
+		clock = clock.Add(DefaultLease * 3 / 4)
+		claimed, err := db.Claim(clock, DefaultLease, DefaultBatchSize)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	}
+	s := NewScheduler(db, n)
+	s.now = func() time.Time { return clock }
+
+	delivered, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
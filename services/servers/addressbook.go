@@ -0,0 +1,96 @@
+package servers
+
+import (
+	"context"
+	"errors"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/addressbook"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddAddressBook subscribes the user to an external address book, synced right away.
+func (c *ContactManagerGrpc) AddAddressBook(ctx context.Context, in *pb.AddressBook) (*pb.AddressBook, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.AddressBooks.Create(addressbook.Source{
+		UserID:    userID,
+		Name:      in.Name,
+		URL:       in.Url,
+		Username:  in.Username,
+		Password:  in.Password,
+		Conflicts: in.Conflicts,
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toPBAddressBook(s), nil
+}
+
+func (c *ContactManagerGrpc) ListAddressBooks(ctx context.Context, in *pb.AddressBookRequest) (*pb.AddressBookList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := c.AddressBooks.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.AddressBookList{}
+	for i := range sources {
+		res.AddressBooks = append(res.AddressBooks, toPBAddressBook(&sources[i]))
+	}
+	return res, nil
+}
+
+func (c *ContactManagerGrpc) DeleteAddressBook(ctx context.Context, in *pb.AddressBook) (*pb.AddressBook, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.AddressBooks.Delete(userID, uint(in.Id))
+	if err != nil {
+		return nil, addressBookError(err)
+	}
+	return toPBAddressBook(s), nil
+}
+
+func (c *ContactManagerGrpc) SyncAddressBook(ctx context.Context, in *pb.AddressBook) (*pb.AddressBook, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.AddressBooks.SyncNow(userID, uint(in.Id))
+	if err != nil {
+		return nil, addressBookError(err)
+	}
+	return toPBAddressBook(s), nil
+}
+
+func addressBookError(err error) error {
+	if errors.Is(err, addressbook.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+// toPBAddressBook converts the address book, leaving out its password.
+func toPBAddressBook(s *addressbook.Source) *pb.AddressBook {
+	res := &pb.AddressBook{
+		Id:         int32(s.ID),
+		Name:       s.Name,
+		Url:        s.URL,
+		Username:   s.Username,
+		Conflicts:  s.Conflicts,
+		NextSyncAt: s.NextSyncAt.Unix(),
+		LastError:  s.LastError,
+	}
+	if s.LastSyncedAt != nil {
+		res.LastSyncedAt = s.LastSyncedAt.Unix()
+	}
+	return res
+}
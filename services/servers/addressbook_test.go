@@ -0,0 +1,55 @@
+package servers
+
+import (
+	"context"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAddressBooks(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+	t.Cleanup(func() {
+		require.NoError(t, server.Conn.Exec("DELETE FROM sources").Error)
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+
+	book, err := contactgrpc.AddAddressBook(ctx, &pb.AddressBook{
+		Url:      "https://dav.example.com/remote.php/dav/",
+		Username: "tola",
+		Password: "app-password",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "dav.example.com", book.Name)
+	assert.Equal(t, "remote", book.Conflicts)
+	assert.Empty(t, book.Password)
+
+	_, err = contactgrpc.AddAddressBook(ctx, &pb.AddressBook{Url: "dav.example.com"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = contactgrpc.AddAddressBook(ctx, &pb.AddressBook{Url: "https://dav.example.com/", Conflicts: "newest"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := contactgrpc.ListAddressBooks(ctx, &pb.AddressBookRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.AddressBooks, 1)
+	assert.Equal(t, book.Id, list.AddressBooks[0].Id)
+
+	_, err = contactgrpc.SyncAddressBook(ctx, &pb.AddressBook{Id: book.Id})
+	require.NoError(t, err)
+
+	other := middlewares.ContextWithUserID(context.Background(), userID+1)
+	_, err = contactgrpc.DeleteAddressBook(other, &pb.AddressBook{Id: book.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = contactgrpc.DeleteAddressBook(ctx, &pb.AddressBook{Id: book.Id})
+	require.NoError(t, err)
+	list, err = contactgrpc.ListAddressBooks(ctx, &pb.AddressBookRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.AddressBooks)
+}
@@ -0,0 +1,184 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/attachment"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StartAttachmentUpload registers an upload for a file attached to one of the user's contacts.
+func (c *ContactManagerGrpc) StartAttachmentUpload(ctx context.Context, in *pb.AttachmentUploadRequest) (*pb.AttachmentUpload, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := c.Attachments.StartUpload(attachment.Upload{
+		UserID:      found.UserID,
+		ContactID:   found.ID,
+		Name:        in.Name,
+		ContentType: in.ContentType,
+		Size:        in.Size,
+	})
+	if err != nil {
+		return nil, attachmentError(err)
+	}
+	return toPBUpload(u, nil), nil
+}
+
+// GetAttachmentUpload returns the progress of an upload so an interrupted client knows where to resume.
+func (c *ContactManagerGrpc) GetAttachmentUpload(ctx context.Context, in *pb.AttachmentUpload) (*pb.AttachmentUpload, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u, err := c.Attachments.FindUpload(userID, uint(in.Id))
+	if err != nil {
+		return nil, attachmentError(err)
+	}
+	return toPBUpload(u, nil), nil
+}
+
+// UploadAttachment receives chunks of a started upload. Every chunk is persisted as it
+// arrives, so a broken stream loses at most the chunk in flight. An empty chunk at the end of
+// an upload that failed to complete completes it again.
+func (c *ContactManagerGrpc) UploadAttachment(stream pb.ContactManager_UploadAttachmentServer) error {
+	userID, err := authUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+	var (
+		u *attachment.Upload
+		a *attachment.Attachment
+	)
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		u, a, err = c.Attachments.WriteChunk(userID, uint(chunk.UploadID), chunk.Offset, chunk.Data)
+		if err != nil {
+			return attachmentError(err)
+		}
+	}
+	if u == nil {
+		return status.Error(codes.InvalidArgument, "no chunks received")
+	}
+	return stream.SendAndClose(toPBUpload(u, a))
+}
+
+func (c *ContactManagerGrpc) ListAttachments(ctx context.Context, in *pb.AttachmentRequest) (*pb.AttachmentList, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := c.Attachments.FindByContact(found.UserID, found.ID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := c.Attachments.Usage(found.UserID)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.AttachmentList{
+		Usage: usage,
+		Quota: c.Attachments.Quota,
+	}
+	for i := range attachments {
+		res.Attachments = append(res.Attachments, toPBAttachment(&attachments[i]))
+	}
+	return res, nil
+}
+
+// DownloadAttachment streams the attachment content in chunks.
+func (c *ContactManagerGrpc) DownloadAttachment(in *pb.AttachmentRequest, stream pb.ContactManager_DownloadAttachmentServer) error {
+	userID, err := authUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+	a, err := c.Attachments.Find(userID, uint(in.Id))
+	if err != nil {
+		return attachmentError(err)
+	}
+	r, err := c.Attachments.Open(a)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	buf := make([]byte, attachment.MaxChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if err := stream.Send(&pb.AttachmentChunk{Offset: offset, Data: buf[:n]}); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *ContactManagerGrpc) DeleteAttachment(ctx context.Context, in *pb.AttachmentRequest) (*pb.Attachment, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a, err := c.Attachments.Delete(userID, uint(in.Id))
+	if err != nil {
+		return nil, attachmentError(err)
+	}
+	return toPBAttachment(a), nil
+}
+
+// attachmentError maps attachment repository errors to gRPC status codes.
+func attachmentError(err error) error {
+	switch {
+	case errors.Is(err, attachment.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, attachment.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, attachment.ErrOffsetMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func toPBUpload(u *attachment.Upload, a *attachment.Attachment) *pb.AttachmentUpload {
+	res := &pb.AttachmentUpload{
+		Id:        int32(u.ID),
+		ContactID: int32(u.ContactID),
+		Offset:    u.Offset,
+		Size:      u.Size,
+	}
+	if a != nil {
+		res.Attachment = toPBAttachment(a)
+	}
+	return res
+}
+
+func toPBAttachment(a *attachment.Attachment) *pb.Attachment {
+	return &pb.Attachment{
+		Id:          int32(a.ID),
+		ContactID:   int32(a.ContactID),
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Sha256:      a.SHA256,
+		CreatedAt:   a.CreatedAt.Unix(),
+	}
+}
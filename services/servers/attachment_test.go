@@ -0,0 +1,82 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAttachments(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+
+	data := bytes.Repeat([]byte("business card scan "), 100)
+	u, err := cm.StartAttachmentUpload(ctx, &pb.AttachmentUploadRequest{
+		ContactID:   int32(c.ID),
+		Name:        "card.txt",
+		ContentType: "text/plain",
+		Size:        int64(len(data)),
+	})
+	require.NoError(t, err)
+
+	// the first stream is interrupted after one chunk
+	stream, err := cm.UploadAttachment(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.AttachmentChunk{UploadID: u.Id, Offset: 0, Data: data[:500]}))
+	progress, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), progress.Offset)
+	assert.Nil(t, progress.Attachment)
+
+	progress, err = cm.GetAttachmentUpload(ctx, &pb.AttachmentUpload{Id: u.Id})
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), progress.Offset)
+
+	stream, err = cm.UploadAttachment(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.AttachmentChunk{UploadID: u.Id, Offset: progress.Offset, Data: data[500:]}))
+	progress, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+	require.NotNil(t, progress.Attachment)
+	assert.Equal(t, int64(len(data)), progress.Attachment.Size)
+
+	list, err := cm.ListAttachments(ctx, &pb.AttachmentRequest{ContactID: int32(c.ID)})
+	require.NoError(t, err)
+	require.Len(t, list.Attachments, 1)
+	assert.Equal(t, int64(len(data)), list.Usage)
+
+	download, err := cm.DownloadAttachment(ctx, &pb.AttachmentRequest{Id: progress.Attachment.Id})
+	require.NoError(t, err)
+	var got bytes.Buffer
+	for {
+		chunk, err := download.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got.Write(chunk.Data)
+	}
+	assert.Equal(t, data, got.Bytes())
+
+	_, err = cm.DeleteAttachment(ctx, &pb.AttachmentRequest{Id: progress.Attachment.Id})
+	require.NoError(t, err)
+	_, err = cm.DeleteAttachment(ctx, &pb.AttachmentRequest{Id: progress.Attachment.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
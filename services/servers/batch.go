@@ -0,0 +1,115 @@
+package servers
+
+import (
+	"context"
+	"errors"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchCreateContacts creates many contacts in a single transaction.
+func (c *ContactManagerGrpc) BatchCreateContacts(ctx context.Context, in *pb.BatchCreateContactsRequest) (*pb.BatchResponse, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	created := make([]*contact.Contact, len(in.Contacts))
+	results, err := c.DB.Batch(len(in.Contacts), in.Mode == pb.BatchMode_BATCH_ATOMIC, func(tx *contact.DB, i int) error {
+		item := in.Contacts[i]
+		res, err := tx.Create(contact.Contact{
+			UserID:         userID,
+			Fullname:       item.Name,
+			Email:          item.Email,
+			Phone:          item.Phone,
+			Address:        item.Address,
+			OrganizationID: organizationID(item.OrganizationID),
+			JobTitle:       item.JobTitle,
+			Department:     item.Department,
+		})
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		created[i] = res
+		return nil
+	})
+	return toPBBatchResponse(results, created, err)
+}
+
+// BatchUpdateContacts applies many updates, each following the rules of UpdateContact, in a single transaction.
+func (c *ContactManagerGrpc) BatchUpdateContacts(ctx context.Context, in *pb.BatchUpdateContactsRequest) (*pb.BatchResponse, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updated := make([]*contact.Contact, len(in.Requests))
+	results, err := c.DB.Batch(len(in.Requests), in.Mode == pb.BatchMode_BATCH_ATOMIC, func(tx *contact.DB, i int) error {
+		req := in.Requests[i]
+		if req.Contact == nil {
+			return status.Error(codes.InvalidArgument, errInvalidContactID.Error())
+		}
+		found, err := tx.FindByID(userID, uint(req.Contact.Id))
+		if err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		if err := applyUpdate(found, req); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := tx.Update(found); err != nil {
+			if errors.Is(err, contact.ErrVersionConflict) {
+				return status.Error(codes.FailedPrecondition, err.Error())
+			}
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		updated[i] = found
+		return nil
+	})
+	return toPBBatchResponse(results, updated, err)
+}
+
+// BatchDeleteContacts deletes many contacts in a single transaction.
+func (c *ContactManagerGrpc) BatchDeleteContacts(ctx context.Context, in *pb.BatchDeleteContactsRequest) (*pb.BatchResponse, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]*contact.Contact, len(in.Ids))
+	results, err := c.DB.Batch(len(in.Ids), in.Mode == pb.BatchMode_BATCH_ATOMIC, func(tx *contact.DB, i int) error {
+		res, err := tx.Delete(userID, uint(in.Ids[i]))
+		if err != nil {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		deleted[i] = res
+		return nil
+	})
+	return toPBBatchResponse(results, deleted, err)
+}
+
+func toPBBatchResponse(results []error, contacts []*contact.Contact, err error) (*pb.BatchResponse, error) {
+	if errors.Is(err, contact.ErrBatchTooLarge) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.BatchResponse{}
+	for i, err := range results {
+		result := &pb.BatchResult{Index: int32(i)}
+		switch {
+		case errors.Is(err, contact.ErrBatchAborted):
+			result.Code = int32(codes.Aborted)
+			result.Error = err.Error()
+		case err != nil:
+			s := status.Convert(err)
+			result.Code = int32(s.Code())
+			result.Error = s.Message()
+		default:
+			result.Contact = toPBContact(contacts[i], false)
+		}
+		res.Results = append(res.Results, result)
+	}
+	return res, nil
+}
@@ -0,0 +1,88 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func batchContacts(n int) []*pb.Contact {
+	contacts := make([]*pb.Contact, n)
+	for i := range contacts {
+		contacts[i] = &pb.Contact{
+			Name:    fmt.Sprintf("Contact %d", i),
+			Email:   fmt.Sprintf("contact%d@gmail.com", i),
+			Phone:   "+2347033304280",
+			Address: "33, Tioya Street, Ibadan",
+		}
+	}
+	return contacts
+}
+
+func resultCodes(res *pb.BatchResponse) []codes.Code {
+	var c []codes.Code
+	for _, r := range res.Results {
+		c = append(c, codes.Code(r.Code))
+	}
+	return c
+}
+
+func TestGRPCBatchContacts(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+
+	contacts := batchContacts(3)
+	contacts[2].Email = ""
+	res, err := contactgrpc.BatchCreateContacts(ctx, &pb.BatchCreateContactsRequest{Contacts: contacts})
+	require.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.Aborted, codes.Aborted, codes.InvalidArgument}, resultCodes(res))
+	list, err := contactgrpc.GetUserContacts(ctx, &pb.User{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Contacts)
+
+	res, err = contactgrpc.BatchCreateContacts(ctx, &pb.BatchCreateContactsRequest{Contacts: contacts, Mode: pb.BatchMode_BATCH_BEST_EFFORT})
+	require.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.OK, codes.OK, codes.InvalidArgument}, resultCodes(res))
+	first, second := res.Results[0].Contact, res.Results[1].Contact
+	require.NotNil(t, first)
+	assert.NotZero(t, first.Id)
+
+	res, err = contactgrpc.BatchUpdateContacts(ctx, &pb.BatchUpdateContactsRequest{
+		Mode: pb.BatchMode_BATCH_BEST_EFFORT,
+		Requests: []*pb.UpdateContactRequest{
+			{Contact: &pb.Contact{Id: first.Id, Phone: "08155040074"}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"phone"}}},
+			{Contact: &pb.Contact{Id: second.Id, Phone: "0803", Version: second.Version + 1}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"phone"}}},
+			{Contact: &pb.Contact{Id: 999999}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.OK, codes.FailedPrecondition, codes.NotFound}, resultCodes(res))
+	assert.Equal(t, "08155040074", res.Results[0].Contact.Phone)
+
+	res, err = contactgrpc.BatchDeleteContacts(ctx, &pb.BatchDeleteContactsRequest{Ids: []int32{first.Id, second.Id, 999999}})
+	require.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.Aborted, codes.Aborted, codes.NotFound}, resultCodes(res))
+	res, err = contactgrpc.BatchDeleteContacts(ctx, &pb.BatchDeleteContactsRequest{Ids: []int32{first.Id, second.Id}})
+	require.NoError(t, err)
+	assert.Equal(t, []codes.Code{codes.OK, codes.OK}, resultCodes(res))
+	list, err = contactgrpc.GetUserContacts(ctx, &pb.User{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Contacts)
+
+	_, err = contactgrpc.BatchCreateContacts(ctx, &pb.BatchCreateContactsRequest{Contacts: batchContacts(contact.MaxBatchSize + 1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,47 @@
+package servers
+
+import (
+	"net/http"
+
+	"grpc-contact-manager/services/carddav"
+	"grpc-contact-manager/services/organization"
+
+	"github.com/gin-gonic/gin"
+)
+
+// carddavPrefix is where the CardDAV address books are served.
+const carddavPrefix = "/carddav"
+
+// carddavMethods are the methods of WebDAV and CardDAV the address books answer.
+var carddavMethods = []string{
+	http.MethodOptions, "PROPFIND", "PROPPATCH", "REPORT",
+	http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+}
+
+// CardDAVRoutes serves the contacts of every user as a CardDAV address book, authenticated with
+// the user's email and password. Clients find it from /.well-known/carddav.
+func (s *Server) CardDAVRoutes() {
+	wellKnown := func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, carddavPrefix+"/")
+	}
+	s.Router.GET("/.well-known/carddav", wellKnown)
+	s.Router.Handle("PROPFIND", "/.well-known/carddav", wellKnown)
+
+	dav := s.Router.Group(carddavPrefix)
+	for _, method := range carddavMethods {
+		dav.Handle(method, "/*path", s.carddav)
+	}
+}
+
+// carddav serves a CardDAV request. The handler is built per request since the repositories
+// are only set up when the server starts.
+func (s *Server) carddav(c *gin.Context) {
+	h := &carddav.Handler{
+		Contacts: contactDB,
+		Orgs:     &organization.DB{Conn: s.Conn},
+		Users:    userDB,
+		Objects:  carddavDB,
+		Prefix:   carddavPrefix,
+	}
+	h.ServeHTTP(c.Writer, c.Request)
+}
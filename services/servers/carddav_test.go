@@ -0,0 +1,47 @@
+package servers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardDAV(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "carddav@example.com")
+	t.Cleanup(func() {
+		require.NoError(t, server.Conn.Exec("DELETE FROM carddav_objects").Error)
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+
+	w := doRequest(t, s.Handler, "PROPFIND", "/.well-known/carddav", "", nil)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/carddav/", w.Header().Get("Location"))
+
+	book := fmt.Sprintf("/carddav/addressbooks/%d/contacts/", userID)
+	card := "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:ebo-1\r\nFN:Ebo Mensah\r\nEMAIL:ebo@example.com\r\n" +
+		"TEL:+233200000000\r\nADR:;;4 Ring Road;Accra;;;Ghana\r\nEND:VCARD\r\n"
+	basic := func(password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte("carddav@example.com:"+password))
+	}
+	w = doRequest(t, s.Handler, "PUT", book+"ebo.vcf", "", strings.NewReader(card), "Authorization", basic("wrong"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doRequest(t, s.Handler, "PUT", book+"ebo.vcf", "", strings.NewReader(card), "Authorization", basic("password"))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// the card is an ordinary contact of the user
+	w = doRequest(t, s.Handler, "GET", "/contacts/", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"ebo@example.com"`)
+
+	w = doRequest(t, s.Handler, "PROPFIND", book, "", nil, "Authorization", basic("password"), "Depth", "1")
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Contains(t, w.Body.String(), "<d:href>"+book+"ebo.vcf</d:href>")
+}
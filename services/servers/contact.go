@@ -0,0 +1,618 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/addressbook"
+	"grpc-contact-manager/services/attachment"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/duplicate"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/organization"
+	"grpc-contact-manager/services/photo"
+	"grpc-contact-manager/services/relationship"
+	"grpc-contact-manager/services/reminder"
+	"grpc-contact-manager/services/storage"
+	"grpc-contact-manager/services/timeline"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+var (
+	errInvalidContactID  = errors.New("invalid contact id")
+	errInvalidUpdateMask = errors.New("field can't be updated")
+	errInvalidPatch      = errors.New("invalid merge patch")
+)
+
+// ContactManagerGrpc implements the ContactManager gRPC service.
+type ContactManagerGrpc struct {
+	DB           *contact.DB
+	Photos       *photo.DB
+	Attachments  *attachment.DB
+	Timeline     *timeline.DB
+	Reminders    *reminder.DB
+	Relations    *relationship.DB
+	Orgs         *organization.DB
+	Duplicates   *duplicate.DB
+	AddressBooks *addressbook.DB
+	pb.UnimplementedContactManagerServer
+}
+
+// ContactReq request struct
+type ContactReq struct {
+	Name           string `json:"name" form:"name"`
+	Email          string `json:"email" form:"email"`
+	Phone          string `json:"phone" form:"phone"`
+	Address        string `json:"address" form:"address"`
+	OrganizationID *uint  `json:"organization_id" form:"organization_id"`
+	JobTitle       string `json:"job_title" form:"job_title"`
+	Department     string `json:"department" form:"department"`
+}
+
+// NewContactManagerGRPC creates the contact gRPC service and its repositories on the given connection.
+// addressBookKey encrypts the passwords of external address books.
+func NewContactManagerGRPC(conn *gorm.DB, store storage.BlobStore, addressBookKey []byte) (*ContactManagerGrpc, error) {
+	c, err := contact.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	p, err := photo.New(conn, store)
+	if err != nil {
+		return nil, err
+	}
+	a, err := attachment.New(conn, store)
+	if err != nil {
+		return nil, err
+	}
+	tl, err := timeline.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	r, err := reminder.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := relationship.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	o, err := organization.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	dup, err := duplicate.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	ab, err := addressbook.New(conn)
+	if err != nil {
+		return nil, err
+	}
+	ab.Key = addressBookKey
+	for _, db := range []Database{o, c, p, a, tl, r, rel, dup, ab} {
+		if err := db.Migrate(); err != nil {
+			return nil, err
+		}
+	}
+	return &ContactManagerGrpc{
+		DB:           c,
+		Photos:       p,
+		Attachments:  a,
+		Timeline:     tl,
+		Reminders:    r,
+		Relations:    rel,
+		Orgs:         o,
+		Duplicates:   dup,
+		AddressBooks: ab,
+	}, nil
+}
+
+// ContactRoutes registers the contact routes. All of them require a bearer token, except the
+// event stream, which may be opened with a ticket instead.
+func (s *Server) ContactRoutes() {
+	redeem := func(t string) (uint, error) { return ticketDB.Redeem(t) }
+	contacts := s.Router.Group("/contacts", middlewares.AuthenticateStream("/contacts/events", redeem))
+	{
+		contacts.GET("/", s.userContacts)
+		contacts.POST("/", s.newContact)
+		contacts.GET("/:id", fileRoutes(s.findContact, map[string]gin.HandlerFunc{
+			"events":      s.contactEvents,
+			"export":      s.exportFile,
+			"export.csv":  s.exportCSV,
+			"export.pdf":  s.exportPDF,
+			"export.vcf":  s.exportVCards,
+			"export.xlsx": s.exportXLSX,
+		}))
+		contacts.POST("/:id", fileRoutes(nil, map[string]gin.HandlerFunc{
+			"events": s.contactEventsTicket,
+			"import": s.importFile,
+			"scan":   s.scanContact,
+		}))
+		contacts.PUT("/:id", s.updateContact)
+		contacts.PATCH("/:id", s.patchContact)
+
+		contacts.GET("/:id/qr", s.contactQR)
+
+		contacts.GET("/:id/photo", s.contactPhoto)
+		contacts.POST("/:id/photo", s.uploadContactPhoto)
+		contacts.DELETE("/:id/photo", s.deleteContactPhoto)
+	}
+}
+
+func (s *Server) userContacts(c *gin.Context) {
+	userID := middlewares.UserID(c)
+	var (
+		contacts []contact.Contact
+		err      error
+	)
+	if search := c.Query("search"); search != "" {
+		contacts, err = contactDB.Search(uint32(userID), search)
+	} else if c.Query("sort") == "last_contacted" {
+		contacts, err = contactDB.FindRecentlyContacted(uint32(userID))
+	} else {
+		contacts, err = contactDB.FindByUserID(uint32(userID))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    contacts,
+	})
+}
+
+func (s *Server) newContact(c *gin.Context) {
+	var req ContactReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	newContact, err := contactDB.Create(contact.Contact{
+		UserID:         middlewares.UserID(c),
+		Fullname:       req.Name,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		Address:        req.Address,
+		OrganizationID: req.OrganizationID,
+		JobTitle:       req.JobTitle,
+		Department:     req.Department,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.Header("ETag", etag(newContact))
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Contact created successfully",
+		"data":    newContact,
+	})
+}
+
+func (s *Server) findContact(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	tag := etag(found)
+	c.Header("ETag", tag)
+	if matchETag(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    found,
+	})
+}
+
+func (s *Server) updateContact(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	if !checkPreconditions(c, found) {
+		return
+	}
+	var req ContactReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	found.Fullname = req.Name
+	found.Email = req.Email
+	found.Phone = req.Phone
+	found.Address = req.Address
+	found.OrganizationID = req.OrganizationID
+	found.JobTitle = req.JobTitle
+	found.Department = req.Department
+	if err := contactDB.Update(found); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, contact.ErrVersionConflict) {
+			code = http.StatusPreconditionFailed
+		}
+		c.JSON(code, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.Header("ETag", etag(found))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Contact updated successfully",
+		"data":    found,
+	})
+}
+
+// patchContact applies a JSON merge patch (RFC 7396) to the contact. Members set to null are cleared.
+func (s *Server) patchContact(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	if !checkPreconditions(c, found) {
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := applyMergePatch(found, patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := contactDB.Update(found); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, contact.ErrVersionConflict) {
+			code = http.StatusPreconditionFailed
+		}
+		c.JSON(code, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.Header("ETag", etag(found))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Contact updated successfully",
+		"data":    found,
+	})
+}
+
+// applyMergePatch sets the contact fields present in the patch, named as in ContactReq.
+func applyMergePatch(c *contact.Contact, patch map[string]json.RawMessage) error {
+	strs := map[string]*string{
+		"name":       &c.Fullname,
+		"email":      &c.Email,
+		"phone":      &c.Phone,
+		"address":    &c.Address,
+		"job_title":  &c.JobTitle,
+		"department": &c.Department,
+	}
+	for key, raw := range patch {
+		null := string(raw) == "null"
+		if field, ok := strs[key]; ok {
+			if null {
+				*field = ""
+			} else if err := json.Unmarshal(raw, field); err != nil {
+				return fmt.Errorf("%w: %s must be a string", errInvalidPatch, key)
+			}
+			continue
+		}
+		if key != "organization_id" {
+			return fmt.Errorf("%w: unknown field %s", errInvalidPatch, key)
+		}
+		if null {
+			c.OrganizationID = nil
+			continue
+		}
+		var id uint
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return fmt.Errorf("%w: organization_id must be a number", errInvalidPatch)
+		}
+		c.OrganizationID = &id
+	}
+	return nil
+}
+
+// checkPreconditions applies the If-Match and If-None-Match headers of a request changing the
+// contact, writing a 412 response when they don't hold. If-Match also makes the update fail
+// when the contact changes before it is saved.
+func checkPreconditions(c *gin.Context, found *contact.Contact) bool {
+	tag := etag(found)
+	ifMatch, ifNoneMatch := c.GetHeader("If-Match"), c.GetHeader("If-None-Match")
+	if (ifMatch != "" && !matchETag(ifMatch, tag)) || (ifNoneMatch != "" && matchETag(ifNoneMatch, tag)) {
+		c.Header("ETag", tag)
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"success": false,
+			"error":   contact.ErrVersionConflict.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// etag is the entity tag of the contact's current version.
+func etag(c *contact.Contact) string {
+	return fmt.Sprintf("\"%d-%d\"", c.ID, c.Version)
+}
+
+// matchETag reports whether the If-Match or If-None-Match header matches the entity tag.
+// Weak tags are compared by their value.
+func matchETag(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// fileRoutes serves the handler named by the :id path parameter, falling back to next for
+// contact ids, as gin can't register routes such as /contacts/export.vcf next to /contacts/:id.
+// Without next, unknown names aren't found.
+func fileRoutes(next gin.HandlerFunc, routes map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h, ok := routes[c.Param("id")]; ok {
+			h(c)
+			return
+		}
+		if next == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   http.StatusText(http.StatusNotFound),
+			})
+			return
+		}
+		next(c)
+	}
+}
+
+// contactFromParam loads the contact named by the :id path parameter, writing the error response when it can't.
+func (s *Server) contactFromParam(c *gin.Context) (*contact.Contact, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   errInvalidContactID.Error(),
+		})
+		return nil, false
+	}
+	found, err := contactDB.FindByID(middlewares.UserID(c), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+	return found, true
+}
+
+func (c *ContactManagerGrpc) NewContact(ctx context.Context, in *pb.Contact) (*pb.Contact, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newContact, err := c.DB.Create(contact.Contact{
+		UserID:         userID,
+		Fullname:       in.Name,
+		Email:          in.Email,
+		Phone:          in.Phone,
+		Address:        in.Address,
+		OrganizationID: organizationID(in.OrganizationID),
+		JobTitle:       in.JobTitle,
+		Department:     in.Department,
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toPBContact(newContact, false), nil
+}
+
+func (c *ContactManagerGrpc) GetContactByID(ctx context.Context, in *pb.FindContactRequest) (*pb.Contact, error) {
+	found, err := c.findContact(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+	has, err := c.Photos.HasPhoto(found.ID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := c.Relations.FindByContact(found.UserID, found.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := toPBContact(found, has[found.ID])
+	for i := range relationships {
+		res.Related = append(res.Related, toPBRelated(&relationships[i], &relationships[i].Related))
+	}
+	return res, nil
+}
+
+func (c *ContactManagerGrpc) GetUserContacts(ctx context.Context, in *pb.User) (*pb.ContactList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := c.DB.FindByUserID(uint32(userID))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(contacts))
+	for i := range contacts {
+		ids[i] = contacts[i].ID
+	}
+	has, err := c.Photos.HasPhoto(ids...)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ContactList{}
+	for i := range contacts {
+		res.Contacts = append(res.Contacts, toPBContact(&contacts[i], has[contacts[i].ID]))
+	}
+	return res, nil
+}
+
+// UpdateContact changes the fields set in the contact and keeps the others, like PatchContact
+// with an empty mask.
+func (c *ContactManagerGrpc) UpdateContact(ctx context.Context, in *pb.Contact) (*pb.Contact, error) {
+	return c.PatchContact(ctx, &pb.UpdateContactRequest{Contact: in})
+}
+
+// PatchContact changes the fields named by the update mask, or the fields set in the contact
+// when it is empty. Validation runs on the merged contact.
+func (c *ContactManagerGrpc) PatchContact(ctx context.Context, in *pb.UpdateContactRequest) (*pb.Contact, error) {
+	if in.Contact == nil {
+		return nil, status.Error(codes.InvalidArgument, errInvalidContactID.Error())
+	}
+	found, err := c.findContact(ctx, in.Contact.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyUpdate(found, in); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := c.DB.Update(found); err != nil {
+		if errors.Is(err, contact.ErrVersionConflict) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return c.GetContactByID(ctx, &pb.FindContactRequest{Id: int32(found.ID)})
+}
+
+// applyUpdate copies the fields named by the request's update mask, and the version to check, to c.
+func applyUpdate(c *contact.Contact, in *pb.UpdateContactRequest) error {
+	if in.Contact.Version != 0 {
+		c.Version = uint(in.Contact.Version)
+	}
+	paths := in.UpdateMask.GetPaths()
+	if len(paths) == 0 {
+		paths = setContactPaths(in.Contact)
+	}
+	for _, path := range paths {
+		if err := applyContactField(c, in.Contact, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contactMaskPaths are the update mask paths of UpdateContact.
+var contactMaskPaths = []string{"name", "email", "phone", "address", "organizationID", "jobTitle", "department"}
+
+// setContactPaths returns the update mask paths of the fields set in the contact, so fields
+// left out by clients without a mask keep their values.
+func setContactPaths(in *pb.Contact) []string {
+	set := map[string]bool{
+		"name":           in.Name != "",
+		"email":          in.Email != "",
+		"phone":          in.Phone != "",
+		"address":        in.Address != "",
+		"organizationID": in.OrganizationID != 0,
+		"jobTitle":       in.JobTitle != "",
+		"department":     in.Department != "",
+	}
+	var paths []string
+	for _, path := range contactMaskPaths {
+		if set[path] {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// applyContactField copies the field named by an update mask path from in to c.
+func applyContactField(c *contact.Contact, in *pb.Contact, path string) error {
+	switch path {
+	case "name":
+		c.Fullname = in.Name
+	case "email":
+		c.Email = in.Email
+	case "phone":
+		c.Phone = in.Phone
+	case "address":
+		c.Address = in.Address
+	case "organizationID":
+		c.OrganizationID = organizationID(in.OrganizationID)
+	case "jobTitle":
+		c.JobTitle = in.JobTitle
+	case "department":
+		c.Department = in.Department
+	default:
+		return fmt.Errorf("%w: %s", errInvalidUpdateMask, path)
+	}
+	return nil
+}
+
+// findContact loads a contact owned by the authenticated user.
+func (c *ContactManagerGrpc) findContact(ctx context.Context, id int32) (*contact.Contact, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, errInvalidContactID.Error())
+	}
+	found, err := c.DB.FindByID(userID, uint(id))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return found, nil
+}
+
+// authUserID returns the user authenticated by the gRPC auth interceptor.
+func authUserID(ctx context.Context) (uint, error) {
+	userID, ok := middlewares.UserIDFromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	return userID, nil
+}
+
+func toPBContact(c *contact.Contact, hasPhoto bool) *pb.Contact {
+	res := &pb.Contact{
+		Id:         int32(c.ID),
+		UserID:     int32(c.UserID),
+		Name:       c.Fullname,
+		Address:    c.Address,
+		Phone:      c.Phone,
+		Email:      c.Email,
+		HasPhoto:   hasPhoto,
+		JobTitle:   c.JobTitle,
+		Department: c.Department,
+		Version:    int32(c.Version),
+	}
+	if c.OrganizationID != nil {
+		res.OrganizationID = int32(*c.OrganizationID)
+	}
+	if c.LastContactedAt != nil {
+		res.LastContactedAt = c.LastContactedAt.Unix()
+	}
+	return res
+}
@@ -0,0 +1,307 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/gorm"
+)
+
+func TestCreateAndFindContact(t *testing.T) {
+	ctx := context.Background()
+	s, err := server.StartHttp(ctx, ":2500")
+	require.NoError(t, err)
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+
+	payload := `{
+		"name":"Alugbin Abiodun",
+		"email":"tolaabbey001@gmail.com",
+		"phone":"+2347033304280",
+		"address":"33, Tioya Street, Ibadan"
+	}`
+	w := doRequest(t, s.Handler, "POST", "/contacts/", token, strings.NewReader(payload))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	resp := struct {
+		Data struct {
+			ID       uint   `json:"ID"`
+			Fullname string `json:"full_name"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Alugbin Abiodun", resp.Data.Fullname)
+
+	w = doRequest(t, s.Handler, "GET", fmt.Sprintf("/contacts/%d", resp.Data.ID), token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "tolaabbey001@gmail.com")
+
+	// another user can't see the contact
+	_, otherToken := authenticatedUser(t, "other@gmail.com")
+	w = doRequest(t, s.Handler, "GET", fmt.Sprintf("/contacts/%d", resp.Data.ID), otherToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestUpdateContactPreconditions(t *testing.T) {
+	ctx := context.Background()
+	s, err := server.StartHttp(ctx, ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	path := fmt.Sprintf("/contacts/%d", c.ID)
+	payload := func(phone string) io.Reader {
+		return strings.NewReader(fmt.Sprintf(`{"name":"Alugbin Abiodun","email":"tolaabbey001@gmail.com","phone":%q,"address":"Ibadan"}`, phone))
+	}
+
+	w := doRequest(t, s.Handler, "GET", path, token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	tag := w.Header().Get("ETag")
+	assert.Equal(t, fmt.Sprintf(`"%d-1"`, c.ID), tag)
+
+	w = doRequest(t, s.Handler, "GET", path, token, nil, "If-None-Match", tag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = doRequest(t, s.Handler, "PUT", path, token, payload("08155040074"), "If-Match", tag)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	newTag := w.Header().Get("ETag")
+	assert.Equal(t, fmt.Sprintf(`"%d-2"`, c.ID), newTag)
+
+	// the old tag is stale now
+	w = doRequest(t, s.Handler, "PUT", path, token, payload("08155040075"), "If-Match", tag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, newTag, w.Header().Get("ETag"))
+	w = doRequest(t, s.Handler, "PUT", path, token, payload("08155040075"), "If-None-Match", "*")
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", path, token, nil, "If-None-Match", tag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "08155040074")
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestPatchContact(t *testing.T) {
+	ctx := context.Background()
+	s, err := server.StartHttp(ctx, ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	path := fmt.Sprintf("/contacts/%d", c.ID)
+
+	w := doRequest(t, s.Handler, "PATCH", path, token, strings.NewReader(`{"phone":"08155040074","job_title":"Engineer"}`),
+		"Content-Type", "application/merge-patch+json")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	found, err := contactDB.FindByID(userID, c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "08155040074", found.Phone)
+	assert.Equal(t, "Engineer", found.JobTitle)
+	assert.Equal(t, c.Fullname, found.Fullname)
+	assert.Equal(t, c.Address, found.Address)
+
+	w = doRequest(t, s.Handler, "PATCH", path, token, strings.NewReader(`{"job_title":null}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"job_title":""`)
+
+	table := []string{
+		`{"phone":null}`,
+		`{"phone":42}`,
+		`{"nickname":"Abbey"}`,
+		`{"organization_id":999}`,
+	}
+	for _, patch := range table {
+		w = doRequest(t, s.Handler, "PATCH", path, token, strings.NewReader(patch))
+		assert.Equal(t, http.StatusBadRequest, w.Code, patch)
+	}
+
+	w = doRequest(t, s.Handler, "PATCH", path, token, strings.NewReader(`{"phone":"0803"}`), "If-Match", `"0-1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestContactRoutesRequireToken(t *testing.T) {
+	ctx := context.Background()
+	s, err := server.StartHttp(ctx, ":2500")
+	require.NoError(t, err)
+
+	w := doRequest(t, s.Handler, "GET", "/contacts/", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/", "not-a-token", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGRPCContacts(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+
+	created, err := contactgrpc.NewContact(ctx, &pb.Contact{
+		Name:    "Alugbin Abiodun",
+		Email:   "tolaabbey001@gmail.com",
+		Phone:   "+2347033304280",
+		Address: "33, Tioya Street, Ibadan",
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, created.Id)
+	assert.Equal(t, int32(userID), created.UserID)
+
+	created.Phone = "08155040074"
+	updated, err := contactgrpc.UpdateContact(ctx, created)
+	require.NoError(t, err)
+	assert.Equal(t, "08155040074", updated.Phone)
+	assert.Equal(t, created.Version+1, updated.Version)
+
+	// fields left out keep their values
+	updated, err = contactgrpc.UpdateContact(ctx, &pb.Contact{Id: created.Id, JobTitle: "Engineer"})
+	require.NoError(t, err)
+	assert.Equal(t, "Engineer", updated.JobTitle)
+	assert.Equal(t, "08155040074", updated.Phone)
+	assert.Equal(t, "Alugbin Abiodun", updated.Name)
+
+	// created holds a previous version
+	created.Address = "Lagos"
+	_, err = contactgrpc.UpdateContact(ctx, created)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// only the fields in the mask change
+	updated, err = contactgrpc.PatchContact(ctx, &pb.UpdateContactRequest{
+		Contact:    &pb.Contact{Id: created.Id, Address: "Lagos"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"address"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Lagos", updated.Address)
+	assert.Equal(t, "08155040074", updated.Phone)
+	assert.Equal(t, "Alugbin Abiodun", updated.Name)
+
+	// validation runs on the merged contact
+	_, err = contactgrpc.PatchContact(ctx, &pb.UpdateContactRequest{
+		Contact:    &pb.Contact{Id: created.Id},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"phone"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = contactgrpc.PatchContact(ctx, &pb.UpdateContactRequest{
+		Contact:    &pb.Contact{Id: created.Id},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"userID"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := contactgrpc.GetUserContacts(ctx, &pb.User{})
+	require.NoError(t, err)
+	require.Len(t, list.Contacts, 1)
+	assert.Equal(t, created.Id, list.Contacts[0].Id)
+
+	_, err = contactgrpc.GetContactByID(middlewares.ContextWithUserID(context.Background(), userID+1), &pb.FindContactRequest{Id: created.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = contactgrpc.GetUserContacts(context.Background(), &pb.User{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCAuthInterceptor(t *testing.T) {
+	ctx := context.Background()
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+
+	c := pb.NewContactManagerClient(client)
+	_, err := c.GetUserContacts(ctx, &pb.User{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	list, err := c.GetUserContacts(authCtx, &pb.User{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Contacts)
+
+	// the user service doesn't need a token
+	u := pb.NewUserManagerClient(client)
+	_, err = u.Authenticate(ctx, &pb.AuthUserRequest{Email: "tolaabbey009@gmail.com", Password: "password"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+// startGRPC serves the gRPC services over an in-memory listener and returns a client connection to it.
+func startGRPC(t *testing.T) (*grpc.ClientConn, func()) {
+	lis := bufconn.Listen(1 << 20)
+	g, err := server.StartUserGRPC(context.Background())
+	require.NoError(t, err)
+	go func() {
+		_ = g.Serve(lis)
+	}()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	return conn, func() {
+		conn.Close()
+		g.Stop()
+	}
+}
+
+// doRequest sends a JSON request, authenticated with the token when one is given.
+// doRequest serves the request on h. headers are extra header name and value pairs.
+func doRequest(t *testing.T, h http.Handler, method, path, token string, body io.Reader, headers ...string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, body)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// cleanupContacts removes the contacts, everything attached to them and the users.
+func cleanupContacts(db *gorm.DB) error {
+	for _, table := range []string{"photos", "uploads", "attachments", "notes", "interactions", "reminders", "relationships", "revisions", "merges", "contacts", "organizations"} {
+		if err := db.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
+	return cleanup(db)
+}
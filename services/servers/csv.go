@@ -0,0 +1,193 @@
+package servers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/sheet"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// columnMapping lays the custom header mapping over the preset.
+func columnMapping(preset string, custom map[string]string) (sheet.Mapping, error) {
+	base, err := sheet.Preset(preset)
+	if err != nil {
+		return nil, err
+	}
+	if len(custom) == 0 {
+		return base, nil
+	}
+	m := make(sheet.Mapping, len(base)+len(custom))
+	for header, field := range base {
+		m[header] = field
+	}
+	for header, field := range custom {
+		m[header] = field
+	}
+	return m, m.Validate()
+}
+
+// rowReader reads the rows of a spreadsheet.
+type rowReader interface {
+	Read() (*sheet.Row, error)
+}
+
+// rowWriter writes the rows of a spreadsheet.
+type rowWriter interface {
+	Write(*sheet.Row) error
+}
+
+// importRows adds the rows read from r to the import, numbered by line. Organizations are
+// resolved by name as the rows are written. Malformed rows fail on their own, while a file that can't be decoded stops
+// the import with errInvalidFile.
+func importRows(im *contact.Importer, r rowReader) (*contact.ImportSummary, error) {
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *sheet.RowError
+		if errors.As(err, &rowErr) {
+			im.Fail(rowErr.Line, rowErr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidFile, err)
+		}
+		if err := im.AddInOrganization(row.Line, row.Contact, row.Organization); err != nil {
+			return nil, err
+		}
+	}
+	return im.Close()
+}
+
+// exportRows writes the contacts of the user as rows, reading them in batches.
+func (t *transfer) exportRows(ctx context.Context, userID uint, w rowWriter) error {
+	names, err := t.organizationNames(userID)
+	if err != nil {
+		return err
+	}
+	return t.contacts.Export(ctx, userID, 0, 0, func(batch []contact.Contact) error {
+		for i := range batch {
+			row := &sheet.Row{Contact: batch[i]}
+			if id := batch[i].OrganizationID; id != nil {
+				row.Organization = names[*id]
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// exportCSV writes the contacts of the user as a CSV file.
+func (t *transfer) exportCSV(ctx context.Context, userID uint, w *sheet.CSVWriter) error {
+	if err := t.exportRows(ctx, userID, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// exportCSV downloads the contacts of the user as a CSV file. The columns query parameter
+// picks the fields, separated by commas, and bom starts the file with a byte order mark. The
+// cells spreadsheet applications would read as formulas are always escaped.
+func (s *Server) exportCSV(c *gin.Context) {
+	var columns []string
+	if v := c.Query("columns"); v != "" {
+		columns = strings.Split(v, ",")
+	}
+	bom, _ := strconv.ParseBool(c.Query("bom"))
+	w, err := sheet.NewCSVWriter(c.Writer, columns, bom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	startDownload(c, sheet.CSVContentType+"; charset=utf-8", "contacts.csv")
+	if err := s.restTransfer().exportCSV(c.Request.Context(), middlewares.UserID(c), w); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// ImportCSV imports the CSV file streamed in chunks and reports how every row went, numbering
+// rows by line.
+func (c *ContactManagerGrpc) ImportCSV(stream pb.ContactManager_ImportCSVServer) error {
+	userID, err := authUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+	im, err := c.DB.NewImporter(userID, duplicatePolicy(stream.Context()))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "no csv file sent")
+	}
+	if err != nil {
+		return err
+	}
+	m, err := columnMapping(first.Preset, first.Mapping)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	body := &chunkReader{buf: first.Data, recv: func() ([]byte, error) {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return chunk.Data, nil
+	}}
+	r, err := sheet.NewCSVReader(body, m, first.Charset)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	summary, err := importRows(im, r)
+	if errors.Is(err, errInvalidFile) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(toPBImportSummary(summary))
+}
+
+// ExportCSV streams the contacts of the user as a CSV file, in chunks of up to 32 KiB.
+func (c *ContactManagerGrpc) ExportCSV(in *pb.CSVExportRequest, stream pb.ContactManager_ExportCSVServer) error {
+	ctx := stream.Context()
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriterSize(chunkWriter(func(p []byte) error {
+		return stream.Send(&pb.CSVChunk{Data: p})
+	}), fileChunkSize)
+	w, err := sheet.NewCSVWriter(buf, in.Columns, in.Bom)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = c.transfer().exportCSV(ctx, userID, w)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return err
+	}
+	return buf.Flush()
+}
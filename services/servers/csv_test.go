@@ -0,0 +1,146 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCSVImportExport(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	google, err := os.ReadFile("../sheet/testdata/google.csv")
+	require.NoError(t, err)
+	outlook, err := os.ReadFile("../sheet/testdata/outlook.csv")
+	require.NoError(t, err)
+
+	type summary struct {
+		Created int `json:"created"`
+		Failed  int `json:"failed"`
+		Errors  []struct {
+			Row int `json:"row"`
+		} `json:"errors"`
+	}
+	importCSV := func(path string, body []byte, contentType string) (int, summary) {
+		w := doRequest(t, s.Handler, "POST", path, token, bytes.NewReader(body), "Content-Type", contentType)
+		resp := struct {
+			Data summary `json:"data"`
+		}{}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp.Data
+	}
+
+	code, _ := importCSV("/contacts/import?preset=yahoo", google, "text/csv")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = importCSV("/contacts/import?mapping[Notes]=notes", google, "text/csv")
+	assert.Equal(t, http.StatusBadRequest, code)
+	// the default preset maps none of the columns
+	code, _ = importCSV("/contacts/import", google, "text/csv")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, got := importCSV("/contacts/import?preset=google", google, "text/csv")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, got.Created)
+	assert.Equal(t, 2, got.Failed)
+	require.Len(t, got.Errors, 2)
+	// rows are numbered by line
+	assert.Equal(t, 6, got.Errors[0].Row)
+	assert.Equal(t, 7, got.Errors[1].Row)
+
+	code, got = importCSV("/contacts/import?preset=outlook", outlook, "text/csv; charset=windows-1252")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, got.Created)
+
+	custom := "Full Name,Mail,Tel,Where\r\nAda Obi,ada@example.com,0803,Enugu\r\n"
+	code, got = importCSV("/contacts/import?mapping[Full Name]=name&mapping[Mail]=email&mapping[Tel]=phone&mapping[Where]=address", []byte(custom), "application/csv")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, got.Created)
+
+	w := doRequest(t, s.Handler, "GET", "/contacts/export.csv?columns=name,job", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export.csv?columns=email,name,organization&bom=true", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.True(t, strings.HasPrefix(w.Body.String(), "\ufeff"))
+	lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(w.Body.String(), "\ufeff")), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "email,name,organization", lines[0])
+	assert.Contains(t, lines, "jane.doe@acme.com,Jane Doe,Acme")
+	assert.Contains(t, lines, "renee@example.fr,Renée François,Société Générale")
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCCSV(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+
+	importCSV := func(chunks ...*pb.CSVChunk) (*pb.ImportSummary, error) {
+		stream, err := cm.ImportCSV(ctx)
+		require.NoError(t, err)
+		for _, chunk := range chunks {
+			if err := stream.Send(chunk); err != nil {
+				break
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+
+	_, err := importCSV()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = importCSV(&pb.CSVChunk{Data: []byte("a,b\n"), Preset: "google"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	summary, err := importCSV(
+		&pb.CSVChunk{Data: []byte("Mail,Name,Phone,Address,Company\r\nada@exa"), Mapping: map[string]string{"Mail": "email", "Company": "organization"}},
+		&pb.CSVChunk{Data: []byte("mple.com,Ada Obi,0803,Enugu,Acme\r\nbad,,,,\r\n")},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), summary.Created)
+	require.Len(t, summary.Errors, 1)
+	assert.Equal(t, int32(3), summary.Errors[0].Row)
+
+	export, err := cm.ExportCSV(ctx, &pb.CSVExportRequest{Columns: []string{"name", "organization"}})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	for {
+		chunk, err := export.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		buf.Write(chunk.Data)
+	}
+	assert.Equal(t, "name,organization\nAda Obi,Acme\n", buf.String())
+
+	export, err = cm.ExportCSV(ctx, &pb.CSVExportRequest{Columns: []string{"notes"}})
+	require.NoError(t, err)
+	_, err = export.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,98 @@
+package servers
+
+import (
+	"context"
+	"errors"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/duplicate"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FindDuplicates lists the pairs of the user's contacts that are likely the same person, best first.
+func (c *ContactManagerGrpc) FindDuplicates(ctx context.Context, in *pb.FindDuplicatesRequest) (*pb.DuplicateList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := c.Duplicates.FindDuplicates(userID, in.MinScore)
+	if err != nil {
+		return nil, err
+	}
+	if in.Limit > 0 && len(pairs) > int(in.Limit) {
+		pairs = pairs[:in.Limit]
+	}
+	res := &pb.DuplicateList{}
+	for i := range pairs {
+		p := &pairs[i]
+		res.Pairs = append(res.Pairs, &pb.DuplicatePair{
+			A:       toPBContact(&p.A, false),
+			B:       toPBContact(&p.B, false),
+			Score:   p.Score,
+			Reasons: p.Reasons,
+		})
+	}
+	return res, nil
+}
+
+// MergeContacts merges contacts into the survivor. The merge can be reverted with UndoMerge.
+func (c *ContactManagerGrpc) MergeContacts(ctx context.Context, in *pb.MergeContactsRequest) (*pb.MergeResult, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := duplicate.Request{
+		UserID:     userID,
+		SurvivorID: uint(in.SurvivorID),
+		Fields:     map[string]uint{},
+	}
+	for _, id := range in.MergedIDs {
+		req.MergedIDs = append(req.MergedIDs, uint(id))
+	}
+	for field, id := range in.FieldSources {
+		req.Fields[field] = uint(id)
+	}
+	survivor, m, err := c.Duplicates.Merge(req)
+	if err != nil {
+		return nil, duplicateError(err)
+	}
+	c.DB.Publish(userID)
+	hasPhoto, err := c.Photos.HasPhoto(survivor.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MergeResult{
+		MergeID: int32(m.ID),
+		Contact: toPBContact(survivor, hasPhoto[survivor.ID]),
+	}, nil
+}
+
+// UndoMerge reverts a merge and returns the survivor followed by the restored contacts.
+func (c *ContactManagerGrpc) UndoMerge(ctx context.Context, in *pb.MergeRequest) (*pb.ContactList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := c.Duplicates.Undo(userID, uint(in.MergeID))
+	if err != nil {
+		return nil, duplicateError(err)
+	}
+	c.DB.Publish(userID)
+	res := &pb.ContactList{}
+	for i := range contacts {
+		res.Contacts = append(res.Contacts, toPBContact(&contacts[i], false))
+	}
+	return res, nil
+}
+
+func duplicateError(err error) error {
+	switch {
+	case errors.Is(err, duplicate.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, duplicate.ErrAlreadyUndone), errors.Is(err, duplicate.ErrChangedSinceMerge):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
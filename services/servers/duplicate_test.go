@@ -0,0 +1,73 @@
+package servers
+
+import (
+	"context"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCMergeContacts(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+
+	jane, err := contactgrpc.NewContact(ctx, &pb.Contact{
+		Name:    "Jane Doe",
+		Email:   "jane.doe@gmail.com",
+		Phone:   "+2347033304280",
+		Address: "33, Tioya Street, Ibadan",
+	})
+	require.NoError(t, err)
+	dup, err := contactgrpc.NewContact(ctx, &pb.Contact{
+		Name:     "Jane A. Doe",
+		Email:    "janedoe@gmail.com",
+		Phone:    "0703 330 4280",
+		Address:  "12, Allen Avenue, Ikeja",
+		JobTitle: "Engineer",
+	})
+	require.NoError(t, err)
+	_, err = contactgrpc.AddNote(ctx, &pb.Note{ContactID: dup.Id, Body: "Prefers email"})
+	require.NoError(t, err)
+
+	pairs, err := contactgrpc.FindDuplicates(ctx, &pb.FindDuplicatesRequest{})
+	require.NoError(t, err)
+	require.Len(t, pairs.Pairs, 1)
+	assert.Subset(t, pairs.Pairs[0].Reasons, []string{"email", "phone"})
+
+	res, err := contactgrpc.MergeContacts(ctx, &pb.MergeContactsRequest{
+		SurvivorID:   jane.Id,
+		MergedIDs:    []int32{dup.Id},
+		FieldSources: map[string]int32{"address": dup.Id},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", res.Contact.Name)
+	assert.Equal(t, "12, Allen Avenue, Ikeja", res.Contact.Address)
+	assert.Equal(t, "Engineer", res.Contact.JobTitle)
+
+	_, err = contactgrpc.GetContactByID(ctx, &pb.FindContactRequest{Id: dup.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	timeline, err := contactgrpc.GetContactTimeline(ctx, &pb.TimelineRequest{ContactID: jane.Id})
+	require.NoError(t, err)
+	assert.NotEmpty(t, timeline.Entries)
+
+	restored, err := contactgrpc.UndoMerge(ctx, &pb.MergeRequest{MergeID: res.MergeID})
+	require.NoError(t, err)
+	require.Len(t, restored.Contacts, 2)
+	assert.Equal(t, "33, Tioya Street, Ibadan", restored.Contacts[0].Address)
+	assert.Equal(t, dup.Id, restored.Contacts[1].Id)
+
+	_, err = contactgrpc.UndoMerge(ctx, &pb.MergeRequest{MergeID: res.MergeID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = contactgrpc.MergeContacts(ctx, &pb.MergeContactsRequest{SurvivorID: jane.Id})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,236 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"grpc-contact-manager/services/changefeed"
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/ticket"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// resync is the action of the event telling clients that the changes since their event ID are no
+// longer known, so they must reload the contacts. Streaming goes on from the event's ID.
+const resync = "resync"
+
+// eventsRetry is the reconnection delay, in milliseconds, given to EventSource clients.
+const eventsRetry = 3000
+
+// eventsHeartbeat is how often an idle event stream is written to, so proxies keep it open and
+// lost clients are noticed.
+var eventsHeartbeat = 15 * time.Second
+
+var upgrader = websocket.Upgrader{}
+
+// ContactEvent is a change to one of the user's contacts sent on /contacts/events. ID is the
+// sync token to resume from after it.
+type ContactEvent struct {
+	ID        string           `json:"id"`
+	Action    string           `json:"action"`
+	ContactID uint             `json:"contact_id,omitempty"`
+	Contact   *contact.Contact `json:"contact,omitempty"`
+}
+
+// eventWriter sends events to a client over SSE or a WebSocket.
+type eventWriter interface {
+	send(e *ContactEvent) error
+	heartbeat() error
+}
+
+// contactEvents streams the changes to the user's contacts as server-sent events, or over a
+// WebSocket when the request asks for an upgrade. Clients resume after the event ID given in the
+// Last-Event-ID header or the lastEventId query parameter, and start from now without one.
+func (s *Server) contactEvents(c *gin.Context) {
+	userID := middlewares.UserID(c)
+	heartbeat := eventsHeartbeat
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// the upgrader has answered the request
+			return
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		w := &socketWriter{conn: conn, timeout: heartbeat}
+		conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+		})
+		go func() {
+			// reading handles the pongs and tells when the client went away
+			defer cancel()
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+		if err := s.streamEvents(ctx, userID, lastEventID, heartbeat, w); err != nil {
+			log.WithError(err).Debug("streaming contact events")
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseInternalServerErr, ""), time.Now().Add(time.Second))
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	w := &sseWriter{w: c.Writer}
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", eventsRetry); err != nil {
+		return
+	}
+	c.Writer.Flush()
+	if err := s.streamEvents(c.Request.Context(), userID, lastEventID, heartbeat, w); err != nil {
+		log.WithError(err).Debug("streaming contact events")
+	}
+}
+
+// contactEventsTicket issues a ticket to open /contacts/events with, passed in the ticket query
+// parameter by clients that can't set the Authorization header. It is valid once, for a short time.
+func (s *Server) contactEventsTicket(c *gin.Context) {
+	t, err := ticketDB.Issue(middlewares.UserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"ticket":     t,
+			"expires_in": int(ticket.TTL.Seconds()),
+		},
+	})
+}
+
+// streamEvents writes the changes to the user's contacts after lastEventID until ctx is done, and a
+// heartbeat after each idle interval. Like WatchContacts, it reads the change log a page at a time
+// whenever the feed tells of changes.
+func (s *Server) streamEvents(ctx context.Context, userID uint, lastEventID string, interval time.Duration, w eventWriter) error {
+	// subscribing first leaves no change unnoticed between reading the log and waiting
+	sub := s.Feed.Subscribe(changefeed.Event{Topic: changefeed.Contacts, UserID: userID})
+	defer sub.Close()
+
+	since, err := contactDB.LatestSeq(userID)
+	if err != nil {
+		return err
+	}
+	// an ID this server never sent can't be resumed from
+	stale := false
+	if lastEventID != "" {
+		seq, perr := strconv.ParseUint(lastEventID, 10, 64)
+		since, stale = seq, perr != nil
+	}
+
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	for {
+		var page *contact.EventPage
+		if !stale {
+			page, err = contactDB.Events(userID, since, watchPageSize)
+			stale = errors.Is(err, changelog.ErrResyncRequired)
+		}
+		if stale {
+			if since, err = contactDB.LatestSeq(userID); err != nil {
+				return err
+			}
+			if err := w.send(&ContactEvent{ID: formatSyncToken(since), Action: resync}); err != nil {
+				return err
+			}
+			stale = false
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for i := range page.Events {
+			if err := w.send(newContactEvent(&page.Events[i])); err != nil {
+				return err
+			}
+		}
+		since = page.Seq
+		if page.More {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.C:
+		case <-heartbeat.C:
+			if err := w.heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newContactEvent(e *contact.Event) *ContactEvent {
+	res := &ContactEvent{ID: formatSyncToken(e.Seq), Action: e.Action, ContactID: e.Contact.ID}
+	if e.Action != changelog.Delete {
+		res.Contact = &e.Contact
+	}
+	return res
+}
+
+// sseWriter writes server-sent events.
+type sseWriter struct {
+	w gin.ResponseWriter
+}
+
+func (w *sseWriter) send(e *ContactEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.w, "id: %s\ndata: %s\n\n", e.ID, data); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return nil
+}
+
+func (w *sseWriter) heartbeat() error {
+	// comments are ignored by EventSource
+	if _, err := fmt.Fprint(w.w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return nil
+}
+
+// socketWriter writes events as JSON text messages on a WebSocket.
+type socketWriter struct {
+	conn    *websocket.Conn
+	timeout time.Duration
+}
+
+func (w *socketWriter) send(e *ContactEvent) error {
+	w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	return w.conn.WriteJSON(e)
+}
+
+func (w *socketWriter) heartbeat() error {
+	return w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.timeout))
+}
@@ -0,0 +1,227 @@
+package servers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactEventsSSE(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ts := httptest.NewServer(s.Handler)
+	defer ts.Close()
+
+	w := doRequest(t, s.Handler, "POST", "/contacts/", token, strings.NewReader(
+		`{"name":"Alugbin Abiodun","email":"tolaabbey001@gmail.com","phone":"+2347033304280","address":"Ibadan"}`))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data struct {
+			ID uint `json:"ID"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// EventSource can't send headers, so a ticket is passed in the query
+	ticket := eventsTicket(t, s.Handler, token)
+	events := openEventStream(t, ts.URL+"/contacts/events?ticket="+ticket, "")
+	w = doRequest(t, s.Handler, "POST", "/contacts/", token, strings.NewReader(
+		`{"name":"Ada","email":"ada@example.com","phone":"1","address":"Lagos"}`))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	id, e := events.next(t)
+	assert.Equal(t, "create", e.Action)
+	assert.Equal(t, "Ada", e.Contact.Fullname)
+	assert.Equal(t, id, e.ID)
+	events.close()
+
+	// reconnecting with the last event ID replays what was missed meanwhile
+	w = doRequest(t, s.Handler, "PATCH", fmt.Sprintf("/contacts/%d", created.Data.ID), token, strings.NewReader(`{"phone":"08155040074"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	events = openEventStream(t, ts.URL+"/contacts/events", token, "Last-Event-ID", id)
+	_, e = events.next(t)
+	assert.Equal(t, "update", e.Action)
+	assert.Equal(t, created.Data.ID, e.ContactID)
+	assert.Equal(t, "08155040074", e.Contact.Phone)
+	events.close()
+
+	// an ID that can't be resumed from asks the client to reload
+	events = openEventStream(t, ts.URL+"/contacts/events", token, "Last-Event-ID", "1000000")
+	resumeID, e := events.next(t)
+	assert.Equal(t, resync, e.Action)
+	assert.NotEqual(t, "1000000", resumeID)
+	events.close()
+
+	// tickets are used once, and neither they nor tokens are taken from the query elsewhere
+	for _, path := range []string{
+		"/contacts/events",
+		"/contacts/events?ticket=" + ticket,
+		"/contacts/events?access_token=" + token,
+		"/contacts/?ticket=" + eventsTicket(t, s.Handler, token),
+	} {
+		res, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode, path)
+	}
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestContactEventsHeartbeat(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	heartbeat := eventsHeartbeat
+	eventsHeartbeat = 20 * time.Millisecond
+	t.Cleanup(func() { eventsHeartbeat = heartbeat })
+	ts := httptest.NewServer(s.Handler)
+	defer ts.Close()
+
+	events := openEventStream(t, ts.URL+"/contacts/events", token)
+	defer events.close()
+	line, err := events.r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": heartbeat\n", line)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestContactEventsWebSocket(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	heartbeat := eventsHeartbeat
+	eventsHeartbeat = 20 * time.Millisecond
+	t.Cleanup(func() { eventsHeartbeat = heartbeat })
+	ts := httptest.NewServer(s.Handler)
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/contacts/events?ticket=" + eventsTicket(t, s.Handler, token)
+	conn, res, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	res.Body.Close()
+	defer conn.Close()
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	received := make(chan ContactEvent)
+	go func() {
+		var e ContactEvent
+		if err := conn.ReadJSON(&e); err == nil {
+			received <- e
+		}
+		close(received)
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no heartbeat")
+	}
+	w := doRequest(t, s.Handler, "POST", "/contacts/", token, strings.NewReader(
+		`{"name":"Ada","email":"ada@example.com","phone":"1","address":"Lagos"}`))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	select {
+	case e, ok := <-received:
+		require.True(t, ok)
+		assert.Equal(t, "create", e.Action)
+		assert.Equal(t, "Ada", e.Contact.Fullname)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+// eventsTicket returns a ticket to open the event stream with.
+func eventsTicket(t *testing.T, handler http.Handler, token string) string {
+	w := doRequest(t, handler, "POST", "/contacts/events", token, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var res struct {
+		Data struct {
+			Ticket    string `json:"ticket"`
+			ExpiresIn int    `json:"expires_in"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.NotEmpty(t, res.Data.Ticket)
+	require.Positive(t, res.Data.ExpiresIn)
+	return res.Data.Ticket
+}
+
+// eventStream reads server-sent events.
+type eventStream struct {
+	r     *bufio.Reader
+	close func()
+}
+
+// openEventStream connects to url, authenticated with the token when one is given, and reads
+// the retry field sent first.
+func openEventStream(t *testing.T, url, token string, headers ...string) *eventStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	s := &eventStream{r: bufio.NewReader(res.Body), close: func() {
+		cancel()
+		res.Body.Close()
+	}}
+	line, err := s.r.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(line, "retry: "), line)
+	_, err = s.r.ReadString('\n')
+	require.NoError(t, err)
+	return s
+}
+
+// next returns the ID and data of the next event, skipping heartbeats.
+func (s *eventStream) next(t *testing.T) (string, ContactEvent) {
+	var id string
+	var e ContactEvent
+	for {
+		line, err := s.r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+		case line == "" && id != "":
+			return id, e
+		}
+	}
+}
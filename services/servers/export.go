@@ -0,0 +1,129 @@
+package servers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/export"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxExportBatchSize caps the rows ExportContacts reads at a time.
+const maxExportBatchSize = 5000
+
+// ExportContacts streams the contacts of the user, reading them in batches so memory use
+// stays flat however many there are. Send blocks while the client isn't reading, and the
+// export stops when the client cancels.
+func (c *ContactManagerGrpc) ExportContacts(in *pb.ExportRequest, stream pb.ContactManager_ExportContactsServer) error {
+	ctx := stream.Context()
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if in.AfterID < 0 || in.BatchSize < 0 || in.BatchSize > maxExportBatchSize {
+		return status.Error(codes.InvalidArgument, "invalid export request")
+	}
+	err = c.DB.Export(ctx, userID, uint(in.AfterID), int(in.BatchSize), func(batch []contact.Contact) error {
+		ids := make([]uint, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].ID
+		}
+		has, err := c.Photos.HasPhoto(ids...)
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			if err := stream.Send(toPBContact(&batch[i], has[batch[i].ID])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return err
+}
+
+// exportEncoded encodes the contacts of the user, reading them in batches. flush, when set, is
+// called after every batch so the output reaches the client as it is encoded.
+func (t *transfer) exportEncoded(ctx context.Context, userID uint, enc export.Encoder, flush func()) error {
+	names, err := t.organizationNames(userID)
+	if err != nil {
+		return err
+	}
+	err = t.contacts.Export(ctx, userID, 0, 0, func(batch []contact.Contact) error {
+		for i := range batch {
+			var organization string
+			if id := batch[i].OrganizationID; id != nil {
+				organization = names[*id]
+			}
+			if err := enc.Encode(&batch[i], organization); err != nil {
+				return err
+			}
+		}
+		if flush != nil {
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// exportFile downloads the contacts of the user in the format named by the format query
+// parameter, one of the registered export formats.
+func (s *Server) exportFile(c *gin.Context) {
+	f, err := export.Lookup(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	startDownload(c, f.ContentType+"; charset=utf-8", "contacts"+f.Extension)
+	enc := f.NewEncoder(c.Writer)
+	if err := s.restTransfer().exportEncoded(c.Request.Context(), middlewares.UserID(c), enc, c.Writer.Flush); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// ExportFile streams the contacts of the user as a file in the requested format, in chunks of up
+// to 32 KiB. It is an addition next to ExportContacts, whose stream of contact messages has no
+// file format to pick.
+func (c *ContactManagerGrpc) ExportFile(in *pb.FileExportRequest, stream pb.ContactManager_ExportFileServer) error {
+	ctx := stream.Context()
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := export.Lookup(in.Format)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	contentType := f.ContentType
+	w := bufio.NewWriterSize(chunkWriter(func(p []byte) error {
+		chunk := &pb.FileChunk{Data: p, ContentType: contentType}
+		contentType = ""
+		return stream.Send(chunk)
+	}), fileChunkSize)
+	err = c.transfer().exportEncoded(ctx, userID, f.NewEncoder(w), nil)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
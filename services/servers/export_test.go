@@ -0,0 +1,180 @@
+package servers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCExportContacts(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	_, err := contactgrpc.BatchCreateContacts(middlewares.ContextWithUserID(context.Background(), userID),
+		&pb.BatchCreateContactsRequest{Contacts: batchContacts(120)})
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+
+	export := func(ctx context.Context, in *pb.ExportRequest) ([]*pb.Contact, error) {
+		stream, err := cm.ExportContacts(ctx, in)
+		require.NoError(t, err)
+		var contacts []*pb.Contact
+		for {
+			c, err := stream.Recv()
+			if err == io.EOF {
+				return contacts, nil
+			}
+			if err != nil {
+				return contacts, err
+			}
+			contacts = append(contacts, c)
+		}
+	}
+
+	contacts, err := export(ctx, &pb.ExportRequest{BatchSize: 50})
+	require.NoError(t, err)
+	require.Len(t, contacts, 120)
+	for i := 1; i < len(contacts); i++ {
+		assert.Less(t, contacts[i-1].Id, contacts[i].Id)
+	}
+
+	resumed, err := export(ctx, &pb.ExportRequest{AfterID: contacts[99].Id})
+	require.NoError(t, err)
+	require.Len(t, resumed, 20)
+	assert.Equal(t, contacts[100].Id, resumed[0].Id)
+
+	_, err = export(ctx, &pb.ExportRequest{BatchSize: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the export stops once the client goes away
+	cancelled, cancel := context.WithCancel(middlewares.ContextWithUserID(context.Background(), userID))
+	defer cancel()
+	fake := &exportStream{ctx: cancelled, onSend: cancel}
+	err = contactgrpc.ExportContacts(&pb.ExportRequest{BatchSize: 10}, fake)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, 10, fake.sent)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestExportFile(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	_, err = contactgrpc.BatchCreateContacts(middlewares.ContextWithUserID(context.Background(), userID),
+		&pb.BatchCreateContactsRequest{Contacts: batchContacts(3)})
+	require.NoError(t, err)
+
+	w := doRequest(t, s.Handler, "GET", "/contacts/export?format=jsonl", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "contacts.jsonl")
+	assert.True(t, w.Flushed)
+	scanner := bufio.NewScanner(w.Body)
+	var names []string
+	for scanner.Scan() {
+		var c struct {
+			Fullname string `json:"full_name"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &c))
+		names = append(names, c.Fullname)
+	}
+	assert.Equal(t, []string{"Contact 0", "Contact 1", "Contact 2"}, names)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export?format=ldif", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, strings.HasPrefix(w.Body.String(), "version: 1\n\ndn: cn=Contact 0,mail=contact0@gmail.com\n"))
+	assert.Equal(t, 3, strings.Count(w.Body.String(), "objectClass: inetOrgPerson"))
+
+	// the vCard and CSV exports are formats too
+	w = doRequest(t, s.Handler, "GET", "/contacts/export?format=vcard", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "contacts.vcf")
+	assert.Equal(t, 3, strings.Count(w.Body.String(), "BEGIN:VCARD"))
+	w = doRequest(t, s.Handler, "GET", "/contacts/export?format=csv", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 4, strings.Count(w.Body.String(), "\n"))
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export?format=xml", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "csv, jsonl, ldif, vcard")
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCExportFile(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	_, err := contactgrpc.BatchCreateContacts(middlewares.ContextWithUserID(context.Background(), userID),
+		&pb.BatchCreateContactsRequest{Contacts: batchContacts(120)})
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+
+	stream, err := cm.ExportFile(ctx, &pb.FileExportRequest{Format: "ldif"})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	var chunks []*pb.FileChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(chunk.Data), fileChunkSize)
+		chunks = append(chunks, chunk)
+		buf.Write(chunk.Data)
+	}
+	require.Greater(t, len(chunks), 1)
+	assert.Equal(t, "text/x-ldif", chunks[0].ContentType)
+	assert.Empty(t, chunks[1].ContentType)
+	assert.Equal(t, 120, strings.Count(buf.String(), "\ndn: "))
+
+	stream, err = cm.ExportFile(ctx, &pb.FileExportRequest{Format: "pdf"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+// exportStream is a server side ExportContacts stream calling onSend for every contact sent.
+type exportStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	onSend func()
+	sent   int
+}
+
+func (s *exportStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *exportStream) Send(*pb.Contact) error {
+	s.sent++
+	s.onSend()
+	return nil
+}
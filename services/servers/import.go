@@ -0,0 +1,66 @@
+package servers
+
+import (
+	"io"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// duplicatePolicyKey is the metadata key choosing what ImportContacts does with duplicates.
+const duplicatePolicyKey = "duplicate-policy"
+
+// ImportContacts creates the streamed contacts, validating them as they arrive and writing
+// them in chunks, and reports how every row went.
+func (c *ContactManagerGrpc) ImportContacts(stream pb.ContactManager_ImportContactsServer) error {
+	userID, err := authUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+	im, err := c.DB.NewImporter(userID, duplicatePolicy(stream.Context()))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		err = im.Add(contact.Contact{
+			Fullname:       in.Name,
+			Email:          in.Email,
+			Phone:          in.Phone,
+			Address:        in.Address,
+			OrganizationID: organizationID(in.OrganizationID),
+			JobTitle:       in.JobTitle,
+			Department:     in.Department,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	summary, err := im.Close()
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(toPBImportSummary(summary))
+}
+
+func toPBImportSummary(summary *contact.ImportSummary) *pb.ImportSummary {
+	res := &pb.ImportSummary{
+		Created: int32(summary.Created),
+		Updated: int32(summary.Updated),
+		Skipped: int32(summary.Skipped),
+		Failed:  int32(summary.Failed),
+	}
+	for _, e := range summary.Errors {
+		res.Errors = append(res.Errors, &pb.ImportError{Row: int32(e.Row), Error: e.Err.Error()})
+	}
+	return res
+}
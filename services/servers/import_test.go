@@ -0,0 +1,67 @@
+package servers
+
+import (
+	"context"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCImportContacts(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	existing := createContact(t, userID, "contact0@gmail.com")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+	db := &contact.DB{Conn: server.Conn}
+
+	importContacts := func(ctx context.Context, contacts []*pb.Contact) (*pb.ImportSummary, error) {
+		stream, err := cm.ImportContacts(ctx)
+		require.NoError(t, err)
+		for _, c := range contacts {
+			if err := stream.Send(c); err != nil {
+				// the server ended the stream, CloseAndRecv returns why
+				break
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+
+	contacts := batchContacts(250)
+	contacts[0].Phone = "08155040074"
+	contacts[10].Phone = ""
+	summary, err := importContacts(ctx, contacts)
+	require.NoError(t, err)
+	assert.Equal(t, int32(248), summary.Created)
+	assert.Equal(t, int32(1), summary.Skipped)
+	assert.Equal(t, int32(1), summary.Failed)
+	require.Len(t, summary.Errors, 1)
+	assert.Equal(t, int32(11), summary.Errors[0].Row)
+
+	found, err := db.FindByID(userID, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, existing.Phone, found.Phone)
+
+	update := metadata.AppendToOutgoingContext(ctx, duplicatePolicyKey, "update")
+	summary, err = importContacts(update, contacts[:1])
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), summary.Updated)
+	found, err = db.FindByID(userID, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "08155040074", found.Phone)
+
+	_, err = importContacts(metadata.AppendToOutgoingContext(ctx, duplicatePolicyKey, "replace"), contacts[:1])
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,148 @@
+package servers
+
+import (
+	"context"
+	"errors"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/organization"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (c *ContactManagerGrpc) CreateOrganization(ctx context.Context, in *pb.Organization) (*pb.Organization, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Orgs.Create(organization.Organization{
+		UserID:  userID,
+		Name:    in.Name,
+		Domain:  in.Domain,
+		Address: in.Address,
+		Phone:   in.Phone,
+		Notes:   in.Notes,
+	})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBOrganization(o), nil
+}
+
+func (c *ContactManagerGrpc) UpdateOrganization(ctx context.Context, in *pb.Organization) (*pb.Organization, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Orgs.Find(userID, uint(in.Id))
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	o.Name = in.Name
+	o.Domain = in.Domain
+	o.Address = in.Address
+	o.Phone = in.Phone
+	o.Notes = in.Notes
+	if err := c.Orgs.Update(o); err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBOrganization(o), nil
+}
+
+// DeleteOrganization removes an organization. Its contacts are kept without an organization.
+func (c *ContactManagerGrpc) DeleteOrganization(ctx context.Context, in *pb.Organization) (*pb.Organization, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.DB.DeleteOrganization(userID, uint(in.Id))
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBOrganization(o), nil
+}
+
+func (c *ContactManagerGrpc) ListOrganizations(ctx context.Context, in *pb.OrganizationRequest) (*pb.OrganizationList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	organizations, err := c.Orgs.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toPBOrganizationList(organizations), nil
+}
+
+// GetOrganizationContacts lists the people of an organization.
+func (c *ContactManagerGrpc) GetOrganizationContacts(ctx context.Context, in *pb.OrganizationRequest) (*pb.ContactList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Orgs.Find(userID, uint(in.Id))
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	contacts, err := c.DB.FindByOrganization(userID, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ContactList{}
+	for i := range contacts {
+		res.Contacts = append(res.Contacts, toPBContact(&contacts[i], false))
+	}
+	return res, nil
+}
+
+// SuggestOrganizations suggests organizations for a contact from the domain of their email.
+func (c *ContactManagerGrpc) SuggestOrganizations(ctx context.Context, in *pb.SuggestOrganizationsRequest) (*pb.OrganizationList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	organizations, err := c.Orgs.Suggest(userID, in.Email)
+	if err != nil {
+		return nil, err
+	}
+	return toPBOrganizationList(organizations), nil
+}
+
+func organizationError(err error) error {
+	switch {
+	case errors.Is(err, organization.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, organization.ErrExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// organizationID converts an optional organization ID from a request.
+func organizationID(id int32) *uint {
+	if id <= 0 {
+		return nil
+	}
+	res := uint(id)
+	return &res
+}
+
+func toPBOrganization(o *organization.Organization) *pb.Organization {
+	return &pb.Organization{
+		Id:      int32(o.ID),
+		Name:    o.Name,
+		Domain:  o.Domain,
+		Address: o.Address,
+		Phone:   o.Phone,
+		Notes:   o.Notes,
+	}
+}
+
+func toPBOrganizationList(organizations []organization.Organization) *pb.OrganizationList {
+	res := &pb.OrganizationList{}
+	for i := range organizations {
+		res.Organizations = append(res.Organizations, toPBOrganization(&organizations[i]))
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package servers
+
+import (
+	"context"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCOrganizations(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+
+	suggested, err := contactgrpc.SuggestOrganizations(ctx, &pb.SuggestOrganizationsRequest{Email: "jane@acme.com"})
+	require.NoError(t, err)
+	require.Len(t, suggested.Organizations, 1)
+	assert.Zero(t, suggested.Organizations[0].Id)
+
+	org, err := contactgrpc.CreateOrganization(ctx, suggested.Organizations[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", org.Name)
+	assert.Equal(t, "acme.com", org.Domain)
+
+	_, err = contactgrpc.CreateOrganization(ctx, &pb.Organization{Name: "Acme Again", Domain: "acme.com"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	jane, err := contactgrpc.NewContact(ctx, &pb.Contact{
+		Name:     "Jane Doe",
+		Email:    "jane@acme.com",
+		Phone:    "+2347033304280",
+		Address:  "33, Tioya Street, Ibadan",
+		JobTitle: "Engineer",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, org.Id, jane.OrganizationID)
+	assert.Equal(t, "Engineer", jane.JobTitle)
+
+	people, err := contactgrpc.GetOrganizationContacts(ctx, &pb.OrganizationRequest{Id: org.Id})
+	require.NoError(t, err)
+	require.Len(t, people.Contacts, 1)
+	assert.Equal(t, jane.Id, people.Contacts[0].Id)
+
+	_, err = contactgrpc.DeleteOrganization(ctx, &pb.Organization{Id: org.Id})
+	require.NoError(t, err)
+	found, err := contactgrpc.GetContactByID(ctx, &pb.FindContactRequest{Id: jane.Id})
+	require.NoError(t, err)
+	assert.Zero(t, found.OrganizationID)
+
+	list, err := contactgrpc.ListOrganizations(ctx, &pb.OrganizationRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Organizations)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
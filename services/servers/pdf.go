@@ -0,0 +1,141 @@
+package servers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/pdf"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidSelection = errors.New("ids and organization_id must be numbers")
+
+// selectedContacts returns the contacts picked by the query: those of the organization given by
+// organization_id, those matching search, or all of them, narrowed down to ids when given.
+func (s *Server) selectedContacts(c *gin.Context) ([]contact.Contact, error) {
+	userID := middlewares.UserID(c)
+	var ids map[uint]bool
+	if v := c.Query("ids"); v != "" {
+		ids = map[uint]bool{}
+		for _, id := range strings.Split(v, ",") {
+			n, err := strconv.ParseUint(strings.TrimSpace(id), 10, 32)
+			if err != nil {
+				return nil, errInvalidSelection
+			}
+			ids[uint(n)] = true
+		}
+	}
+	var (
+		contacts []contact.Contact
+		err      error
+	)
+	if v := c.Query("organization_id"); v != "" {
+		orgID, perr := strconv.ParseUint(v, 10, 32)
+		if perr != nil {
+			return nil, errInvalidSelection
+		}
+		contacts, err = contactDB.FindByOrganization(userID, uint(orgID))
+	} else if search := c.Query("search"); search != "" {
+		contacts, err = contactDB.Search(uint32(userID), search)
+	} else {
+		contacts, err = contactDB.FindByUserID(uint32(userID))
+	}
+	if err != nil || ids == nil {
+		return contacts, err
+	}
+	selected := contacts[:0]
+	for _, ct := range contacts {
+		if ids[ct.ID] {
+			selected = append(selected, ct)
+		}
+	}
+	return selected, nil
+}
+
+// exportPDF downloads the selected contacts, see selectedContacts, as a PDF document. The
+// layout query parameter is "sheet" for a contact sheet headed by title, or "labels" for
+// mailing labels on the sheets of template, leaving the first skip labels blank.
+func (s *Server) exportPDF(c *gin.Context) {
+	badRequest := func(err error) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	layout := c.DefaultQuery("layout", "sheet")
+	if layout != "sheet" && layout != "labels" {
+		badRequest(errors.New(`layout must be "sheet" or "labels"`))
+		return
+	}
+	var tmpl pdf.Template
+	skip := 0
+	if layout == "labels" {
+		var err error
+		if tmpl, err = pdf.LookupTemplate(c.Query("template")); err != nil {
+			badRequest(err)
+			return
+		}
+		if v := c.Query("skip"); v != "" {
+			if skip, err = strconv.Atoi(v); err != nil {
+				badRequest(pdf.ErrInvalidSkip)
+				return
+			}
+		}
+	}
+	contacts, err := s.selectedContacts(c)
+	if errors.Is(err, errInvalidSelection) {
+		badRequest(err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	userID := middlewares.UserID(c)
+	names, err := s.restTransfer().organizationNames(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	entries := make([]pdf.Entry, len(contacts))
+	for i, ct := range contacts {
+		entries[i] = pdf.Entry{Contact: ct}
+		if ct.OrganizationID != nil {
+			entries[i].Organization = names[*ct.OrganizationID]
+		}
+	}
+
+	var doc *pdf.Document
+	if layout == "labels" {
+		doc, err = pdf.Labels(tmpl, skip, entries)
+	} else {
+		doc, err = pdf.ContactSheet(c.DefaultQuery("title", "Contacts"), entries)
+	}
+	if errors.Is(err, pdf.ErrInvalidSkip) {
+		badRequest(err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	startDownload(c, pdf.ContentType, "contacts.pdf")
+	if _, err := doc.WriteTo(c.Writer); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,45 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPDF(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	res, err := contactgrpc.BatchCreateContacts(middlewares.ContextWithUserID(context.Background(), userID),
+		&pb.BatchCreateContactsRequest{Contacts: batchContacts(3)})
+	require.NoError(t, err)
+
+	for _, path := range []string{
+		"/contacts/export.pdf",
+		"/contacts/export.pdf?layout=sheet&title=Holiday%20cards&search=contact1",
+		fmt.Sprintf("/contacts/export.pdf?layout=labels&template=avery5163&skip=3&ids=%d,%d", res.Results[0].Contact.Id, res.Results[2].Contact.Id),
+	} {
+		w := doRequest(t, s.Handler, "GET", path, token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "contacts.pdf")
+		assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF-"), path)
+	}
+
+	for _, query := range []string{"layout=poster", "layout=labels&template=avery1", "layout=labels&skip=30", "layout=labels&skip=x", "ids=a", "organization_id=x"} {
+		w := doRequest(t, s.Handler, "GET", "/contacts/export.pdf?"+query, token, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,242 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/photo"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// formOverhead is the room left around an uploaded file for the framing of its multipart form.
+const formOverhead = 64 << 10
+
+var (
+	errPhotoTooLarge = errors.New("photo is too large")
+)
+
+// limitForm caps the body of the request before its form is parsed, so a file larger than size
+// is refused while it's read instead of being buffered to disk first.
+func limitForm(c *gin.Context, size int64) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, size+formOverhead)
+}
+
+// bodyTooLarge tells if the error comes from reading past the limit set by limitForm.
+func bodyTooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "request body too large")
+}
+
+func (s *Server) contactPhoto(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	p, err := photoDB.Find(found.UserID, found.ID)
+	if errors.Is(err, photo.ErrNoPhoto) {
+		c.Data(http.StatusOK, photo.InitialsContentType, photo.InitialsSVG(found.Fullname))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	thumbnail, _ := strconv.ParseBool(c.Query("thumbnail"))
+	r, err := photoDB.Open(p, thumbnail)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	defer r.Close()
+
+	if thumbnail {
+		c.DataFromReader(http.StatusOK, -1, photo.ThumbnailContentType, r, nil)
+		return
+	}
+	c.DataFromReader(http.StatusOK, p.Size, p.ContentType, r, nil)
+}
+
+func (s *Server) uploadContactPhoto(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	limitForm(c, photo.MaxSize)
+	file, err := c.FormFile("photo")
+	if err != nil && !bodyTooLarge(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil || file.Size > photo.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   errPhotoTooLarge.Error(),
+		})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := photoDB.Save(found.UserID, found.ID, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	contactDB.Publish(found.UserID)
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Photo uploaded successfully",
+		"data":    p,
+	})
+}
+
+func (s *Server) deleteContactPhoto(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	if err := photoDB.Delete(middlewares.UserID(c), found.ID); err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, photo.ErrNoPhoto) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	contactDB.Publish(found.UserID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Photo deleted successfully",
+	})
+}
+
+// UploadContactPhoto receives a photo in chunks. The first chunk names the contact.
+func (c *ContactManagerGrpc) UploadContactPhoto(stream pb.ContactManager_UploadContactPhotoServer) error {
+	var (
+		contactID int32
+		buf       bytes.Buffer
+	)
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if contactID == 0 {
+			contactID = chunk.ContactID
+		}
+		if buf.Len()+len(chunk.Data) > photo.MaxSize {
+			return status.Error(codes.InvalidArgument, errPhotoTooLarge.Error())
+		}
+		buf.Write(chunk.Data)
+	}
+
+	found, err := c.findContact(stream.Context(), contactID)
+	if err != nil {
+		return err
+	}
+	p, err := c.Photos.Save(found.UserID, found.ID, buf.Bytes())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	c.DB.Publish(found.UserID)
+	return stream.SendAndClose(toPBPhoto(p, nil))
+}
+
+// GetContactPhoto returns the photo of the contact, or the generated initials avatar when it has none.
+func (c *ContactManagerGrpc) GetContactPhoto(ctx context.Context, in *pb.PhotoRequest) (*pb.Photo, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.Photos.Find(found.UserID, found.ID)
+	if errors.Is(err, photo.ErrNoPhoto) {
+		svg := photo.InitialsSVG(found.Fullname)
+		return &pb.Photo{
+			ContactID:   int32(found.ID),
+			ContentType: photo.InitialsContentType,
+			Size:        int64(len(svg)),
+			Data:        svg,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := c.Photos.Open(p, in.Thumbnail)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	res := toPBPhoto(p, data)
+	if in.Thumbnail {
+		res.ContentType = photo.ThumbnailContentType
+		res.Size = int64(len(data))
+	}
+	return res, nil
+}
+
+func (c *ContactManagerGrpc) DeleteContactPhoto(ctx context.Context, in *pb.PhotoRequest) (*pb.Photo, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.Photos.Find(found.UserID, found.ID)
+	if errors.Is(err, photo.ErrNoPhoto) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Photos.Delete(found.UserID, found.ID); err != nil {
+		return nil, err
+	}
+	c.DB.Publish(found.UserID)
+	return toPBPhoto(p, nil), nil
+}
+
+func toPBPhoto(p *photo.Photo, data []byte) *pb.Photo {
+	return &pb.Photo{
+		ContactID:   int32(p.ContactID),
+		ContentType: p.ContentType,
+		Size:        p.Size,
+		Data:        data,
+	}
+}
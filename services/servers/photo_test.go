@@ -0,0 +1,158 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/photo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUploadContactPhoto(t *testing.T) {
+	ctx := context.Background()
+	s, err := server.StartHttp(ctx, ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	path := fmt.Sprintf("/contacts/%d/photo", c.ID)
+
+	// no photo yet, so we get the initials
+	w := doRequest(t, s.Handler, "GET", path, token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, photo.InitialsContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), ">AA</text>")
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("photo", "me.png")
+	require.NoError(t, err)
+	_, err = fw.Write(testPNG(t))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req, err := http.NewRequest("POST", path, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	s.Handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// oversized uploads are refused while the form is read
+	body.Reset()
+	mw = multipart.NewWriter(&body)
+	fw, err = mw.CreateFormFile("photo", "big.png")
+	require.NoError(t, err)
+	_, err = fw.Write(make([]byte, photo.MaxSize+2*formOverhead))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	req, err = http.NewRequest("POST", path, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	s.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+
+	w = doRequest(t, s.Handler, "GET", path, token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, testPNG(t), w.Body.Bytes())
+
+	w = doRequest(t, s.Handler, "GET", path+"?thumbnail=true", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	thumb, _, err := image.Decode(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, photo.ThumbnailSize, thumb.Bounds().Dx())
+
+	w = doRequest(t, s.Handler, "DELETE", path, token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(t, s.Handler, "DELETE", path, token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCUploadContactPhoto(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	data := testPNG(t)
+	cm := pb.NewContactManagerClient(client)
+	stream, err := cm.UploadContactPhoto(ctx)
+	require.NoError(t, err)
+	for i := 0; i < len(data); i += 100 {
+		end := i + 100
+		if end > len(data) {
+			end = len(data)
+		}
+		require.NoError(t, stream.Send(&pb.PhotoChunk{ContactID: int32(c.ID), Data: data[i:end]}))
+	}
+	res, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), res.Size)
+	assert.Equal(t, "image/png", res.ContentType)
+
+	got, err := cm.GetContactPhoto(ctx, &pb.PhotoRequest{ContactID: int32(c.ID)})
+	require.NoError(t, err)
+	assert.Equal(t, data, got.Data)
+
+	found, err := contactgrpc.GetContactByID(middlewares.ContextWithUserID(context.Background(), userID), &pb.FindContactRequest{Id: int32(c.ID)})
+	require.NoError(t, err)
+	assert.True(t, found.HasPhoto)
+
+	_, err = cm.DeleteContactPhoto(ctx, &pb.PhotoRequest{ContactID: int32(c.ID)})
+	require.NoError(t, err)
+	got, err = cm.GetContactPhoto(ctx, &pb.PhotoRequest{ContactID: int32(c.ID)})
+	require.NoError(t, err)
+	assert.Equal(t, photo.InitialsContentType, got.ContentType)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func createContact(t *testing.T, userID uint, email string) *contact.Contact {
+	db := &contact.DB{Conn: server.Conn}
+	require.NoError(t, db.Migrate())
+	c, err := db.Create(contact.Contact{
+		UserID:   userID,
+		Fullname: "Alugbin Abiodun",
+		Email:    email,
+		Phone:    "+2347033304280",
+		Address:  "33, Tioya Street, Ibadan",
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func testPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 48; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 5), B: 80, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
@@ -0,0 +1,194 @@
+package servers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/qr"
+	"grpc-contact-manager/services/vcard"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultQRSize and maxQRSize bound the side of QR code images, in pixels.
+	defaultQRSize = 256
+	maxQRSize     = 2048
+	// maxScanSize caps the images QR codes are read from.
+	maxScanSize = 10 << 20
+)
+
+var (
+	errInvalidQRFormat = errors.New(`format must be "png" or "svg"`)
+	errInvalidQRSize   = errors.New("size must be a number of pixels up to 2048")
+	errNoCard          = errors.New("QR code holds no vcard")
+	errImageTooLarge   = errors.New("image is too large")
+)
+
+// contactQR draws a QR code of the contact as a vCard 3.0, which phone cameras read, without
+// its photo. The format query parameter is png, with size pixels on a side, or svg.
+func (s *Server) contactQR(c *gin.Context) {
+	found, ok := s.contactFromParam(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "png")
+	if format != "png" && format != "svg" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   errInvalidQRFormat.Error(),
+		})
+		return
+	}
+	size := defaultQRSize
+	if v := c.Query("size"); v != "" {
+		var err error
+		if size, err = strconv.Atoi(v); err != nil || size < 1 || size > maxQRSize {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   errInvalidQRSize.Error(),
+			})
+			return
+		}
+	}
+
+	e := &vcard.Entry{Contact: *found}
+	if found.OrganizationID != nil {
+		org, err := s.restTransfer().orgs.Find(found.UserID, *found.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		e.Organization = org.Name
+	}
+	var card bytes.Buffer
+	enc, err := vcard.NewEncoder(&card, "3.0")
+	if err == nil {
+		err = enc.Encode(e)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	code, err := qr.Encode(card.String())
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	var img bytes.Buffer
+	contentType := qr.PNGContentType
+	if format == "svg" {
+		contentType = qr.SVGContentType
+		err = code.SVG(&img)
+	} else {
+		err = code.PNG(&img, size)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", etag(found))
+	c.Data(http.StatusOK, contentType, img.Bytes())
+}
+
+// scanContact creates a contact from the vCard in the QR code of an uploaded image, sent as the
+// image field of a form or as the request body.
+func (s *Server) scanContact(c *gin.Context) {
+	fail := func(code int, err error) {
+		c.JSON(code, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	var body io.Reader = c.Request.Body
+	if mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); mediaType == "multipart/form-data" {
+		limitForm(c, maxScanSize)
+		header, err := c.FormFile("image")
+		if err != nil && !bodyTooLarge(err) {
+			fail(http.StatusBadRequest, err)
+			return
+		}
+		if err != nil || header.Size > maxScanSize {
+			fail(http.StatusRequestEntityTooLarge, errImageTooLarge)
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			fail(http.StatusInternalServerError, err)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+	text, err := qr.Decode(io.LimitReader(body, maxScanSize))
+	if err != nil {
+		fail(http.StatusBadRequest, err)
+		return
+	}
+	e, err := vcard.NewDecoder(strings.NewReader(text)).Decode()
+	if err == io.EOF {
+		err = errNoCard
+	}
+	if err != nil {
+		fail(http.StatusBadRequest, err)
+		return
+	}
+
+	userID := middlewares.UserID(c)
+	t := s.restTransfer()
+	var newContact *contact.Contact
+	err = contactDB.Transaction(func(tx *contact.DB) error {
+		c := contact.Contact{
+			UserID:     userID,
+			Fullname:   e.Contact.Fullname,
+			Email:      e.Contact.Email,
+			Phone:      e.Contact.Phone,
+			Address:    e.Contact.Address,
+			JobTitle:   e.Contact.JobTitle,
+			Department: e.Contact.Department,
+		}
+		if err := tx.ResolveOrganization(&c, e.Organization); err != nil {
+			return err
+		}
+		newContact, err = tx.Create(c)
+		return err
+	})
+	if err != nil {
+		fail(http.StatusBadRequest, err)
+		return
+	}
+	if len(e.Photo) > 0 {
+		if _, err := t.photos.Save(userID, newContact.ID, e.Photo); err != nil {
+			_ = c.Error(err)
+		} else {
+			t.contacts.Publish(userID)
+		}
+	}
+	c.Header("ETag", etag(newContact))
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Contact created successfully",
+		"data":    newContact,
+	})
+}
@@ -0,0 +1,102 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactQR(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	res, err := contactgrpc.BatchCreateContacts(middlewares.ContextWithUserID(context.Background(), userID),
+		&pb.BatchCreateContactsRequest{Contacts: []*pb.Contact{{
+			Name:     "Adébáyọ̀ Bello",
+			Email:    "ade@acme.com",
+			Phone:    "+2347033304280",
+			Address:  "33, Tioya Street; Ibadan",
+			JobTitle: "Engineer",
+		}}})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/contacts/%d/qr", res.Results[0].Contact.Id)
+
+	w := doRequest(t, s.Handler, "GET", path+"?size=300", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	code := w.Body.Bytes()
+	img, err := png.Decode(bytes.NewReader(code))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, img.Bounds().Dx(), 300)
+
+	w = doRequest(t, s.Handler, "GET", path+"?format=svg", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "<svg"))
+
+	for _, query := range []string{"?format=gif", "?size=0", "?size=5000"} {
+		w = doRequest(t, s.Handler, "GET", path+query, token, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// the contact still exists
+	w = doRequest(t, s.Handler, "POST", "/contacts/scan", token, bytes.NewReader(code), "Content-Type", "image/png")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, cleanupContacts(server.Conn))
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("image", "card.png")
+	require.NoError(t, err)
+	_, err = fw.Write(code)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	w = doRequest(t, s.Handler, "POST", "/contacts/scan", token, &form, "Content-Type", mw.FormDataContentType())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	resp := struct {
+		Data struct {
+			Fullname string `json:"full_name"`
+			Email    string `json:"email"`
+			Phone    string `json:"phone"`
+			Address  string `json:"address"`
+			JobTitle string `json:"job_title"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Adébáyọ̀ Bello", resp.Data.Fullname)
+	assert.Equal(t, "ade@acme.com", resp.Data.Email)
+	assert.Equal(t, "+2347033304280", resp.Data.Phone)
+	assert.Equal(t, "33, Tioya Street; Ibadan", resp.Data.Address)
+	assert.Equal(t, "Engineer", resp.Data.JobTitle)
+
+	// oversized forms are refused while they are read
+	form.Reset()
+	mw = multipart.NewWriter(&form)
+	fw, err = mw.CreateFormFile("image", "huge.png")
+	require.NoError(t, err)
+	_, err = fw.Write(make([]byte, maxScanSize+2*formOverhead))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	w = doRequest(t, s.Handler, "POST", "/contacts/scan", token, &form, "Content-Type", mw.FormDataContentType())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+
+	w = doRequest(t, s.Handler, "POST", "/contacts/scan", token, strings.NewReader("not an image"), "Content-Type", "image/png")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "png, jpeg or gif")
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,112 @@
+package servers
+
+import (
+	"context"
+	"errors"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/relationship"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	relationshipTypes = map[pb.RelationshipType]string{
+		pb.RelationshipType_RELATIONSHIP_SPOUSE:    relationship.Spouse,
+		pb.RelationshipType_RELATIONSHIP_PARENT:    relationship.Parent,
+		pb.RelationshipType_RELATIONSHIP_CHILD:     relationship.Child,
+		pb.RelationshipType_RELATIONSHIP_MANAGER:   relationship.Manager,
+		pb.RelationshipType_RELATIONSHIP_REPORT:    relationship.Report,
+		pb.RelationshipType_RELATIONSHIP_ASSISTANT: relationship.Assistant,
+		pb.RelationshipType_RELATIONSHIP_EXECUTIVE: relationship.Executive,
+		pb.RelationshipType_RELATIONSHIP_COLLEAGUE: relationship.Colleague,
+		pb.RelationshipType_RELATIONSHIP_CUSTOM:    relationship.Custom,
+	}
+)
+
+// LinkContacts relates two of the user's contacts.
+func (c *ContactManagerGrpc) LinkContacts(ctx context.Context, in *pb.LinkContactsRequest) (*pb.RelatedContact, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	related, err := c.findContact(ctx, in.RelatedID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.Relations.Link(relationship.Relationship{
+		UserID:       found.UserID,
+		ContactID:    found.ID,
+		RelatedID:    related.ID,
+		Type:         relationshipTypes[in.Type],
+		Label:        in.Label,
+		InverseLabel: in.InverseLabel,
+		Reciprocal:   in.Reciprocal,
+	})
+	if errors.Is(err, relationship.ErrExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toPBRelated(r, related), nil
+}
+
+// UnlinkContacts removes a relationship, and its reverse when it was linked reciprocally.
+func (c *ContactManagerGrpc) UnlinkContacts(ctx context.Context, in *pb.RelatedContact) (*pb.RelatedContact, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.Relations.Unlink(userID, uint(in.RelationshipID))
+	if errors.Is(err, relationship.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPBRelated(r, &contact.Contact{}), nil
+}
+
+// GetRelatedContacts walks the relationship graph from a contact, e.g. following REPORT
+// relationships to find everyone on a manager's team.
+func (c *ContactManagerGrpc) GetRelatedContacts(ctx context.Context, in *pb.RelatedContactsRequest) (*pb.RelatedContactList, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	for _, t := range in.Types {
+		types = append(types, relationshipTypes[t])
+	}
+	nodes, err := c.Relations.Walk(found.UserID, found.ID, int(in.Depth), types...)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.RelatedContactList{}
+	for i := range nodes {
+		related := toPBRelated(&nodes[i].Via, &nodes[i].Contact)
+		related.Depth = int32(nodes[i].Depth)
+		related.ViaContactID = int32(nodes[i].Via.ContactID)
+		res.Contacts = append(res.Contacts, related)
+	}
+	return res, nil
+}
+
+func toPBRelated(r *relationship.Relationship, related *contact.Contact) *pb.RelatedContact {
+	res := &pb.RelatedContact{
+		RelationshipID: int32(r.ID),
+		ContactID:      int32(r.RelatedID),
+		Name:           related.Fullname,
+		Label:          r.Label,
+		Reciprocal:     r.Reciprocal,
+	}
+	for t, name := range relationshipTypes {
+		if name == r.Type {
+			res.Type = t
+		}
+	}
+	return res
+}
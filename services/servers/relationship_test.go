@@ -0,0 +1,72 @@
+package servers
+
+import (
+	"context"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCRelationships(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+	jane := createContact(t, userID, "jane@example.com")
+	john := createContact(t, userID, "john@example.com")
+	peter := createContact(t, userID, "peter@example.com")
+
+	r, err := contactgrpc.LinkContacts(ctx, &pb.LinkContactsRequest{
+		ContactID:  int32(jane.ID),
+		RelatedID:  int32(john.ID),
+		Type:       pb.RelationshipType_RELATIONSHIP_REPORT,
+		Reciprocal: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(john.ID), r.ContactID)
+
+	_, err = contactgrpc.LinkContacts(ctx, &pb.LinkContactsRequest{
+		ContactID: int32(john.ID),
+		RelatedID: int32(peter.ID),
+		Type:      pb.RelationshipType_RELATIONSHIP_REPORT,
+	})
+	require.NoError(t, err)
+
+	_, err = contactgrpc.LinkContacts(ctx, &pb.LinkContactsRequest{
+		ContactID: int32(john.ID),
+		RelatedID: int32(peter.ID),
+		Type:      pb.RelationshipType_RELATIONSHIP_REPORT,
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	found, err := contactgrpc.GetContactByID(ctx, &pb.FindContactRequest{Id: int32(john.ID)})
+	require.NoError(t, err)
+	require.Len(t, found.Related, 2)
+	assert.Equal(t, pb.RelationshipType_RELATIONSHIP_MANAGER, found.Related[0].Type)
+	assert.Equal(t, int32(jane.ID), found.Related[0].ContactID)
+
+	team, err := contactgrpc.GetRelatedContacts(ctx, &pb.RelatedContactsRequest{
+		ContactID: int32(jane.ID),
+		Depth:     3,
+		Types:     []pb.RelationshipType{pb.RelationshipType_RELATIONSHIP_REPORT},
+	})
+	require.NoError(t, err)
+	require.Len(t, team.Contacts, 2)
+	assert.Equal(t, int32(peter.ID), team.Contacts[1].ContactID)
+	assert.Equal(t, int32(2), team.Contacts[1].Depth)
+	assert.Equal(t, int32(john.ID), team.Contacts[1].ViaContactID)
+
+	_, err = contactgrpc.UnlinkContacts(ctx, &pb.RelatedContact{RelationshipID: r.RelationshipID})
+	require.NoError(t, err)
+	found, err = contactgrpc.GetContactByID(ctx, &pb.FindContactRequest{Id: int32(john.ID)})
+	require.NoError(t, err)
+	assert.Len(t, found.Related, 1)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,126 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/reminder"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	repeatRules = map[pb.RepeatRule]string{
+		pb.RepeatRule_REPEAT_NEVER:   reminder.Never,
+		pb.RepeatRule_REPEAT_DAILY:   reminder.Daily,
+		pb.RepeatRule_REPEAT_WEEKLY:  reminder.Weekly,
+		pb.RepeatRule_REPEAT_MONTHLY: reminder.Monthly,
+		pb.RepeatRule_REPEAT_YEARLY:  reminder.Yearly,
+	}
+)
+
+// CreateReminder schedules a follow up on one of the user's contacts.
+func (c *ContactManagerGrpc) CreateReminder(ctx context.Context, in *pb.Reminder) (*pb.Reminder, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.Reminders.Create(reminder.Reminder{
+		UserID:         found.UserID,
+		ContactID:      found.ID,
+		DueAt:          unixTime(in.DueAt),
+		Repeat:         repeatRules[in.Repeat],
+		RepeatInterval: int(in.RepeatInterval),
+		Note:           in.Note,
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toPBReminder(r), nil
+}
+
+func (c *ContactManagerGrpc) ListReminders(ctx context.Context, in *pb.ReminderRequest) (*pb.ReminderList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := c.Reminders.FindByUserID(userID, uint(in.ContactID))
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ReminderList{}
+	for i := range reminders {
+		res.Reminders = append(res.Reminders, toPBReminder(&reminders[i]))
+	}
+	return res, nil
+}
+
+// UpdateReminder reschedules a reminder. The contact of a reminder can't be changed.
+func (c *ContactManagerGrpc) UpdateReminder(ctx context.Context, in *pb.Reminder) (*pb.Reminder, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.Reminders.Find(userID, uint(in.Id))
+	if err != nil {
+		return nil, reminderError(err)
+	}
+	r.DueAt = unixTime(in.DueAt)
+	r.Repeat = repeatRules[in.Repeat]
+	r.RepeatInterval = int(in.RepeatInterval)
+	r.Note = in.Note
+	if err := c.Reminders.Update(r); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toPBReminder(r), nil
+}
+
+func (c *ContactManagerGrpc) DeleteReminder(ctx context.Context, in *pb.Reminder) (*pb.Reminder, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.Reminders.Delete(userID, uint(in.Id))
+	if err != nil {
+		return nil, reminderError(err)
+	}
+	return toPBReminder(r), nil
+}
+
+func reminderError(err error) error {
+	if errors.Is(err, reminder.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+// unixTime converts a unix timestamp from a request, leaving unset timestamps as the zero time.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func toPBReminder(r *reminder.Reminder) *pb.Reminder {
+	res := &pb.Reminder{
+		Id:             int32(r.ID),
+		ContactID:      int32(r.ContactID),
+		DueAt:          r.DueAt.Unix(),
+		RepeatInterval: int32(r.RepeatInterval),
+		Note:           r.Note,
+		Done:           r.Done,
+	}
+	for rule, name := range repeatRules {
+		if name == r.Repeat {
+			res.Repeat = rule
+		}
+	}
+	if r.DeliveredAt != nil {
+		res.DeliveredAt = r.DeliveredAt.Unix()
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+package servers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCReminders(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+
+	due := time.Now().Add(14 * 24 * time.Hour).Unix()
+	r, err := contactgrpc.CreateReminder(ctx, &pb.Reminder{
+		ContactID: int32(c.ID),
+		DueAt:     due,
+		Note:      "Call Jane",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, due, r.DueAt)
+	assert.Equal(t, pb.RepeatRule_REPEAT_NEVER, r.Repeat)
+
+	_, err = contactgrpc.CreateReminder(ctx, &pb.Reminder{ContactID: int32(c.ID), Note: "No due time"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	r.Repeat = pb.RepeatRule_REPEAT_MONTHLY
+	updated, err := contactgrpc.UpdateReminder(ctx, r)
+	require.NoError(t, err)
+	assert.Equal(t, pb.RepeatRule_REPEAT_MONTHLY, updated.Repeat)
+
+	list, err := contactgrpc.ListReminders(ctx, &pb.ReminderRequest{ContactID: int32(c.ID)})
+	require.NoError(t, err)
+	require.Len(t, list.Reminders, 1)
+
+	other := middlewares.ContextWithUserID(context.Background(), userID+1)
+	_, err = contactgrpc.DeleteReminder(other, &pb.Reminder{Id: r.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = contactgrpc.DeleteReminder(ctx, &pb.Reminder{Id: r.Id})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
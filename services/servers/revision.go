@@ -0,0 +1,79 @@
+package servers
+
+import (
+	"context"
+	"errors"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ListContactRevisions returns the history of a contact, newest first. The history of deleted contacts is kept.
+func (c *ContactManagerGrpc) ListContactRevisions(ctx context.Context, in *pb.RevisionsRequest) (*pb.RevisionList, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := c.DB.Revisions(userID, uint(in.ContactID))
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, status.Error(codes.NotFound, "contact not found")
+	}
+	res := &pb.RevisionList{}
+	for i := range revisions {
+		r, err := toPBRevision(&revisions[i])
+		if err != nil {
+			return nil, err
+		}
+		res.Revisions = append(res.Revisions, r)
+	}
+	return res, nil
+}
+
+// RevertContact puts a contact back in the state it had right after the given revision,
+// restoring it if it was deleted.
+func (c *ContactManagerGrpc) RevertContact(ctx context.Context, in *pb.RevertContactRequest) (*pb.Contact, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reverted, err := c.DB.Revert(userID, uint(in.RevisionID))
+	switch {
+	case errors.Is(err, contact.ErrRevisionNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, contact.ErrNoSnapshot):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	hasPhoto, err := c.Photos.HasPhoto(reverted.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toPBContact(reverted, hasPhoto[reverted.ID]), nil
+}
+
+func toPBRevision(r *contact.Revision) (*pb.ContactRevision, error) {
+	changes, err := r.ChangeList()
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ContactRevision{
+		Id:        int32(r.ID),
+		ContactID: int32(r.ContactID),
+		Action:    r.Action,
+		Actor:     r.Actor,
+		CreatedAt: r.CreatedAt.Unix(),
+		Version:   int32(r.Version),
+	}
+	for _, c := range changes {
+		res.Changes = append(res.Changes, &pb.FieldChange{Field: c.Field, OldValue: c.Old, NewValue: c.New})
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package servers
+
+import (
+	"context"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCContactRevisions(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	contactID := int32(c.ID)
+
+	updated, err := contactgrpc.UpdateContact(ctx, &pb.Contact{
+		Id:      contactID,
+		Name:    c.Fullname,
+		Email:   c.Email,
+		Phone:   "08155040074",
+		Address: c.Address,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "08155040074", updated.Phone)
+
+	history, err := contactgrpc.ListContactRevisions(ctx, &pb.RevisionsRequest{ContactID: contactID})
+	require.NoError(t, err)
+	require.Len(t, history.Revisions, 2)
+	assert.Equal(t, "update", history.Revisions[0].Action)
+	assert.Equal(t, []*pb.FieldChange{{Field: "phone", OldValue: c.Phone, NewValue: "08155040074"}}, history.Revisions[0].Changes)
+
+	reverted, err := contactgrpc.RevertContact(ctx, &pb.RevertContactRequest{RevisionID: history.Revisions[1].Id})
+	require.NoError(t, err)
+	assert.Equal(t, c.Phone, reverted.Phone)
+
+	history, err = contactgrpc.ListContactRevisions(ctx, &pb.RevisionsRequest{ContactID: contactID})
+	require.NoError(t, err)
+	assert.Len(t, history.Revisions, 3)
+
+	other := middlewares.ContextWithUserID(context.Background(), userID+1)
+	_, err = contactgrpc.ListContactRevisions(other, &pb.RevisionsRequest{ContactID: contactID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = contactgrpc.RevertContact(other, &pb.RevertContactRequest{RevisionID: history.Revisions[1].Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
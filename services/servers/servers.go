@@ -6,7 +6,13 @@ import (
 	"net/http"
 
 	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/carddav"
+	"grpc-contact-manager/services/changefeed"
 	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/photo"
+	"grpc-contact-manager/services/storage"
+	"grpc-contact-manager/services/ticket"
 	"grpc-contact-manager/services/user"
 
 	"github.com/gin-gonic/gin"
@@ -18,11 +24,15 @@ var (
 	errServerEmpty    = errors.New("server not initialized")
 	errDatabaseNotSet = errors.New("database not set")
 	errRouterNotSet   = errors.New("router not set")
+	errStorageNotSet  = errors.New("storage not set")
 )
 
 var (
 	userDB    *user.DB
 	contactDB *contact.DB
+	photoDB   *photo.DB
+	carddavDB *carddav.DB
+	ticketDB  *ticket.DB
 )
 
 type Database interface {
@@ -33,16 +43,25 @@ type Database interface {
 
 // Server creates a struct to house the server elements.
 type Server struct {
-	Conn   *gorm.DB
-	Router *gin.Engine
+	Conn    *gorm.DB
+	Router  *gin.Engine
+	Storage storage.BlobStore
+	// Feed notifies the watchers of the changes made through both servers. It is in memory
+	// unless set before the servers start.
+	Feed changefeed.Feed
+	// AddressBookKey encrypts the passwords of external address books at rest.
+	AddressBookKey []byte
 }
 
 // New initialize a new server object
 func New(db *gorm.DB) (*Server, error) {
-	router := gin.Default()
+	// gin.Default with a logger keeping credentials out of the logs
+	router := gin.New()
+	router.Use(middlewares.Logger(), gin.Recovery())
 	return &Server{
 		Conn:   db,
 		Router: router,
+		Feed:   changefeed.NewMemory(),
 	}, nil
 }
 
@@ -56,6 +75,9 @@ func (s *Server) StartHttp(ctx context.Context, port string) (*http.Server, erro
 	if s.Router == nil {
 		return nil, errRouterNotSet
 	}
+	if s.Storage == nil {
+		return nil, errStorageNotSet
+	}
 	// initialize all db repositories
 	// s.userRoutes()
 	err := s.setupModels()
@@ -76,23 +98,60 @@ func (s *Server) setupModels() error {
 	if err != nil {
 		return err
 	}
+	p, err := photo.New(s.Conn, s.Storage)
+	if err != nil {
+		return err
+	}
+	d, err := carddav.New(s.Conn)
+	if err != nil {
+		return err
+	}
+	t, err := ticket.New(s.Conn)
+	if err != nil {
+		return err
+	}
+	u.Feed = s.Feed
+	c.Feed = s.Feed
 	userDB = u
 	contactDB = c
+	photoDB = p
+	carddavDB = d
+	ticketDB = t
 
 	if err := userDB.Migrate(); err != nil {
 		return err
 	}
+	if err := contactDB.Migrate(); err != nil {
+		return err
+	}
+	if err := carddavDB.Migrate(); err != nil {
+		return err
+	}
+	if err := ticketDB.Migrate(); err != nil {
+		return err
+	}
 
-	return contactDB.Migrate()
+	return photoDB.Migrate()
 }
 
 func (s *Server) StartUserGRPC(ctx context.Context) (*grpc.Server, error) {
-	userDB := &user.DB{Conn: s.Conn}
+	userDB := &user.DB{Conn: s.Conn, Feed: s.Feed}
 	if err := userDB.Migrate(); err != nil {
 		return nil, err
 	}
 	userGrpcServer := NewUserManagerGRPC(userDB)
-	gServer := grpc.NewServer()
+	contactGrpcServer, err := NewContactManagerGRPC(s.Conn, s.Storage, s.AddressBookKey)
+	if err != nil {
+		return nil, err
+	}
+	contactGrpcServer.DB.Feed = s.Feed
+
+	// only the contact service needs an authenticated user
+	gServer := grpc.NewServer(
+		grpc.UnaryInterceptor(middlewares.UnaryAuthInterceptor("contact.ContactManager")),
+		grpc.StreamInterceptor(middlewares.StreamAuthInterceptor("contact.ContactManager")),
+	)
 	pb.RegisterUserManagerServer(gServer, userGrpcServer)
+	pb.RegisterContactManagerServer(gServer, contactGrpcServer)
 	return gServer, nil
 }
@@ -1,19 +1,24 @@
 package servers
 
 import (
+	"bytes"
 	"log"
 	"os"
 	"testing"
 
+	"grpc-contact-manager/services/addressbook"
+	"grpc-contact-manager/services/storage"
 	"grpc-contact-manager/services/user"
 
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var (
-	server   *Server
-	usergrpc *UserManagerGrpc
+	server      *Server
+	usergrpc    *UserManagerGrpc
+	contactgrpc *ContactManagerGrpc
 )
 
 func TestMain(m *testing.M) {
@@ -25,10 +30,47 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	dir, err := os.MkdirTemp("", "storage")
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := storage.NewLocal(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.Storage = store
+	s.AddressBookKey = bytes.Repeat([]byte{7}, addressbook.KeySize)
 	server = s
 	usergrpc = &UserManagerGrpc{
 		DB: &user.DB{Conn: conn},
 	}
+	cg, err := NewContactManagerGRPC(conn, store, s.AddressBookKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// as StartUserGRPC does, the servers share the feed
+	cg.DB.Feed = s.Feed
+	contactgrpc = cg
 	server.UserRoutes()
-	os.Exit(m.Run())
+	server.ContactRoutes()
+	server.CardDAVRoutes()
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// authenticatedUser creates a user and returns its ID and bearer token.
+func authenticatedUser(t *testing.T, email string) (uint, string) {
+	db := &user.DB{Conn: server.Conn}
+	require.NoError(t, db.Migrate())
+	u, err := db.Create(user.User{
+		Name:     "Alugbin Abiodun",
+		Email:    email,
+		Password: "password",
+	})
+	require.NoError(t, err)
+	auth, err := db.Authenticate(email, "password")
+	require.NoError(t, err)
+	return u.ID, auth.Token
 }
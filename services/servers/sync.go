@@ -0,0 +1,149 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/changefeed"
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/contact"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchPageSize is the number of changes WatchContacts reads from the change log at a time.
+const watchPageSize = 100
+
+var errInvalidSyncToken = errors.New("invalid sync token")
+
+// SyncContacts returns the changes to the user's contacts since the sync token, or all of them when
+// it is empty. Clients must drop their copy and sync without a token on FAILED_PRECONDITION.
+func (c *ContactManagerGrpc) SyncContacts(ctx context.Context, in *pb.SyncContactsRequest) (*pb.ContactChanges, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cs *contact.ChangeSet
+	if in.SyncToken == "" {
+		cs, err = c.DB.FullSync(userID)
+	} else {
+		since, perr := parseSyncToken(in.SyncToken)
+		if perr != nil {
+			return nil, perr
+		}
+		cs, err = c.DB.Changes(userID, since)
+	}
+	if err != nil {
+		return nil, syncError(err)
+	}
+
+	ids := make([]uint, 0, len(cs.Created)+len(cs.Updated))
+	for _, list := range [][]contact.Contact{cs.Created, cs.Updated} {
+		for i := range list {
+			ids = append(ids, list[i].ID)
+		}
+	}
+	has, err := c.Photos.HasPhoto(ids...)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ContactChanges{SyncToken: formatSyncToken(cs.Seq)}
+	for i := range cs.Created {
+		res.Created = append(res.Created, toPBContact(&cs.Created[i], has[cs.Created[i].ID]))
+	}
+	for i := range cs.Updated {
+		res.Updated = append(res.Updated, toPBContact(&cs.Updated[i], has[cs.Updated[i].ID]))
+	}
+	for i := range cs.Deleted {
+		res.DeletedIDs = append(res.DeletedIDs, int32(cs.Deleted[i].ID))
+	}
+	return res, nil
+}
+
+// WatchContacts streams the changes to the user's contacts until the client goes away. The feed
+// only tells that changes were made: they are read from the change log a page at a time, so while
+// the client is slow to receive, notifications pile up into one and the log is read at its pace.
+func (c *ContactManagerGrpc) WatchContacts(in *pb.WatchContactsRequest, stream pb.ContactManager_WatchContactsServer) error {
+	ctx := stream.Context()
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return err
+	}
+	// subscribing first leaves no change unnoticed between reading the log and waiting
+	sub := c.DB.Feed.Subscribe(changefeed.Event{Topic: changefeed.Contacts, UserID: userID})
+	defer sub.Close()
+
+	var since uint64
+	if in.SyncToken == "" {
+		since, err = c.DB.LatestSeq(userID)
+	} else {
+		since, err = parseSyncToken(in.SyncToken)
+	}
+	if err != nil {
+		return err
+	}
+	for {
+		page, err := c.DB.Events(userID, since, watchPageSize)
+		if err != nil {
+			return syncError(err)
+		}
+		if err := c.sendEvents(stream, page.Events); err != nil {
+			return err
+		}
+		since = page.Seq
+		if page.More {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.C:
+		}
+	}
+}
+
+func (c *ContactManagerGrpc) sendEvents(stream pb.ContactManager_WatchContactsServer, events []contact.Event) error {
+	ids := make([]uint, len(events))
+	for i := range events {
+		ids[i] = events[i].Contact.ID
+	}
+	has, err := c.Photos.HasPhoto(ids...)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		e := &events[i]
+		res := &pb.ContactEvent{Action: e.Action, SyncToken: formatSyncToken(e.Seq)}
+		if e.Action == changelog.Delete {
+			res.Contact = &pb.Contact{Id: int32(e.Contact.ID), UserID: int32(e.Contact.UserID)}
+		} else {
+			res.Contact = toPBContact(&e.Contact, has[e.Contact.ID])
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseSyncToken(token string) (uint64, error) {
+	seq, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, status.Error(codes.InvalidArgument, errInvalidSyncToken.Error())
+	}
+	return seq, nil
+}
+
+func formatSyncToken(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+func syncError(err error) error {
+	if errors.Is(err, changelog.ErrResyncRequired) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package servers
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCSyncContacts(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+	kept := createContact(t, userID, "tolaabbey001@gmail.com")
+	removed := createContact(t, userID, "tolaabbey002@gmail.com")
+
+	full, err := contactgrpc.SyncContacts(ctx, &pb.SyncContactsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, full.Created, 2)
+	assert.Empty(t, full.DeletedIDs)
+
+	_, err = contactgrpc.UpdateContact(ctx, &pb.Contact{
+		Id:      int32(kept.ID),
+		Name:    kept.Fullname,
+		Email:   kept.Email,
+		Phone:   "08155040074",
+		Address: kept.Address,
+	})
+	require.NoError(t, err)
+	_, err = contactgrpc.DB.Delete(userID, removed.ID)
+	require.NoError(t, err)
+	added := createContact(t, userID, "tolaabbey003@gmail.com")
+
+	changes, err := contactgrpc.SyncContacts(ctx, &pb.SyncContactsRequest{SyncToken: full.SyncToken})
+	require.NoError(t, err)
+	require.Len(t, changes.Created, 1)
+	assert.Equal(t, int32(added.ID), changes.Created[0].Id)
+	require.Len(t, changes.Updated, 1)
+	assert.Equal(t, "08155040074", changes.Updated[0].Phone)
+	assert.Equal(t, []int32{int32(removed.ID)}, changes.DeletedIDs)
+
+	changes, err = contactgrpc.SyncContacts(ctx, &pb.SyncContactsRequest{SyncToken: changes.SyncToken})
+	require.NoError(t, err)
+	assert.Empty(t, changes.Created)
+	assert.Empty(t, changes.Updated)
+	assert.Empty(t, changes.DeletedIDs)
+
+	// a token that was never handed out can't be synced from
+	seq, err := strconv.ParseUint(changes.SyncToken, 10, 64)
+	require.NoError(t, err)
+	_, err = contactgrpc.SyncContacts(ctx, &pb.SyncContactsRequest{SyncToken: strconv.FormatUint(seq+1, 10)})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = contactgrpc.SyncContacts(ctx, &pb.SyncContactsRequest{SyncToken: "yesterday"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCWatchContacts(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token))
+	defer cancel()
+	c := pb.NewContactManagerClient(client)
+
+	kept, err := c.NewContact(ctx, &pb.Contact{Name: "Alugbin Abiodun", Email: "tolaabbey001@gmail.com", Phone: "+2347033304280", Address: "Ibadan"})
+	require.NoError(t, err)
+	synced, err := c.SyncContacts(ctx, &pb.SyncContactsRequest{})
+	require.NoError(t, err)
+
+	// watching from the sync token misses nothing made in between
+	stream, err := c.WatchContacts(ctx, &pb.WatchContactsRequest{SyncToken: synced.SyncToken})
+	require.NoError(t, err)
+	kept.Phone = "08155040074"
+	_, err = c.UpdateContact(ctx, kept)
+	require.NoError(t, err)
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "update", e.Action)
+	assert.Equal(t, "08155040074", e.Contact.Phone)
+
+	added, err := c.NewContact(ctx, &pb.Contact{Name: "Ada", Email: "ada@example.com", Phone: "1", Address: "Lagos"})
+	require.NoError(t, err)
+	e, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "create", e.Action)
+	assert.Equal(t, added.Id, e.Contact.Id)
+
+	_, err = c.BatchDeleteContacts(ctx, &pb.BatchDeleteContactsRequest{Ids: []int32{added.Id}})
+	require.NoError(t, err)
+	e, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "delete", e.Action)
+	assert.Equal(t, &pb.Contact{Id: added.Id, UserID: added.UserID}, e.Contact)
+
+	// resuming replays what changed since the token: the contact created and deleted since is left out
+	resumed, err := c.WatchContacts(ctx, &pb.WatchContactsRequest{SyncToken: synced.SyncToken})
+	require.NoError(t, err)
+	e, err = resumed.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "update", e.Action)
+	assert.Equal(t, kept.Id, e.Contact.Id)
+
+	// a token that was never handed out can't be resumed from
+	stale, err := c.WatchContacts(ctx, &pb.WatchContactsRequest{SyncToken: "1000000"})
+	require.NoError(t, err)
+	_, err = stale.Recv()
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
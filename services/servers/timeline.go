@@ -0,0 +1,152 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/timeline"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	interactionTypes = map[pb.InteractionType]string{
+		pb.InteractionType_INTERACTION_CALL:    timeline.Call,
+		pb.InteractionType_INTERACTION_MEETING: timeline.Meeting,
+		pb.InteractionType_INTERACTION_EMAIL:   timeline.Email,
+		pb.InteractionType_INTERACTION_MESSAGE: timeline.Message,
+	}
+)
+
+func (c *ContactManagerGrpc) AddNote(ctx context.Context, in *pb.Note) (*pb.Note, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	note, err := c.Timeline.AddNote(timeline.Note{
+		UserID:    found.UserID,
+		ContactID: found.ID,
+		Body:      in.Body,
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toPBNote(note), nil
+}
+
+func (c *ContactManagerGrpc) DeleteNote(ctx context.Context, in *pb.Note) (*pb.Note, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	note, err := c.Timeline.DeleteNote(userID, uint(in.Id))
+	if errors.Is(err, timeline.ErrNoteNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPBNote(note), nil
+}
+
+// LogInteraction records a call, meeting, email or message with the contact.
+func (c *ContactManagerGrpc) LogInteraction(ctx context.Context, in *pb.Interaction) (*pb.Interaction, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	i := timeline.Interaction{
+		UserID:    found.UserID,
+		ContactID: found.ID,
+		Type:      interactionTypes[in.Type],
+		Summary:   in.Summary,
+	}
+	if in.OccurredAt != 0 {
+		i.OccurredAt = time.Unix(in.OccurredAt, 0)
+	}
+	logged, err := c.Timeline.LogInteraction(i)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	c.DB.Publish(found.UserID)
+	return toPBInteraction(logged), nil
+}
+
+func (c *ContactManagerGrpc) DeleteInteraction(ctx context.Context, in *pb.Interaction) (*pb.Interaction, error) {
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i, err := c.Timeline.DeleteInteraction(userID, uint(in.Id))
+	if errors.Is(err, timeline.ErrInteractionNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.DB.Publish(userID)
+	return toPBInteraction(i), nil
+}
+
+// GetContactTimeline returns the notes, interactions and edits of a contact, newest first.
+func (c *ContactManagerGrpc) GetContactTimeline(ctx context.Context, in *pb.TimelineRequest) (*pb.Timeline, error) {
+	found, err := c.findContact(ctx, in.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	entries, next, err := c.Timeline.Timeline(found.UserID, found.ID, int(in.PageSize), in.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res := &pb.Timeline{NextPageToken: next}
+	for _, e := range entries {
+		entry := &pb.TimelineEntry{At: e.At.Unix()}
+		switch {
+		case e.Note != nil:
+			entry.Entry = &pb.TimelineEntry_Note{Note: toPBNote(e.Note)}
+		case e.Interaction != nil:
+			entry.Entry = &pb.TimelineEntry_Interaction{Interaction: toPBInteraction(e.Interaction)}
+		case e.Edit != nil:
+			entry.Entry = &pb.TimelineEntry_Edit{Edit: toPBEdit(e.Edit)}
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return res, nil
+}
+
+func toPBNote(n *timeline.Note) *pb.Note {
+	return &pb.Note{
+		Id:        int32(n.ID),
+		ContactID: int32(n.ContactID),
+		Body:      n.Body,
+		CreatedAt: n.CreatedAt.Unix(),
+	}
+}
+
+func toPBInteraction(i *timeline.Interaction) *pb.Interaction {
+	res := &pb.Interaction{
+		Id:         int32(i.ID),
+		ContactID:  int32(i.ContactID),
+		Summary:    i.Summary,
+		OccurredAt: i.OccurredAt.Unix(),
+	}
+	for t, name := range interactionTypes {
+		if name == i.Type {
+			res.Type = t
+		}
+	}
+	return res
+}
+
+func toPBEdit(r *contact.Revision) *pb.ContactEdit {
+	return &pb.ContactEdit{
+		Id:        int32(r.ID),
+		Action:    r.Action,
+		Fields:    r.FieldList(),
+		CreatedAt: r.CreatedAt.Unix(),
+	}
+}
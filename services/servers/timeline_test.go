@@ -0,0 +1,57 @@
+package servers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCContactTimeline(t *testing.T) {
+	userID, _ := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := middlewares.ContextWithUserID(context.Background(), userID)
+	c := createContact(t, userID, "tolaabbey001@gmail.com")
+	contactID := int32(c.ID)
+
+	_, err := contactgrpc.AddNote(ctx, &pb.Note{ContactID: contactID, Body: "Met at the conference"})
+	require.NoError(t, err)
+	called := time.Now().Add(time.Hour).Unix()
+	i, err := contactgrpc.LogInteraction(ctx, &pb.Interaction{
+		ContactID:  contactID,
+		Type:       pb.InteractionType_INTERACTION_CALL,
+		Summary:    "Discussed the renewal",
+		OccurredAt: called,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.InteractionType_INTERACTION_CALL, i.Type)
+
+	_, err = contactgrpc.LogInteraction(ctx, &pb.Interaction{ContactID: contactID, Summary: "No type"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	found, err := contactgrpc.GetContactByID(ctx, &pb.FindContactRequest{Id: contactID})
+	require.NoError(t, err)
+	assert.Equal(t, called, found.LastContactedAt)
+
+	tl, err := contactgrpc.GetContactTimeline(ctx, &pb.TimelineRequest{ContactID: contactID, PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, tl.Entries, 2)
+	assert.Equal(t, "Discussed the renewal", tl.Entries[0].GetInteraction().GetSummary())
+	assert.Equal(t, "Met at the conference", tl.Entries[1].GetNote().GetBody())
+
+	tl, err = contactgrpc.GetContactTimeline(ctx, &pb.TimelineRequest{ContactID: contactID, PageSize: 2, PageToken: tl.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, tl.Entries, 1)
+	assert.Equal(t, "create", tl.Entries[0].GetEdit().GetAction())
+	assert.Empty(t, tl.NextPageToken)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,238 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/organization"
+	"grpc-contact-manager/services/photo"
+	"grpc-contact-manager/services/sheet"
+	"grpc-contact-manager/services/vcard"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+// fileChunkSize is the size of the chunks file exports are streamed in.
+const fileChunkSize = 32 << 10
+
+var (
+	errInvalidFile          = errors.New("invalid file")
+	errUnsupportedMediaType = errors.New("body must be a vcard, csv or xlsx file")
+)
+
+// transfer imports and exports the contacts of a user as files.
+type transfer struct {
+	contacts *contact.DB
+	photos   *photo.DB
+	orgs     *organization.DB
+}
+
+// restTransfer builds the transfer from the repositories of the REST server.
+func (s *Server) restTransfer() *transfer {
+	return &transfer{contacts: contactDB, photos: photoDB, orgs: &organization.DB{Conn: s.Conn}}
+}
+
+func (c *ContactManagerGrpc) transfer() *transfer {
+	return &transfer{contacts: c.DB, photos: c.Photos, orgs: c.Orgs}
+}
+
+func (t *transfer) readPhoto(userID, contactID uint) ([]byte, string, error) {
+	p, err := t.photos.Find(userID, contactID)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := t.photos.Open(p, false)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	return data, p.ContentType, err
+}
+
+// organizationNames maps the ids of the user's organizations to their names.
+func (t *transfer) organizationNames(userID uint) (map[uint]string, error) {
+	orgs, err := t.orgs.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(orgs))
+	for _, o := range orgs {
+		names[o.ID] = o.Name
+	}
+	return names, nil
+}
+
+// fileTypes gives the media types of uploaded files by extension.
+var fileTypes = map[string]string{
+	".vcf":  vcard.ContentType,
+	".csv":  sheet.CSVContentType,
+	".xlsx": sheet.XLSXContentType,
+}
+
+// importFile creates contacts from the vCard, CSV or XLSX file in the body, or uploaded as the
+// file field of a form. Duplicates are skipped, or updated when the duplicates query parameter
+// is "update". Spreadsheet columns are mapped with the preset and mapping query parameters.
+func (s *Server) importFile(c *gin.Context) {
+	mediaType, params, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	var body io.Reader = c.Request.Body
+	if mediaType == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+		body = file
+		mediaType, params = fileTypes[strings.ToLower(filepath.Ext(header.Filename))], nil
+	}
+
+	t := s.restTransfer()
+	userID := middlewares.UserID(c)
+	var read func(im *contact.Importer) (*contact.ImportSummary, error)
+	switch mediaType {
+	case vcard.ContentType, "text/x-vcard":
+		read = func(im *contact.Importer) (*contact.ImportSummary, error) {
+			return t.importCards(im, userID, body)
+		}
+	case sheet.CSVContentType, "application/csv", sheet.XLSXContentType:
+		m, err := columnMapping(c.Query("preset"), c.QueryMap("mapping"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		var r rowReader
+		if mediaType == sheet.XLSXContentType {
+			var xr *sheet.XLSXReader
+			if xr, err = openWorkbook(body, m, c.Query("sheet")); err == nil {
+				defer xr.Close()
+				r = xr
+			}
+		} else {
+			r, err = sheet.NewCSVReader(body, m, params["charset"])
+		}
+		if err != nil {
+			code := http.StatusBadRequest
+			if errors.Is(err, errWorkbookTooLarge) {
+				code = http.StatusRequestEntityTooLarge
+			}
+			c.JSON(code, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		read = func(im *contact.Importer) (*contact.ImportSummary, error) {
+			return importRows(im, r)
+		}
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"success": false,
+			"error":   errUnsupportedMediaType.Error(),
+		})
+		return
+	}
+
+	im, err := contactDB.NewImporter(userID, c.Query("duplicates"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	summary, err := read(im)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, errInvalidFile) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	rowErrors := make([]gin.H, len(summary.Errors))
+	for i, e := range summary.Errors {
+		rowErrors[i] = gin.H{"row": e.Row, "error": e.Err.Error()}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"created": summary.Created,
+			"updated": summary.Updated,
+			"skipped": summary.Skipped,
+			"failed":  summary.Failed,
+			"errors":  rowErrors,
+		},
+	})
+}
+
+// startDownload sets the headers of a file download. Errors after it only cut the download short.
+func startDownload(c *gin.Context, contentType, filename string) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+}
+
+// duplicatePolicy returns the duplicate policy of an import from the request metadata.
+func duplicatePolicy(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(duplicatePolicyKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// chunkReader reads the data of the chunks of a client stream.
+type chunkReader struct {
+	recv func() ([]byte, error)
+	buf  []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		data, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// chunkWriter sends every write as a chunk of a server stream.
+type chunkWriter func(p []byte) error
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	if err := w(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,163 @@
+package servers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/vcard"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportVCards writes the contacts of the user as cards, reading them in batches.
+func (t *transfer) exportVCards(ctx context.Context, userID uint, enc *vcard.Encoder, withPhotos bool) error {
+	names, err := t.organizationNames(userID)
+	if err != nil {
+		return err
+	}
+	return t.contacts.Export(ctx, userID, 0, 0, func(batch []contact.Contact) error {
+		has := map[uint]bool{}
+		if withPhotos {
+			ids := make([]uint, len(batch))
+			for i := range batch {
+				ids[i] = batch[i].ID
+			}
+			var err error
+			if has, err = t.photos.HasPhoto(ids...); err != nil {
+				return err
+			}
+		}
+		for i := range batch {
+			e := &vcard.Entry{Contact: batch[i]}
+			if id := batch[i].OrganizationID; id != nil {
+				e.Organization = names[*id]
+			}
+			if has[batch[i].ID] {
+				if e.Photo, e.PhotoContentType, err = t.readPhoto(userID, batch[i].ID); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// importCards adds the cards read from r to the import. Organizations are resolved by name
+// as the cards are written, and photos are saved once their contact is written.
+// A malformed card stops the import with errInvalidFile.
+func (t *transfer) importCards(im *contact.Importer, userID uint, r io.Reader) (*contact.ImportSummary, error) {
+	photos := map[int][]byte{}
+	im.OnWrite = func(row int, c *contact.Contact) error {
+		data, ok := photos[row]
+		if !ok {
+			return nil
+		}
+		if _, err := t.photos.Save(userID, c.ID, data); err != nil {
+			return err
+		}
+		t.contacts.Publish(userID)
+		return nil
+	}
+	dec := vcard.NewDecoder(r)
+	for row := 1; ; row++ {
+		e, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: card %d: %v", errInvalidFile, row, err)
+		}
+		if len(e.Photo) > 0 {
+			photos[row] = e.Photo
+		}
+		if err := im.AddInOrganization(row, e.Contact, e.Organization); err != nil {
+			return nil, err
+		}
+		if im.Pending() == 0 {
+			// the chunk was written, so its photos were saved
+			photos = map[int][]byte{}
+		}
+	}
+	return im.Close()
+}
+
+// exportVCards downloads all the contacts of the user as a vCard file.
+func (s *Server) exportVCards(c *gin.Context) {
+	enc, err := vcard.NewEncoder(c.Writer, c.Query("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	photos, _ := strconv.ParseBool(c.Query("photos"))
+	startDownload(c, vcard.ContentType+"; charset=utf-8", "contacts.vcf")
+	if err := s.restTransfer().exportVCards(c.Request.Context(), middlewares.UserID(c), enc, photos); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// ImportVCards imports the vCard file streamed in chunks and reports how every card went.
+func (c *ContactManagerGrpc) ImportVCards(stream pb.ContactManager_ImportVCardsServer) error {
+	userID, err := authUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+	im, err := c.DB.NewImporter(userID, duplicatePolicy(stream.Context()))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	summary, err := c.transfer().importCards(im, userID, &chunkReader{recv: func() ([]byte, error) {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return chunk.Data, nil
+	}})
+	if errors.Is(err, errInvalidFile) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(toPBImportSummary(summary))
+}
+
+// ExportVCards streams the contacts of the user as a vCard file, in chunks of up to 32 KiB.
+func (c *ContactManagerGrpc) ExportVCards(in *pb.VCardExportRequest, stream pb.ContactManager_ExportVCardsServer) error {
+	ctx := stream.Context()
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(chunkWriter(func(p []byte) error {
+		return stream.Send(&pb.VCardChunk{Data: p})
+	}), fileChunkSize)
+	enc, err := vcard.NewEncoder(w, in.Version)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = c.transfer().exportVCards(ctx, userID, enc, in.IncludePhotos)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,170 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/organization"
+	"grpc-contact-manager/services/photo"
+	"grpc-contact-manager/services/vcard"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// sampleCards reads the sample cards of the vcard package.
+func sampleCards(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	for _, name := range names {
+		data, err := os.ReadFile("../vcard/testdata/" + name)
+		require.NoError(t, err)
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
+
+func decodeCards(t *testing.T, r io.Reader) map[string]*vcard.Entry {
+	entries := map[string]*vcard.Entry{}
+	dec := vcard.NewDecoder(r)
+	for {
+		e, err := dec.Decode()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		entries[e.Contact.Email] = e
+	}
+}
+
+func TestVCardImportExport(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	cards := sampleCards(t, "apple.vcf", "google.vcf", "outlook.vcf", "thunderbird.vcf")
+
+	w := doRequest(t, s.Handler, "POST", "/contacts/import", token, bytes.NewReader(cards))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	w = doRequest(t, s.Handler, "POST", "/contacts/import?duplicates=replace", token, bytes.NewReader(cards), "Content-Type", "text/vcard")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doRequest(t, s.Handler, "POST", "/contacts/import", token, strings.NewReader("BEGIN:VCARD\r\nFN:Jane\r\n"), "Content-Type", "text/vcard")
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = doRequest(t, s.Handler, "POST", "/contacts/import", token, bytes.NewReader(cards), "Content-Type", "text/x-vcard; charset=utf-8")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp := struct {
+		Data struct {
+			Created int `json:"created"`
+			Skipped int `json:"skipped"`
+			Failed  int `json:"failed"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 5, resp.Data.Created)
+
+	orgs, err := (&organization.DB{Conn: server.Conn}).FindByUserID(userID)
+	require.NoError(t, err)
+	assert.Len(t, orgs, 3)
+
+	// importing again skips every card
+	w = doRequest(t, s.Handler, "POST", "/contacts/import", token, bytes.NewReader(cards), "Content-Type", "text/vcard")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 5, resp.Data.Skipped)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export.vcf?version=2.1", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export.vcf?version=3.0&photos=true", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/vcard; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "contacts.vcf")
+	assert.Contains(t, w.Body.String(), "VERSION:3.0\r\n")
+	entries := decodeCards(t, w.Body)
+	require.Len(t, entries, 5)
+	apple := entries["j.appleseed@apple.com"]
+	assert.Equal(t, "Apple Inc.", apple.Organization)
+	assert.Equal(t, "Engineering", apple.Contact.Department)
+	assert.Equal(t, "image/png", apple.PhotoContentType)
+	assert.Empty(t, entries["ola@example.com"].Photo)
+
+	// contact ids are still served next to the file routes
+	w = doRequest(t, s.Handler, "GET", "/contacts/abc", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doRequest(t, s.Handler, "POST", "/contacts/export", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCVCards(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	userID, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+
+	stream, err := cm.ImportVCards(ctx)
+	require.NoError(t, err)
+	cards := sampleCards(t, "thunderbird.vcf", "google.vcf")
+	// chunks split cards and lines anywhere
+	for len(cards) > 0 {
+		n := 7
+		if n > len(cards) {
+			n = len(cards)
+		}
+		require.NoError(t, stream.Send(&pb.VCardChunk{Data: cards[:n]}))
+		cards = cards[n:]
+	}
+	summary, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), summary.Created)
+
+	found, err := server.Conn.Raw("SELECT id FROM contacts WHERE email = ?", "juergen@example.de").Rows()
+	require.NoError(t, err)
+	var contactID uint
+	require.True(t, found.Next())
+	require.NoError(t, found.Scan(&contactID))
+	found.Close()
+	p, err := (&photo.DB{Conn: server.Conn}).Find(userID, contactID)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", p.ContentType)
+
+	export, err := cm.ExportVCards(ctx, &pb.VCardExportRequest{})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	for {
+		chunk, err := export.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(chunk.Data), fileChunkSize)
+		buf.Write(chunk.Data)
+	}
+	assert.Contains(t, buf.String(), "VERSION:4.0\r\n")
+	entries := decodeCards(t, &buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "Dr. Jürgen Müller", entries["juergen@example.de"].Contact.Fullname)
+	assert.Empty(t, entries["juergen@example.de"].Photo)
+
+	export, err = cm.ExportVCards(ctx, &pb.VCardExportRequest{Version: "2.1"})
+	require.NoError(t, err)
+	_, err = export.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
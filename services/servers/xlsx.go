@@ -0,0 +1,175 @@
+package servers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/middlewares"
+	"grpc-contact-manager/services/sheet"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxWorkbookSize caps imported workbooks, which are read into memory.
+const maxWorkbookSize = 20 << 20
+
+var errWorkbookTooLarge = fmt.Errorf("workbook must not be larger than %d bytes", maxWorkbookSize)
+
+// openWorkbook reads the workbook from r and maps the columns of the named sheet.
+func openWorkbook(r io.Reader, m sheet.Mapping, name string) (*sheet.XLSXReader, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxWorkbookSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxWorkbookSize {
+		return nil, errWorkbookTooLarge
+	}
+	return sheet.NewXLSXReader(bytes.NewReader(data), m, name)
+}
+
+// exportXLSX writes the contacts of the user as a workbook, with a sheet of their organizations
+// when withOrgs is set. The workbook is only written to out once all the rows were read.
+func (t *transfer) exportXLSX(ctx context.Context, userID uint, w *sheet.XLSXWriter, withOrgs bool, out io.Writer) error {
+	defer w.Close()
+	if err := t.exportRows(ctx, userID, w); err != nil {
+		return err
+	}
+	if withOrgs {
+		orgs, err := t.orgs.FindByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if err := w.WriteOrganizations(orgs); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteTo(out)
+	return err
+}
+
+// exportXLSX downloads the contacts of the user as an Excel workbook. The columns query
+// parameter picks the fields, separated by commas, and organizations adds a sheet of them.
+func (s *Server) exportXLSX(c *gin.Context) {
+	var columns []string
+	if v := c.Query("columns"); v != "" {
+		columns = strings.Split(v, ",")
+	}
+	withOrgs, _ := strconv.ParseBool(c.Query("organizations"))
+	w, err := sheet.NewXLSXWriter(columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	// the headers are only sent with the workbook, so failures before it still get an error response
+	out := &lazyDownload{c: c, contentType: sheet.XLSXContentType, filename: "contacts.xlsx"}
+	if err := s.restTransfer().exportXLSX(c.Request.Context(), middlewares.UserID(c), w, withOrgs, out); err != nil {
+		if out.started {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// lazyDownload starts the download on the first write.
+type lazyDownload struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (d *lazyDownload) Write(p []byte) (int, error) {
+	if !d.started {
+		startDownload(d.c, d.contentType, d.filename)
+		d.started = true
+	}
+	return d.c.Writer.Write(p)
+}
+
+// ImportXLSX imports the workbook streamed in chunks and reports how every row went, numbering
+// rows as in the sheet.
+func (c *ContactManagerGrpc) ImportXLSX(stream pb.ContactManager_ImportXLSXServer) error {
+	userID, err := authUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+	im, err := c.DB.NewImporter(userID, duplicatePolicy(stream.Context()))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "no workbook sent")
+	}
+	if err != nil {
+		return err
+	}
+	m, err := columnMapping(first.Preset, first.Mapping)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	r, err := openWorkbook(&chunkReader{buf: first.Data, recv: func() ([]byte, error) {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return chunk.Data, nil
+	}}, m, first.Sheet)
+	if errors.Is(err, errWorkbookTooLarge) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer r.Close()
+	summary, err := importRows(im, r)
+	if errors.Is(err, errInvalidFile) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(toPBImportSummary(summary))
+}
+
+// ExportXLSX streams the contacts of the user as an Excel workbook, in chunks of up to 32 KiB.
+func (c *ContactManagerGrpc) ExportXLSX(in *pb.XLSXExportRequest, stream pb.ContactManager_ExportXLSXServer) error {
+	ctx := stream.Context()
+	userID, err := authUserID(ctx)
+	if err != nil {
+		return err
+	}
+	w, err := sheet.NewXLSXWriter(in.Columns)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	buf := bufio.NewWriterSize(chunkWriter(func(p []byte) error {
+		return stream.Send(&pb.XLSXChunk{Data: p})
+	}), fileChunkSize)
+	err = c.transfer().exportXLSX(ctx, userID, w, in.IncludeOrganizations, buf)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return err
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,151 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	pb "grpc-contact-manager/contact"
+	"grpc-contact-manager/services/sheet"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// workbook builds a workbook whose first sheet holds the rows.
+func workbook(t *testing.T, rows ...[]interface{}) []byte {
+	f := excelize.NewFile()
+	for i, row := range rows {
+		axis, err := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow("Sheet1", axis, &row))
+	}
+	var buf bytes.Buffer
+	_, err := f.WriteTo(&buf)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestXLSXImportExport(t *testing.T) {
+	s, err := server.StartHttp(context.Background(), ":2500")
+	require.NoError(t, err)
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	data := workbook(t,
+		[]interface{}{"First Name", "Last Name", "E-mail Address", "Mobile Phone", "Business City", "Company"},
+		[]interface{}{"Jane", "Doe", "jane.doe@acme.com", "+234 703 330 4280", "Ibadan", "Acme"},
+		[]interface{}{"Ola", "Bello", "", "08155040074", "Lagos"},
+	)
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, err := mw.CreateFormFile("file", "contacts.xlsx")
+	require.NoError(t, err)
+	_, err = fw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	w := doRequest(t, s.Handler, "POST", "/contacts/import?preset=outlook", token, &form, "Content-Type", mw.FormDataContentType())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp := struct {
+		Data struct {
+			Created int `json:"created"`
+			Errors  []struct {
+				Row int `json:"row"`
+			} `json:"errors"`
+		} `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.Created)
+	require.Len(t, resp.Data.Errors, 1)
+	assert.Equal(t, 3, resp.Data.Errors[0].Row)
+
+	w = doRequest(t, s.Handler, "POST", "/contacts/import?preset=outlook&sheet=People", token, bytes.NewReader(data), "Content-Type", sheet.XLSXContentType)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doRequest(t, s.Handler, "POST", "/contacts/import", token, bytes.NewReader([]byte("not a workbook")), "Content-Type", sheet.XLSXContentType)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export.xlsx?columns=name,notes", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(t, s.Handler, "GET", "/contacts/export.xlsx?organizations=true", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, sheet.XLSXContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "contacts.xlsx")
+	f, err := excelize.OpenReader(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, []string{sheet.ContactsSheet, sheet.OrganizationsSheet}, f.GetSheetList())
+	rows, err := f.GetRows(sheet.ContactsSheet)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, sheet.ExportFields, rows[0])
+	assert.Equal(t, []string{"Jane Doe", "jane.doe@acme.com", "+234 703 330 4280", "Ibadan", "Acme"}, rows[1][:5])
+	orgs, err := f.GetRows(sheet.OrganizationsSheet)
+	require.NoError(t, err)
+	require.Len(t, orgs, 2)
+	assert.Equal(t, "Acme", orgs[1][0])
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
+
+func TestGRPCXLSX(t *testing.T) {
+	client, closeFn := startGRPC(t)
+	defer closeFn()
+	_, token := authenticatedUser(t, "tolaabbey009@gmail.com")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	cm := pb.NewContactManagerClient(client)
+	data := workbook(t,
+		[]interface{}{"name", "email", "phone", "address"},
+		[]interface{}{"Ada Obi", "ada@example.com", "0803", "Enugu"},
+	)
+
+	stream, err := cm.ImportXLSX(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.XLSXChunk{Data: data[:100], Sheet: "Sheet1"}))
+	require.NoError(t, stream.Send(&pb.XLSXChunk{Data: data[100:]}))
+	summary, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), summary.Created)
+
+	stream, err = cm.ImportXLSX(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.XLSXChunk{Data: data[:100]}))
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	export, err := cm.ExportXLSX(ctx, &pb.XLSXExportRequest{Columns: []string{"email", "created_at"}})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	for {
+		chunk, err := export.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		buf.Write(chunk.Data)
+	}
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{sheet.ContactsSheet}, f.GetSheetList())
+	email, err := f.GetCellValue(sheet.ContactsSheet, "A2")
+	require.NoError(t, err)
+	assert.Equal(t, "ada@example.com", email)
+	cellType, err := f.GetCellType(sheet.ContactsSheet, "B2")
+	require.NoError(t, err)
+	assert.Equal(t, excelize.CellTypeUnset, cellType)
+	created, err := f.GetCellValue(sheet.ContactsSheet, "B2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Regexp(t, `^\d+\.\d+$`, created)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanupContacts(server.Conn))
+	})
+}
@@ -0,0 +1,162 @@
+package sheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// CSVContentType is the media type of CSV files.
+const CSVContentType = "text/csv"
+
+// utf8BOM lets spreadsheet applications such as Excel recognize UTF-8 files.
+const utf8BOM = "\ufeff"
+
+// formulaPrefixes start the cells spreadsheet applications read as formulas.
+const formulaPrefixes = "=+-@\t\r"
+
+// CSVReader reads contacts from a CSV file with a header line.
+type CSVReader struct {
+	r      *csv.Reader
+	header header
+	// unescape is set for the files written by CSVWriter, whose formulas were escaped.
+	unescape bool
+}
+
+// NewCSVReader reads the header of the file and maps its columns. A byte order mark selects
+// UTF-8 or UTF-16, otherwise the text is decoded from charset, such as windows-1252, which
+// defaults to UTF-8.
+func NewCSVReader(r io.Reader, m Mapping, charset string) (*CSVReader, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	enc := encoding.Encoding(unicode.UTF8)
+	if charset != "" {
+		var err error
+		if enc, err = htmlindex.Get(charset); err != nil {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+	}
+	cr := csv.NewReader(transform.NewReader(r, unicode.BOMOverride(enc.NewDecoder())))
+	// rows may leave out trailing empty cells
+	cr.FieldsPerRecord = -1
+	names, err := cr.Read()
+	if err == io.EOF {
+		return nil, errNoColumns
+	}
+	if err != nil {
+		return nil, err
+	}
+	h, err := newHeader(names, m)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVReader{r: cr, header: h, unescape: written(names)}, nil
+}
+
+// written tells if the header is one CSVWriter writes, only made of export columns. Other
+// files are read as they are, as a leading quote is part of their cells.
+func written(names []string) bool {
+	for _, name := range names {
+		if !contains(ExportFields, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Read returns the next row, or io.EOF after the last one. Malformed rows are returned as a
+// *RowError and reading can go on.
+func (r *CSVReader) Read() (*Row, error) {
+	for {
+		cells, err := r.r.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blank(cells) {
+			continue
+		}
+		if r.unescape {
+			for i, c := range cells {
+				cells[i] = unescapeFormula(c)
+			}
+		}
+		line, _ := r.r.FieldPos(0)
+		return r.header.row(line, cells), nil
+	}
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if c != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// CSVWriter writes contacts as a CSV file with the given columns.
+type CSVWriter struct {
+	w      *csv.Writer
+	fields []string
+}
+
+// NewCSVWriter writes the header of the columns, preceded by a byte order mark when bom is set.
+// Whether or not the file is meant for spreadsheet applications, the cells they would read as
+// formulas are escaped with a leading quote. CSVReader removes it again.
+func NewCSVWriter(w io.Writer, fields []string, bom bool) (*CSVWriter, error) {
+	fields, err := Columns(fields)
+	if err != nil {
+		return nil, err
+	}
+	if bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+	cw := &CSVWriter{w: csv.NewWriter(w), fields: fields}
+	return cw, cw.w.Write(fields)
+}
+
+// Write writes the row. Rows are buffered until Flush.
+func (w *CSVWriter) Write(r *Row) error {
+	cells := cells(r, w.fields)
+	for i, c := range cells {
+		cells[i] = escapeFormula(c)
+	}
+	return w.w.Write(cells)
+}
+
+// Flush writes the buffered rows.
+func (w *CSVWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// escapeFormula quotes the cell so spreadsheet applications show it as text rather than
+// running it as a formula.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unescapeFormula undoes escapeFormula, so exports read back as they were.
+func unescapeFormula(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
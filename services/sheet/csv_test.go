@@ -0,0 +1,182 @@
+package sheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func readAll(t *testing.T, r *CSVReader) ([]*Row, []*RowError) {
+	var (
+		rows   []*Row
+		errors []*RowError
+	)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, errors
+		}
+		if rowErr, ok := err.(*RowError); ok {
+			errors = append(errors, rowErr)
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReaderGoogle(t *testing.T) {
+	f, err := os.Open("testdata/google.csv")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := NewCSVReader(f, Google, "")
+	require.NoError(t, err)
+	rows, rowErrors := readAll(t, r)
+
+	require.Len(t, rows, 3)
+	assert.Equal(t, &Row{
+		Line: 2,
+		Contact: contact.Contact{
+			Fullname:   "Jane Doe",
+			Email:      "jane.doe@acme.com",
+			Phone:      "+234 703 330 4280",
+			Address:    "33, Tioya Street\nIbadan",
+			JobTitle:   "Engineering Manager",
+			Department: "Platform",
+		},
+		Organization: "Acme",
+	}, rows[0])
+	// the multi-line address of the first row moves the next row down
+	assert.Equal(t, 4, rows[1].Line)
+	assert.Equal(t, "Ola Bello", rows[1].Contact.Fullname)
+	assert.Equal(t, 7, rows[2].Line)
+	assert.Empty(t, rows[2].Contact.Email)
+
+	require.Len(t, rowErrors, 1)
+	assert.Equal(t, 6, rowErrors[0].Line)
+	assert.True(t, errors.Is(rowErrors[0], csv.ErrQuote))
+}
+
+func TestCSVReaderEncodings(t *testing.T) {
+	outlook, err := os.ReadFile("testdata/outlook.csv")
+	require.NoError(t, err)
+
+	// Outlook writes windows-1252 without a byte order mark
+	r, err := NewCSVReader(bytes.NewReader(outlook), Outlook, "windows-1252")
+	require.NoError(t, err)
+	rows, _ := readAll(t, r)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Renée François", rows[0].Contact.Fullname)
+	assert.Equal(t, "+33 1 42 14 20 00", rows[0].Contact.Phone)
+	assert.Equal(t, "29 Boulevard Haussmann, Paris, 75009, France", rows[0].Contact.Address)
+	assert.Equal(t, "Société Générale", rows[0].Organization)
+	assert.Equal(t, "Mr. John Smith Jr.", rows[1].Contact.Fullname)
+	assert.Equal(t, "(425) 555-0100", rows[1].Contact.Phone)
+
+	// a byte order mark wins over the given charset
+	text := "name,email\r\nJürgen Müller,juergen@example.de\r\n"
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(text)
+	require.NoError(t, err)
+	for _, data := range []string{utf16, utf8BOM + text} {
+		r, err := NewCSVReader(strings.NewReader(data), defaultMapping(), "windows-1252")
+		require.NoError(t, err)
+		rows, _ := readAll(t, r)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "Jürgen Müller", rows[0].Contact.Fullname)
+	}
+
+	_, err = NewCSVReader(strings.NewReader(text), defaultMapping(), "klingon")
+	assert.Error(t, err)
+}
+
+func TestPreset(t *testing.T) {
+	m, err := Preset("")
+	require.NoError(t, err)
+	assert.Equal(t, FieldJobTitle, m["job_title"])
+	m, err = Preset("Outlook")
+	require.NoError(t, err)
+	assert.Equal(t, Outlook, m)
+	_, err = Preset("yahoo")
+	assert.Equal(t, ErrUnknownPreset, err)
+}
+
+func TestCSVReaderMapping(t *testing.T) {
+	text := "Full Name , Mail,Notes\nJane Doe,jane@example.com,hi\n"
+	r, err := NewCSVReader(strings.NewReader(text), Mapping{"full name": FieldName, "MAIL": FieldEmail}, "")
+	require.NoError(t, err)
+	rows, _ := readAll(t, r)
+	require.Len(t, rows, 1)
+	assert.Equal(t, contact.Contact{Fullname: "Jane Doe", Email: "jane@example.com"}, rows[0].Contact)
+
+	_, err = NewCSVReader(strings.NewReader(text), Mapping{"Notes": "notes"}, "")
+	assert.Error(t, err)
+	_, err = NewCSVReader(strings.NewReader(text), Google, "")
+	assert.Equal(t, errNoColumns, err)
+	_, err = NewCSVReader(strings.NewReader(""), Google, "")
+	assert.Equal(t, errNoColumns, err)
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewCSVWriter(&buf, []string{"name", "notes"}, false)
+	assert.Error(t, err)
+
+	w, err := NewCSVWriter(&buf, []string{FieldEmail, FieldName, FieldOrganization}, true)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(&Row{
+		Contact:      contact.Contact{Fullname: `Jane "JD" Doe`, Email: "jane@example.com"},
+		Organization: "Acme, Inc.",
+	}))
+	require.NoError(t, w.Flush())
+	assert.Equal(t, utf8BOM+"email,name,organization\njane@example.com,\"Jane \"\"JD\"\" Doe\",\"Acme, Inc.\"\n", buf.String())
+
+	// the export reads back with the default preset
+	r, err := NewCSVReader(&buf, Presets["default"], "")
+	require.NoError(t, err)
+	rows, _ := readAll(t, r)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Acme, Inc.", rows[0].Organization)
+	assert.Equal(t, `Jane "JD" Doe`, rows[0].Contact.Fullname)
+}
+
+func TestCSVWriterFormulas(t *testing.T) {
+	row := &Row{
+		Contact:      contact.Contact{Fullname: "=HYPERLINK(\"http://evil.example\")", Phone: "+2347033304280", Email: "@jane"},
+		Organization: "-1+1",
+	}
+	fields := []string{FieldName, FieldPhone, FieldEmail, FieldOrganization}
+	for _, bom := range []bool{false, true} {
+		var buf bytes.Buffer
+		w, err := NewCSVWriter(&buf, fields, bom)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(row))
+		require.NoError(t, w.Flush())
+		assert.True(t, strings.HasSuffix(buf.String(), "name,phone,email,organization\n\"'=HYPERLINK(\"\"http://evil.example\"\")\",'+2347033304280,'@jane,'-1+1\n"), buf.String())
+
+		r, err := NewCSVReader(&buf, Presets["default"], "")
+		require.NoError(t, err)
+		rows, _ := readAll(t, r)
+		require.Len(t, rows, 1)
+		assert.Equal(t, row.Contact.Fullname, rows[0].Contact.Fullname)
+		assert.Equal(t, row.Contact.Phone, rows[0].Contact.Phone)
+		assert.Equal(t, row.Contact.Email, rows[0].Contact.Email)
+		assert.Equal(t, row.Organization, rows[0].Organization)
+	}
+
+	// other files keep their leading quotes
+	r, err := NewCSVReader(strings.NewReader("Name,E-mail\n'=1+1,jane@example.com\n"), Mapping{"Name": FieldName, "E-mail": FieldEmail}, "")
+	require.NoError(t, err)
+	rows, _ := readAll(t, r)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "'=1+1", rows[0].Contact.Fullname)
+}
@@ -0,0 +1,288 @@
+// Package sheet maps the rows of spreadsheets, such as CSV files and Excel workbooks, to contacts.
+package sheet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+)
+
+// Contact fields a column can map to.
+const (
+	FieldName         = "name"
+	FieldEmail        = "email"
+	FieldPhone        = "phone"
+	FieldAddress      = "address"
+	FieldOrganization = "organization"
+	FieldJobTitle     = "job_title"
+	FieldDepartment   = "department"
+)
+
+// Date fields, which are exported but never imported.
+const (
+	FieldCreatedAt       = "created_at"
+	FieldUpdatedAt       = "updated_at"
+	FieldLastContactedAt = "last_contacted_at"
+)
+
+var (
+	// Fields lists the contact fields in their default column order.
+	Fields = []string{FieldName, FieldEmail, FieldPhone, FieldAddress, FieldOrganization, FieldJobTitle, FieldDepartment}
+	// DateFields lists the date fields.
+	DateFields = []string{FieldCreatedAt, FieldUpdatedAt, FieldLastContactedAt}
+	// ExportFields lists the fields exported by default.
+	ExportFields = append(append([]string{}, Fields...), DateFields...)
+)
+
+var (
+	errNoColumns = errors.New("no column maps to a contact field")
+
+	// ErrUnknownPreset is returned for a preset missing from Presets.
+	ErrUnknownPreset = errors.New("unknown column preset")
+)
+
+// Mapping maps column headers to contact fields. Headers are matched ignoring case and
+// surrounding spaces. Several columns may map to one field: names are joined with spaces,
+// addresses with commas, and other fields take the first non-empty value.
+type Mapping map[string]string
+
+// Google maps the columns of the CSV files exported by Google Contacts, in both its current
+// and its former layout.
+var Google = Mapping{
+	"Name":                        FieldName,
+	"First Name":                  FieldName,
+	"Middle Name":                 FieldName,
+	"Last Name":                   FieldName,
+	"E-mail 1 - Value":            FieldEmail,
+	"E-mail 2 - Value":            FieldEmail,
+	"Phone 1 - Value":             FieldPhone,
+	"Phone 2 - Value":             FieldPhone,
+	"Address 1 - Formatted":       FieldAddress,
+	"Organization Name":           FieldOrganization,
+	"Organization 1 - Name":       FieldOrganization,
+	"Organization Title":          FieldJobTitle,
+	"Organization 1 - Title":      FieldJobTitle,
+	"Organization Department":     FieldDepartment,
+	"Organization 1 - Department": FieldDepartment,
+}
+
+// Outlook maps the columns of the CSV files exported by Outlook. Business details are
+// preferred over home ones.
+var Outlook = Mapping{
+	"Title":                   FieldName,
+	"First Name":              FieldName,
+	"Middle Name":             FieldName,
+	"Last Name":               FieldName,
+	"Suffix":                  FieldName,
+	"E-mail Address":          FieldEmail,
+	"E-mail 2 Address":        FieldEmail,
+	"Business Phone":          FieldPhone,
+	"Mobile Phone":            FieldPhone,
+	"Home Phone":              FieldPhone,
+	"Business Street":         FieldAddress,
+	"Business City":           FieldAddress,
+	"Business State":          FieldAddress,
+	"Business Postal Code":    FieldAddress,
+	"Business Country/Region": FieldAddress,
+	"Company":                 FieldOrganization,
+	"Job Title":               FieldJobTitle,
+	"Department":              FieldDepartment,
+}
+
+// Presets are the built-in mappings by name. The default preset expects the field names as headers.
+var Presets = map[string]Mapping{
+	"default": defaultMapping(),
+	"google":  Google,
+	"outlook": Outlook,
+}
+
+// Preset returns the mapping of a preset, the default one when the name is empty.
+func Preset(name string) (Mapping, error) {
+	if name == "" {
+		name = "default"
+	}
+	m, ok := Presets[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownPreset
+	}
+	return m, nil
+}
+
+func defaultMapping() Mapping {
+	m := Mapping{}
+	for _, f := range Fields {
+		m[f] = f
+	}
+	return m
+}
+
+// Validate checks that every header maps to a known field.
+func (m Mapping) Validate() error {
+	for header, field := range m {
+		if !knownField(field) {
+			return fmt.Errorf("column %q maps to unknown field %q", header, field)
+		}
+	}
+	return nil
+}
+
+func knownField(field string) bool {
+	return contains(Fields, field)
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Row is a contact read from a spreadsheet.
+type Row struct {
+	// Line is where the row starts in the file, counting the header as line 1.
+	Line    int
+	Contact contact.Contact
+	// Organization is the name of the contact's organization.
+	Organization string
+}
+
+// RowError is the error of a single row. Reading can go on with the next row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// header holds the field of every column, if any.
+type header []string
+
+func newHeader(names []string, m Mapping) (header, error) {
+	byName := make(map[string]string, len(m))
+	for name, field := range m {
+		byName[normalize(name)] = field
+	}
+	h := make(header, len(names))
+	mapped := false
+	for i, name := range names {
+		if field, ok := byName[normalize(name)]; ok {
+			h[i] = field
+			mapped = true
+		}
+	}
+	if !mapped {
+		return nil, errNoColumns
+	}
+	return h, nil
+}
+
+func normalize(header string) string {
+	return strings.ToLower(strings.TrimSpace(header))
+}
+
+// row builds the row from the cells of a record. Records may be shorter than the header.
+func (h header) row(line int, cells []string) *Row {
+	values := map[string][]string{}
+	for i, field := range h {
+		if field == "" || i >= len(cells) {
+			continue
+		}
+		if v := strings.TrimSpace(cells[i]); v != "" {
+			values[field] = append(values[field], v)
+		}
+	}
+	first := func(field string) string {
+		if v := values[field]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	return &Row{
+		Line: line,
+		Contact: contact.Contact{
+			Fullname:   strings.Join(values[FieldName], " "),
+			Email:      first(FieldEmail),
+			Phone:      first(FieldPhone),
+			Address:    strings.Join(values[FieldAddress], ", "),
+			JobTitle:   first(FieldJobTitle),
+			Department: first(FieldDepartment),
+		},
+		Organization: first(FieldOrganization),
+	}
+}
+
+// Columns checks the fields chosen for an export, ExportFields when none are given.
+func Columns(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return ExportFields, nil
+	}
+	for _, f := range fields {
+		if !contains(ExportFields, f) {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+	}
+	return fields, nil
+}
+
+// cells returns the values of the fields of the row as text. Dates are formatted as RFC 3339.
+func cells(r *Row, fields []string) []string {
+	text := make([]string, len(fields))
+	for i, v := range values(r, fields) {
+		switch v := v.(type) {
+		case string:
+			text[i] = v
+		case time.Time:
+			text[i] = v.UTC().Format(time.RFC3339)
+		}
+	}
+	return text
+}
+
+// values returns the values of the fields of the row. Dates are time.Time, or nil when unset.
+func values(r *Row, fields []string) []interface{} {
+	values := make([]interface{}, len(fields))
+	date := func(t time.Time) interface{} {
+		if t.IsZero() {
+			return nil
+		}
+		return t
+	}
+	for i, f := range fields {
+		switch f {
+		case FieldName:
+			values[i] = r.Contact.Fullname
+		case FieldEmail:
+			values[i] = r.Contact.Email
+		case FieldPhone:
+			values[i] = r.Contact.Phone
+		case FieldAddress:
+			values[i] = r.Contact.Address
+		case FieldOrganization:
+			values[i] = r.Organization
+		case FieldJobTitle:
+			values[i] = r.Contact.JobTitle
+		case FieldDepartment:
+			values[i] = r.Contact.Department
+		case FieldCreatedAt:
+			values[i] = date(r.Contact.CreatedAt)
+		case FieldUpdatedAt:
+			values[i] = date(r.Contact.UpdatedAt)
+		case FieldLastContactedAt:
+			if r.Contact.LastContactedAt != nil {
+				values[i] = date(*r.Contact.LastContactedAt)
+			}
+		}
+	}
+	return values
+}
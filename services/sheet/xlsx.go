@@ -0,0 +1,218 @@
+package sheet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"grpc-contact-manager/services/organization"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXContentType is the media type of Excel workbooks.
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Sheets of an exported workbook.
+const (
+	ContactsSheet      = "Contacts"
+	OrganizationsSheet = "Organizations"
+)
+
+// dateFormat is the built-in number format of Excel for dates with times.
+const dateFormat = 22
+
+// maxUnzippedSize caps the uncompressed parts of a read workbook, about ten times the largest
+// workbook the servers accept, so a small upload can't unzip into gigabytes. The parts are kept
+// in memory up to it instead of spilling to temporary files.
+var maxUnzippedSize int64 = 200 << 20
+
+var (
+	errNoSheet = errors.New("workbook has no such sheet")
+
+	organizationColumns = []string{"name", "domain", "phone", "address", "notes"}
+)
+
+// XLSXReader reads contacts from a sheet of a workbook whose first row is the header.
+type XLSXReader struct {
+	f      *excelize.File
+	rows   *excelize.Rows
+	header header
+	line   int
+}
+
+// NewXLSXReader opens the workbook, which is read into memory, and maps the columns of the
+// named sheet, the first one when the name is empty.
+func NewXLSXReader(r io.Reader, m Mapping, sheet string) (*XLSXReader, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	f, err := excelize.OpenReader(r, excelize.Options{
+		UnzipSizeLimit:    maxUnzippedSize,
+		UnzipXMLSizeLimit: maxUnzippedSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid workbook: %v", err)
+	}
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	if f.GetSheetIndex(sheet) == -1 {
+		f.Close()
+		return nil, errNoSheet
+	}
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	xr := &XLSXReader{f: f, rows: rows}
+	names, err := xr.next()
+	if err == nil {
+		xr.header, err = newHeader(names, m)
+	}
+	if err == io.EOF {
+		err = errNoColumns
+	}
+	if err != nil {
+		xr.Close()
+		return nil, err
+	}
+	return xr, nil
+}
+
+// Read returns the next row, or io.EOF after the last one. Rows are numbered as in the sheet.
+func (r *XLSXReader) Read() (*Row, error) {
+	for {
+		cells, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if !blank(cells) {
+			return r.header.row(r.line, cells), nil
+		}
+	}
+}
+
+func (r *XLSXReader) next() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	r.line++
+	return r.rows.Columns()
+}
+
+// Close releases the workbook.
+func (r *XLSXReader) Close() error {
+	r.rows.Close()
+	return r.f.Close()
+}
+
+// XLSXWriter writes contacts to the Contacts sheet of a workbook, with a frozen header row and
+// date cells. Rows are kept in a temporary file until the workbook is written. Contacts have no
+// groups or custom fields to add sheets for; their organizations get the optional sheet instead.
+type XLSXWriter struct {
+	f         *excelize.File
+	contacts  *excelize.StreamWriter
+	fields    []string
+	line      int
+	dateStyle int
+}
+
+// NewXLSXWriter starts a workbook with the given columns.
+func NewXLSXWriter(fields []string) (*XLSXWriter, error) {
+	fields, err := Columns(fields)
+	if err != nil {
+		return nil, err
+	}
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), ContactsSheet)
+	w := &XLSXWriter{f: f, fields: fields}
+	if w.dateStyle, err = f.NewStyle(&excelize.Style{NumFmt: dateFormat}); err != nil {
+		return nil, err
+	}
+	if w.contacts, err = w.newSheet(ContactsSheet, fields); err != nil {
+		return nil, err
+	}
+	w.line = 1
+	return w, nil
+}
+
+// newSheet starts streaming a sheet by writing its frozen header row. Panes must be set before
+// streaming.
+func (w *XLSXWriter) newSheet(name string, columns []string) (*excelize.StreamWriter, error) {
+	if w.f.GetSheetIndex(name) == -1 {
+		w.f.NewSheet(name)
+	}
+	panes := `{"freeze":true,"split":false,"x_split":0,"y_split":1,"top_left_cell":"A2","active_pane":"bottomLeft"}`
+	if err := w.f.SetPanes(name, panes); err != nil {
+		return nil, err
+	}
+	sw, err := w.f.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+	bold, err := w.f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	if err := sw.SetColWidth(1, len(columns), 24); err != nil {
+		return nil, err
+	}
+	header := make([]interface{}, len(columns))
+	for i, c := range columns {
+		header[i] = excelize.Cell{StyleID: bold, Value: c}
+	}
+	return sw, sw.SetRow("A1", header)
+}
+
+// Write adds the row to the Contacts sheet.
+func (w *XLSXWriter) Write(r *Row) error {
+	w.line++
+	cells := values(r, w.fields)
+	for i, v := range cells {
+		if v != nil && contains(DateFields, w.fields[i]) {
+			cells[i] = excelize.Cell{StyleID: w.dateStyle, Value: v}
+		}
+	}
+	axis, err := excelize.CoordinatesToCellName(1, w.line)
+	if err != nil {
+		return err
+	}
+	return w.contacts.SetRow(axis, cells)
+}
+
+// WriteOrganizations adds an Organizations sheet listing the organizations.
+func (w *XLSXWriter) WriteOrganizations(orgs []organization.Organization) error {
+	sw, err := w.newSheet(OrganizationsSheet, organizationColumns)
+	if err != nil {
+		return err
+	}
+	for i, o := range orgs {
+		axis, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(axis, []interface{}{o.Name, o.Domain, o.Phone, o.Address, o.Notes}); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// WriteTo finishes the workbook and writes it to out. Only Close can be called afterwards.
+func (w *XLSXWriter) WriteTo(out io.Writer) (int64, error) {
+	if err := w.contacts.Flush(); err != nil {
+		return 0, err
+	}
+	w.f.SetActiveSheet(w.f.GetSheetIndex(ContactsSheet))
+	return w.f.WriteTo(out)
+}
+
+// Close removes the temporary files of the workbook.
+func (w *XLSXWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,140 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+	"grpc-contact-manager/services/organization"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+func TestXLSXRoundTrip(t *testing.T) {
+	created := time.Date(2022, 3, 14, 15, 9, 26, 0, time.UTC)
+	rows := []*Row{
+		{
+			Contact: contact.Contact{
+				Model:    gorm.Model{CreatedAt: created, UpdatedAt: created},
+				Fullname: "Jane Doe",
+				Email:    "jane.doe@acme.com",
+				Phone:    "+234 703 330 4280",
+				Address:  "33, Tioya Street\nIbadan",
+				JobTitle: "Engineering Manager",
+			},
+			Organization: "Acme",
+		},
+		{Contact: contact.Contact{Fullname: "Ola Bello", Email: "ola@example.com"}},
+	}
+
+	w, err := NewXLSXWriter(nil)
+	require.NoError(t, err)
+	defer w.Close()
+	for _, r := range rows {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.WriteOrganizations([]organization.Organization{{Name: "Acme", Domain: "acme.com"}}))
+	var buf bytes.Buffer
+	_, err = w.WriteTo(&buf)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{ContactsSheet, OrganizationsSheet}, f.GetSheetList())
+	// dates are numbers shown in a date format
+	raw, err := f.GetCellValue(ContactsSheet, "H2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "44634.6"), raw)
+	formatted, err := f.GetCellValue(ContactsSheet, "H2")
+	require.NoError(t, err)
+	assert.Equal(t, "3/14/22 15:09", formatted)
+	domain, err := f.GetCellValue(OrganizationsSheet, "B2")
+	require.NoError(t, err)
+	assert.Equal(t, "acme.com", domain)
+	assert.Contains(t, sheetXML(t, buf.Bytes(), "xl/worksheets/sheet1.xml"), `state="frozen"`)
+
+	r, err := NewXLSXReader(bytes.NewReader(buf.Bytes()), Presets["default"], "")
+	require.NoError(t, err)
+	defer r.Close()
+	for i, want := range rows {
+		got, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, i+2, got.Line)
+		assert.Equal(t, want.Organization, got.Organization)
+		want.Contact.Model = gorm.Model{}
+		assert.Equal(t, want.Contact, got.Contact)
+	}
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestXLSXReader(t *testing.T) {
+	f := excelize.NewFile()
+	f.NewSheet("People")
+	for axis, v := range map[string]string{
+		"A1": "First Name", "B1": "Last Name", "C1": "E-mail Address", "D1": "Company",
+		"A2": "Renée", "B2": "François", "C2": "renee@example.fr", "D2": "Société Générale",
+		// a blank row is skipped without renumbering the next one
+		"A4": "John", "B4": "Smith",
+	} {
+		require.NoError(t, f.SetCellValue("People", axis, v))
+	}
+	var buf bytes.Buffer
+	_, err := f.WriteTo(&buf)
+	require.NoError(t, err)
+
+	_, err = NewXLSXReader(bytes.NewReader(buf.Bytes()), Outlook, "Missing")
+	assert.Equal(t, errNoSheet, err)
+	// the first sheet is empty
+	_, err = NewXLSXReader(bytes.NewReader(buf.Bytes()), Outlook, "")
+	assert.Equal(t, errNoColumns, err)
+	_, err = NewXLSXReader(strings.NewReader("name,email\n"), Outlook, "")
+	assert.Error(t, err)
+
+	r, err := NewXLSXReader(bytes.NewReader(buf.Bytes()), Outlook, "People")
+	require.NoError(t, err)
+	defer r.Close()
+	row, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 2, row.Line)
+	assert.Equal(t, "Renée François", row.Contact.Fullname)
+	assert.Equal(t, "Société Générale", row.Organization)
+	row, err = r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 4, row.Line)
+	assert.Empty(t, row.Contact.Email)
+}
+
+func TestXLSXReaderUnzipLimit(t *testing.T) {
+	w, err := NewXLSXWriter(nil)
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.Write(&Row{Contact: contact.Contact{Fullname: strings.Repeat("Jane Doe ", 1000)}}))
+	var buf bytes.Buffer
+	_, err = w.WriteTo(&buf)
+	require.NoError(t, err)
+
+	defer func(limit int64) { maxUnzippedSize = limit }(maxUnzippedSize)
+	maxUnzippedSize = 4 << 10
+	_, err = NewXLSXReader(bytes.NewReader(buf.Bytes()), Presets["default"], "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unzip size exceeds")
+}
+
+func sheetXML(t *testing.T, workbook []byte, name string) string {
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	require.NoError(t, err)
+	f, err := zr.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores blobs as files below a root directory.
+type Local struct {
+	Root string
+}
+
+// NewLocal creates a local filesystem blob store rooted at the given directory.
+func NewLocal(root string) (*Local, error) {
+	if root == "" {
+		return nil, errEmptyRoot
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{Root: root}, nil
+}
+
+// Put writes the blob to a temporary file first so readers never see a partial blob.
+func (l *Local) Put(key string, r io.Reader) (int64, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return n, os.Rename(f.Name(), p)
+}
+
+// Get opens the file stored under key.
+func (l *Local) Get(key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes the file stored under key.
+func (l *Local) Delete(key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a slash separated key to a file below the root, rejecting keys that escape it.
+func (l *Local) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", errInvalidKey
+	}
+	clean := path.Clean(key)
+	if clean != key || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", errInvalidKey
+	}
+	return filepath.Join(l.Root, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalPutGetDelete(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	n, err := store.Put("photos/1/2/original", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), n)
+
+	r, err := store.Get("photos/1/2/original")
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "hello world", string(b))
+
+	require.NoError(t, store.Delete("photos/1/2/original"))
+	_, err = store.Get("photos/1/2/original")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// deleting twice is fine
+	require.NoError(t, store.Delete("photos/1/2/original"))
+}
+
+func TestLocalInvalidKey(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	table := []string{"", "/etc/passwd", "../secret", "photos/../../secret", "photos//1"}
+	for _, key := range table {
+		t.Run(key, func(t *testing.T) {
+			_, err := store.Put(key, strings.NewReader("x"))
+			assert.Equal(t, errInvalidKey, err)
+		})
+	}
+}
+
+func TestNewLocalWithoutRoot(t *testing.T) {
+	store, err := NewLocal("")
+	assert.Nil(t, store)
+	assert.Equal(t, errEmptyRoot, err)
+}
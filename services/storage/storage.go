@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound is returned when no blob is stored under the requested key.
+	ErrNotFound = errors.New("blob not found")
+
+	errInvalidKey = errors.New("invalid blob key")
+	errEmptyRoot  = errors.New("storage root must be provided")
+)
+
+// BlobStore abstracts where binary objects such as photos are kept.
+type BlobStore interface {
+	// Put stores the content of r under key, replacing any existing blob, and returns the number of bytes written.
+	Put(key string, r io.Reader) (int64, error)
+	// Get opens the blob stored under key. The caller must close the returned reader.
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(key string) error
+}
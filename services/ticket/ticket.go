@@ -0,0 +1,103 @@
+// Package ticket issues short-lived, single-use tickets that stand for the bearer token of a user
+// on connections that can't send headers, such as the EventSource and WebSocket ones of browsers.
+// Tickets are kept in the database, so any instance can redeem them.
+package ticket
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TTL is how long a ticket can be redeemed after it was issued.
+const TTL = 30 * time.Second
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+
+	// ErrInvalid is returned for tickets that are unknown, expired or already redeemed.
+	ErrInvalid = errors.New("invalid or expired ticket")
+)
+
+// Ticket is an issued ticket. Only the hash of its value is stored.
+type Ticket struct {
+	Hash      string    `gorm:"primaryKey"`
+	UserID    uint      `gorm:"column:user_id"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// TableName names the table after what the tickets open.
+func (Ticket) TableName() string {
+	return "stream_tickets"
+}
+
+// DB - ticket repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the ticket repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the tickets table
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Ticket{})
+}
+
+// Issue returns a new ticket for the user, valid for TTL. Expired tickets are removed meanwhile.
+func (d *DB) Issue(userID uint) (string, error) {
+	if userID == 0 {
+		return "", errInvalidUserID
+	}
+	now := time.Now()
+	if err := d.Conn.Where("expires_at <= ?", now).Delete(&Ticket{}).Error; err != nil {
+		return "", err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(b)
+	t := Ticket{Hash: hash(value), UserID: userID, ExpiresAt: now.Add(TTL)}
+	if err := d.Conn.Create(&t).Error; err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Redeem returns the user the ticket was issued to and removes it, so it can't be used again.
+func (d *DB) Redeem(value string) (uint, error) {
+	var t Ticket
+	res := d.Conn.Where("hash = ?", hash(value)).Limit(1).Find(&t)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrInvalid
+	}
+	// only one of the requests redeeming the ticket at once deletes it
+	res = d.Conn.Where("hash = ? AND expires_at > ?", t.Hash, time.Now()).Delete(&Ticket{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrInvalid
+	}
+	return t.UserID, nil
+}
+
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
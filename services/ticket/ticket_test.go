@@ -0,0 +1,82 @@
+package ticket
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db *DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db = d
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestIssueAndRedeem(t *testing.T) {
+	_, err := db.Issue(0)
+	assert.Equal(t, errInvalidUserID, err)
+
+	value, err := db.Issue(7)
+	require.NoError(t, err)
+	var stored Ticket
+	require.NoError(t, db.Conn.First(&stored).Error)
+	assert.NotEqual(t, value, stored.Hash)
+
+	userID, err := db.Redeem(value)
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), userID)
+
+	// a ticket is redeemed once
+	_, err = db.Redeem(value)
+	assert.Equal(t, ErrInvalid, err)
+	_, err = db.Redeem("unknown")
+	assert.Equal(t, ErrInvalid, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestExpiredTicket(t *testing.T) {
+	value, err := db.Issue(7)
+	require.NoError(t, err)
+	require.NoError(t, db.Conn.Model(&Ticket{}).Where("hash = ?", hash(value)).
+		Update("expires_at", time.Now().Add(-time.Second)).Error)
+	_, err = db.Redeem(value)
+	assert.Equal(t, ErrInvalid, err)
+
+	// issuing removes the expired tickets
+	_, err = db.Issue(8)
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Conn.Model(&Ticket{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func cleanup() error {
+	return db.Conn.Exec("DELETE FROM stream_tickets").Error
+}
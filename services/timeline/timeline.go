@@ -0,0 +1,302 @@
+package timeline
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"grpc-contact-manager/services/changelog"
+	"grpc-contact-manager/services/contact"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPageSize is the number of timeline entries returned when no page size is requested.
+	DefaultPageSize = 20
+	// MaxPageSize caps the number of timeline entries returned in one page.
+	MaxPageSize = 100
+)
+
+// Interaction types
+const (
+	Call    = "call"
+	Meeting = "meeting"
+	Email   = "email"
+	Message = "message"
+)
+
+// Timeline entry kinds
+const (
+	KindNote        = "note"
+	KindInteraction = "interaction"
+	KindEdit        = "edit"
+)
+
+var (
+	errConnNotInitialized = errors.New("connection not initialized")
+	errInvalidUserID      = errors.New("invalid user id")
+	errInvalidContactID   = errors.New("invalid contact id")
+	errEmptyBody          = errors.New("note must not be empty")
+	errEmptySummary       = errors.New("interaction summary must be provided")
+	errInvalidType        = errors.New("interaction type must be one of call, meeting, email or message")
+	errInvalidPageToken   = errors.New("invalid page token")
+
+	// ErrNoteNotFound is returned when the note doesn't exist or belongs to another user.
+	ErrNoteNotFound = errors.New("note not found")
+	// ErrInteractionNotFound is returned when the interaction doesn't exist or belongs to another user.
+	ErrInteractionNotFound = errors.New("interaction not found")
+
+	interactionTypes = map[string]bool{
+		Call:    true,
+		Meeting: true,
+		Email:   true,
+		Message: true,
+	}
+)
+
+// Note is a free text note on a contact.
+type Note struct {
+	gorm.Model
+	UserID    uint   `json:"user_id" gorm:"column:user_id;index"`
+	ContactID uint   `json:"contact_id" gorm:"column:contact_id;index"`
+	Body      string `json:"body"`
+}
+
+// Interaction is a call, meeting, email or message logged against a contact.
+type Interaction struct {
+	gorm.Model
+	UserID     uint      `json:"user_id" gorm:"column:user_id;index"`
+	ContactID  uint      `json:"contact_id" gorm:"column:contact_id;index"`
+	Type       string    `json:"type"`
+	Summary    string    `json:"summary"`
+	OccurredAt time.Time `json:"occurred_at" gorm:"index"`
+}
+
+// Entry is a single item of a contact timeline. Exactly one of Note, Interaction and Edit is set.
+type Entry struct {
+	Kind        string
+	At          time.Time
+	Note        *Note
+	Interaction *Interaction
+	Edit        *contact.Revision
+}
+
+// DB - notes and interactions repository
+type DB struct {
+	Conn *gorm.DB
+}
+
+// New creates a new instance of the timeline repository
+func New(conn *gorm.DB) (*DB, error) {
+	if conn == nil {
+		return nil, errConnNotInitialized
+	}
+	return &DB{Conn: conn}, nil
+}
+
+// Migrate creates the notes and interactions tables
+func (d *DB) Migrate() error {
+	return d.Conn.AutoMigrate(Note{}, Interaction{})
+}
+
+// AddNote adds a note to a contact.
+func (d *DB) AddNote(note Note) (*Note, error) {
+	if err := note.validate(); err != nil {
+		return nil, err
+	}
+	return &note, d.Conn.Create(&note).Error
+}
+
+// DeleteNote removes a note.
+func (d *DB) DeleteNote(userID, id uint) (*Note, error) {
+	var note Note
+	err := d.Conn.Where("user_id = ?", userID).First(&note, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &note, d.Conn.Delete(&note).Error
+}
+
+// LogInteraction records an interaction and moves the contact's last contacted time forward when it is newer.
+func (d *DB) LogInteraction(i Interaction) (*Interaction, error) {
+	if i.OccurredAt.IsZero() {
+		i.OccurredAt = time.Now()
+	}
+	if err := i.validate(); err != nil {
+		return nil, err
+	}
+	err := d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&i).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&contact.Contact{}).
+			Where("id = ? AND user_id = ? AND (last_contacted_at IS NULL OR last_contacted_at < ?)", i.ContactID, i.UserID, i.OccurredAt).
+			UpdateColumn("last_contacted_at", i.OccurredAt)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return changelog.Record(tx, i.UserID, changelog.Update, i.ContactID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// DeleteInteraction removes an interaction and recomputes the contact's last contacted time.
+func (d *DB) DeleteInteraction(userID, id uint) (*Interaction, error) {
+	var i Interaction
+	err := d.Conn.Where("user_id = ?", userID).First(&i, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInteractionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = d.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&i).Error; err != nil {
+			return err
+		}
+		var latest Interaction
+		err := tx.Where("user_id = ? AND contact_id = ?", userID, i.ContactID).Order("occurred_at DESC").First(&latest).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		var last *time.Time
+		if latest.ID != 0 {
+			last = &latest.OccurredAt
+		}
+		if err := tx.Model(&contact.Contact{}).Where("id = ?", i.ContactID).UpdateColumn("last_contacted_at", last).Error; err != nil {
+			return err
+		}
+		return changelog.Record(tx, userID, changelog.Update, i.ContactID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// Timeline returns a page of notes, interactions and edits of the contact, newest first,
+// together with the token of the next page. The token is empty on the last page.
+func (d *DB) Timeline(userID, contactID uint, pageSize int, pageToken string) ([]Entry, string, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	offset := 0
+	if pageToken != "" {
+		o, err := strconv.Atoi(pageToken)
+		if err != nil || o < 0 {
+			return nil, "", errInvalidPageToken
+		}
+		offset = o
+	}
+
+	var refs []struct {
+		Kind string
+		ID   uint
+	}
+	// fetch one extra row to know whether there is a next page
+	err := d.Conn.Raw(`SELECT kind, id FROM (
+		SELECT 'note' AS kind, id, created_at AS at FROM notes WHERE user_id = ? AND contact_id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT 'interaction' AS kind, id, occurred_at AS at FROM interactions WHERE user_id = ? AND contact_id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT 'edit' AS kind, id, created_at AS at FROM revisions WHERE user_id = ? AND contact_id = ?
+	) AS entries ORDER BY at DESC, kind, id DESC LIMIT ? OFFSET ?`,
+		userID, contactID, userID, contactID, userID, contactID, pageSize+1, offset).Scan(&refs).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(refs) > pageSize {
+		refs = refs[:pageSize]
+		next = strconv.Itoa(offset + pageSize)
+	}
+
+	ids := map[string][]uint{}
+	for _, r := range refs {
+		ids[r.Kind] = append(ids[r.Kind], r.ID)
+	}
+	var (
+		notes        []Note
+		interactions []Interaction
+		edits        []contact.Revision
+	)
+	if err := d.findByIDs(&notes, ids[KindNote]); err != nil {
+		return nil, "", err
+	}
+	if err := d.findByIDs(&interactions, ids[KindInteraction]); err != nil {
+		return nil, "", err
+	}
+	if err := d.findByIDs(&edits, ids[KindEdit]); err != nil {
+		return nil, "", err
+	}
+
+	byRef := make(map[string]map[uint]Entry, 3)
+	byRef[KindNote] = make(map[uint]Entry, len(notes))
+	for i := range notes {
+		byRef[KindNote][notes[i].ID] = Entry{Kind: KindNote, At: notes[i].CreatedAt, Note: &notes[i]}
+	}
+	byRef[KindInteraction] = make(map[uint]Entry, len(interactions))
+	for i := range interactions {
+		byRef[KindInteraction][interactions[i].ID] = Entry{Kind: KindInteraction, At: interactions[i].OccurredAt, Interaction: &interactions[i]}
+	}
+	byRef[KindEdit] = make(map[uint]Entry, len(edits))
+	for i := range edits {
+		byRef[KindEdit][edits[i].ID] = Entry{Kind: KindEdit, At: edits[i].CreatedAt, Edit: &edits[i]}
+	}
+
+	entries := make([]Entry, 0, len(refs))
+	for _, r := range refs {
+		if e, ok := byRef[r.Kind][r.ID]; ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, next, nil
+}
+
+// findByIDs loads the rows with the given primary keys into dest.
+func (d *DB) findByIDs(dest interface{}, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.Conn.Find(dest, ids).Error
+}
+
+func (n *Note) validate() error {
+	if n.UserID == 0 {
+		return errInvalidUserID
+	}
+	if n.ContactID == 0 {
+		return errInvalidContactID
+	}
+	if n.Body == "" {
+		return errEmptyBody
+	}
+	return nil
+}
+
+func (i *Interaction) validate() error {
+	if i.UserID == 0 {
+		return errInvalidUserID
+	}
+	if i.ContactID == 0 {
+		return errInvalidContactID
+	}
+	if !interactionTypes[i.Type] {
+		return errInvalidType
+	}
+	if i.Summary == "" {
+		return errEmptySummary
+	}
+	return nil
+}
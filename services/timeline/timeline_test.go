@@ -0,0 +1,192 @@
+package timeline
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	db        *DB
+	contactDB *contact.DB
+)
+
+func TestMain(m *testing.M) {
+	conn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := contact.New(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, contactDB = d, c
+	if err := contactDB.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestValidateInteraction(t *testing.T) {
+	table := []struct {
+		name        string
+		interaction Interaction
+		want        error
+	}{
+		{
+			name:        "All good",
+			interaction: Interaction{UserID: 1, ContactID: 1, Type: Call, Summary: "Talked about the contract"},
+			want:        nil,
+		},
+		{
+			name:        "Invalid User",
+			interaction: Interaction{ContactID: 1, Type: Call, Summary: "Talked about the contract"},
+			want:        errInvalidUserID,
+		},
+		{
+			name:        "Invalid Contact",
+			interaction: Interaction{UserID: 1, Type: Call, Summary: "Talked about the contract"},
+			want:        errInvalidContactID,
+		},
+		{
+			name:        "Invalid Type",
+			interaction: Interaction{UserID: 1, ContactID: 1, Type: "fax", Summary: "Talked about the contract"},
+			want:        errInvalidType,
+		},
+		{
+			name:        "Empty Summary",
+			interaction: Interaction{UserID: 1, ContactID: 1, Type: Meeting},
+			want:        errEmptySummary,
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.interaction.validate())
+		})
+	}
+}
+
+func TestLastContactedAt(t *testing.T) {
+	c := createContact(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	recent, err := db.LogInteraction(Interaction{UserID: 1, ContactID: c.ID, Type: Call, Summary: "Called", OccurredAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, now, lastContacted(t, c.ID))
+
+	// an older interaction doesn't move the time back
+	_, err = db.LogInteraction(Interaction{UserID: 1, ContactID: c.ID, Type: Email, Summary: "Emailed", OccurredAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, now, lastContacted(t, c.ID))
+
+	_, err = db.DeleteInteraction(1, recent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), lastContacted(t, c.ID))
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestTimeline(t *testing.T) {
+	c := createContact(t)
+	base := time.Now().Add(time.Hour)
+
+	note, err := db.AddNote(Note{UserID: 1, ContactID: c.ID, Body: "Prefers email"})
+	require.NoError(t, err)
+	_, err = db.LogInteraction(Interaction{UserID: 1, ContactID: c.ID, Type: Meeting, Summary: "Lunch", OccurredAt: base})
+	require.NoError(t, err)
+	_, err = db.LogInteraction(Interaction{UserID: 1, ContactID: c.ID, Type: Call, Summary: "Follow up", OccurredAt: base.Add(time.Hour)})
+	require.NoError(t, err)
+
+	entries, next, err := db.Timeline(1, c.ID, 2, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.NotEmpty(t, next)
+	assert.Equal(t, "Follow up", entries[0].Interaction.Summary)
+	assert.Equal(t, "Lunch", entries[1].Interaction.Summary)
+
+	entries, next, err = db.Timeline(1, c.ID, 2, next)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Empty(t, next)
+	assert.Equal(t, KindNote, entries[0].Kind)
+	assert.Equal(t, note.ID, entries[0].Note.ID)
+	assert.Equal(t, KindEdit, entries[1].Kind)
+	assert.Equal(t, contact.ActionCreate, entries[1].Edit.Action)
+
+	// other users see nothing
+	entries, _, err = db.Timeline(2, c.ID, 10, "")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, _, err = db.Timeline(1, c.ID, 10, "not-a-token")
+	assert.Equal(t, errInvalidPageToken, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func TestDeleteNote(t *testing.T) {
+	c := createContact(t)
+	note, err := db.AddNote(Note{UserID: 1, ContactID: c.ID, Body: "Prefers email"})
+	require.NoError(t, err)
+
+	_, err = db.DeleteNote(2, note.ID)
+	assert.Equal(t, ErrNoteNotFound, err)
+	_, err = db.DeleteNote(1, note.ID)
+	require.NoError(t, err)
+
+	entries, _, err := db.Timeline(1, c.ID, 10, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, KindEdit, entries[0].Kind)
+
+	t.Cleanup(func() {
+		require.NoError(t, cleanup())
+	})
+}
+
+func createContact(t *testing.T) *contact.Contact {
+	c, err := contactDB.Create(contact.Contact{
+		UserID:   1,
+		Fullname: "Alugbin Abiodun",
+		Email:    "tolaabbey009@gmail.com",
+		Phone:    "+2347033304280",
+		Address:  "33, Tioya Street, Ibadan",
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func lastContacted(t *testing.T, contactID uint) time.Time {
+	c, err := contactDB.FindByID(1, contactID)
+	require.NoError(t, err)
+	require.NotNil(t, c.LastContactedAt)
+	return c.LastContactedAt.UTC()
+}
+
+func cleanup() error {
+	for _, table := range []string{"notes", "interactions", "revisions", "contacts"} {
+		if err := db.Conn.Exec("DELETE FROM " + table).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -49,3 +49,8 @@ func validateToken(tokenString string) (uint32, error) {
 
 	return uint32(claims["user_id"].(float64)), nil
 }
+
+// ValidateToken validates the token string and returns the ID of the user it was issued to.
+func ValidateToken(tokenString string) (uint32, error) {
+	return validateToken(tokenString)
+}
@@ -3,6 +3,8 @@ package user
 import (
 	"errors"
 
+	"grpc-contact-manager/services/changefeed"
+
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -30,6 +32,8 @@ type User struct {
 
 type DB struct {
 	Conn *gorm.DB
+	// Feed, when set, is told about the users created through this DB.
+	Feed changefeed.Feed
 }
 
 // New creates a new instance of the user repository
@@ -57,6 +61,9 @@ func (d *DB) Create(user User) (*User, error) {
 	user.Password = string(password)
 
 	result := d.Conn.Create(&user)
+	if result.Error == nil && d.Feed != nil {
+		d.Feed.Publish(changefeed.Event{Topic: changefeed.Users, UserID: user.ID})
+	}
 
 	user.Password = "" //Clear the password before sending it back to user
 	return &user, result.Error
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"grpc-contact-manager/services/changefeed"
 	"grpc-contact-manager/services/mocks"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -108,6 +109,11 @@ func TestCreate(t *testing.T) {
 		Email:    "tolaabbey009@gmail.com",
 		Password: "password",
 	}
+	feed := changefeed.NewMemory()
+	sub := feed.Subscribe(changefeed.Event{Topic: changefeed.Users, UserID: 1})
+	defer sub.Close()
+	db.Feed = feed
+	defer func() { db.Feed = nil }()
 
 	res, err := db.Create(user)
 	require.Nil(t, err)
@@ -115,6 +121,7 @@ func TestCreate(t *testing.T) {
 	assert.Equal(t, res.Password, "")
 	assert.Equal(t, res.Email, "tolaabbey009@gmail.com")
 	assert.True(t, res.CreatedAt.Before(time.Now()))
+	assert.Len(t, sub.C, 1)
 }
 
 func TestCreateWithNoEmail(t *testing.T) {
@@ -0,0 +1,115 @@
+package vcard
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	govcard "github.com/emersion/go-vcard"
+)
+
+// maxLineLength is the longest a content line may be, in octets, before it is folded.
+const maxLineLength = 75
+
+var (
+	textEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`)
+
+	// raw properties hold URIs, binary data or structured values whose components are already
+	// escaped, see component
+	raw = map[string]bool{
+		govcard.FieldPhoto:        true,
+		govcard.FieldVersion:      true,
+		govcard.FieldRevision:     true,
+		govcard.FieldName:         true,
+		govcard.FieldAddress:      true,
+		govcard.FieldOrganization: true,
+	}
+)
+
+// foldWriter writes cards with escaped values and folded lines, as the go-vcard encoder does neither.
+type foldWriter struct {
+	w io.Writer
+}
+
+func (f *foldWriter) writeCard(card govcard.Card) error {
+	if err := f.writeLine("BEGIN:VCARD"); err != nil {
+		return err
+	}
+	if err := f.writeLine(govcard.FieldVersion + ":" + card.Value(govcard.FieldVersion)); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(card))
+	for k := range card {
+		if k != govcard.FieldVersion {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, field := range card[k] {
+			if err := f.writeLine(formatLine(k, field)); err != nil {
+				return err
+			}
+		}
+	}
+	return f.writeLine("END:VCARD")
+}
+
+// writeLine writes a content line, folding it every 75 octets without splitting characters.
+func (f *foldWriter) writeLine(line string) error {
+	var b strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space
+		limit = maxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	_, err := io.WriteString(f.w, b.String())
+	return err
+}
+
+func formatLine(key string, field *govcard.Field) string {
+	var b strings.Builder
+	if field.Group != "" {
+		b.WriteString(field.Group + ".")
+	}
+	b.WriteString(key)
+	params := make([]string, 0, len(field.Params))
+	for k := range field.Params {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		b.WriteString(";" + k + "=")
+		for i, v := range field.Params[k] {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(formatParam(v))
+		}
+	}
+	b.WriteString(":")
+	if raw[key] {
+		b.WriteString(field.Value)
+	} else {
+		b.WriteString(textEscaper.Replace(field.Value))
+	}
+	return b.String()
+}
+
+// formatParam quotes parameter values holding characters that would end them.
+func formatParam(v string) string {
+	if strings.ContainsAny(v, `:;,`) {
+		return `"` + strings.ReplaceAll(v, `"`, `'`) + `"`
+	}
+	return v
+}
@@ -0,0 +1,311 @@
+// Package vcard converts contacts to and from vCard 3.0 (RFC 2426) and 4.0 (RFC 6350).
+package vcard
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"grpc-contact-manager/services/contact"
+
+	govcard "github.com/emersion/go-vcard"
+)
+
+// Supported vCard versions
+const (
+	Version3 = "3.0"
+	Version4 = "4.0"
+)
+
+// ContentType is the media type of vCard files.
+const ContentType = govcard.MIMEType
+
+var errUnsupportedVersion = errors.New("vcard version must be 3.0 or 4.0")
+
+// Entry is a contact along with the vCard properties stored outside of contact.Contact.
+type Entry struct {
+	Contact contact.Contact
+	// Organization is the name of the contact's organization.
+	Organization string
+	// UID identifies the card across address books, when it has one.
+	UID string
+	// Photo holds the image data of the contact's photo, if any.
+	Photo            []byte
+	PhotoContentType string
+}
+
+// ToCard converts the entry to a card of the given version.
+func ToCard(e *Entry, version string) (govcard.Card, error) {
+	if version != Version3 && version != Version4 {
+		return nil, errUnsupportedVersion
+	}
+	c := &e.Contact
+	card := govcard.Card{}
+	card.SetValue(govcard.FieldVersion, version)
+	card.SetValue(govcard.FieldFormattedName, c.Fullname)
+	card.SetName(splitName(c.Fullname))
+	if c.Email != "" {
+		card.Set(govcard.FieldEmail, &govcard.Field{Value: c.Email, Params: typed("internet")})
+	}
+	if c.Phone != "" {
+		card.Set(govcard.FieldTelephone, &govcard.Field{Value: c.Phone, Params: typed("voice")})
+	}
+	if c.Address != "" {
+		card.SetAddress(&govcard.Address{StreetAddress: component(c.Address)})
+	}
+	if e.Organization != "" || c.Department != "" {
+		card.SetValue(govcard.FieldOrganization, component(e.Organization)+";"+component(c.Department))
+	}
+	if c.JobTitle != "" {
+		card.SetValue(govcard.FieldTitle, c.JobTitle)
+	}
+	if !c.UpdatedAt.IsZero() {
+		card.SetRevision(c.UpdatedAt)
+	}
+	if e.UID != "" {
+		card.SetValue(govcard.FieldUID, e.UID)
+	}
+	if len(e.Photo) > 0 {
+		card.Set(govcard.FieldPhoto, photoField(e.Photo, e.PhotoContentType, version))
+	}
+	return card, nil
+}
+
+// FromCard converts a card of any version to an entry.
+func FromCard(card govcard.Card) (*Entry, error) {
+	e := &Entry{}
+	c := &e.Contact
+	c.Fullname = text(card.PreferredValue(govcard.FieldFormattedName))
+	if c.Fullname == "" {
+		c.Fullname = joinNonEmpty(" ", structuredValue(card, govcard.FieldName)...)
+	}
+	c.Email = text(card.PreferredValue(govcard.FieldEmail))
+	c.Phone = strings.TrimPrefix(text(card.PreferredValue(govcard.FieldTelephone)), "tel:")
+	c.Address = joinAddress(card)
+	if org := structuredValue(card, govcard.FieldOrganization); len(org) > 0 {
+		e.Organization = org[0]
+		if len(org) > 1 {
+			c.Department = org[1]
+		}
+	}
+	c.JobTitle = text(card.PreferredValue(govcard.FieldTitle))
+	e.UID = card.Value(govcard.FieldUID)
+	if f := card.Preferred(govcard.FieldPhoto); f != nil {
+		data, contentType, err := decodePhoto(f)
+		if err != nil {
+			return nil, err
+		}
+		e.Photo, e.PhotoContentType = data, contentType
+	}
+	return e, nil
+}
+
+// Decoder reads entries from a stream of cards.
+type Decoder struct {
+	dec *govcard.Decoder
+}
+
+// NewDecoder creates a decoder reading cards from r. Folded lines are unfolded, and the bare
+// parameters of vCard 2.1 are read as types.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: govcard.NewDecoder(&paramReader{r: bufio.NewReader(r)})}
+}
+
+// Decode returns the next entry, or io.EOF once all the cards were read.
+func (d *Decoder) Decode() (*Entry, error) {
+	card, err := d.dec.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return FromCard(card)
+}
+
+// Encoder writes entries as cards of a version.
+type Encoder struct {
+	w       *foldWriter
+	version string
+}
+
+// NewEncoder creates an encoder writing cards of the given version to w. Long lines are folded.
+func NewEncoder(w io.Writer, version string) (*Encoder, error) {
+	if version == "" {
+		version = Version4
+	}
+	if version != Version3 && version != Version4 {
+		return nil, errUnsupportedVersion
+	}
+	return &Encoder{w: &foldWriter{w: w}, version: version}, nil
+}
+
+// Encode writes the entry as a card.
+func (e *Encoder) Encode(entry *Entry) error {
+	card, err := ToCard(entry, e.version)
+	if err != nil {
+		return err
+	}
+	return e.w.writeCard(card)
+}
+
+// splitName splits a full name into given and family names. The family name is the last word.
+func splitName(fullname string) *govcard.Name {
+	words := strings.Fields(fullname)
+	if len(words) < 2 {
+		return &govcard.Name{GivenName: component(fullname)}
+	}
+	return &govcard.Name{
+		GivenName:  component(strings.Join(words[:len(words)-1], " ")),
+		FamilyName: component(words[len(words)-1]),
+	}
+}
+
+// joinAddress flattens the preferred address, falling back to its label.
+func joinAddress(card govcard.Card) string {
+	f := card.Preferred(govcard.FieldAddress)
+	if f == nil {
+		return text(card.PreferredValue("LABEL"))
+	}
+	joined := joinNonEmpty(", ", splitComponents(f.Value)...)
+	if joined == "" {
+		return text(f.Params.Get("LABEL"))
+	}
+	return joined
+}
+
+// structuredValue returns the unescaped components of the preferred value of a structured property.
+func structuredValue(card govcard.Card, k string) []string {
+	f := card.Preferred(k)
+	if f == nil {
+		return nil
+	}
+	components := splitComponents(f.Value)
+	for i, c := range components {
+		components[i] = text(c)
+	}
+	return components
+}
+
+// splitComponents splits a structured value on its unescaped semicolons. Unlike the go-vcard
+// helpers, it keeps escaped semicolons within their component.
+func splitComponents(v string) []string {
+	var components []string
+	start := 0
+	for i := 0; i < len(v); i++ {
+		if v[i] == ';' && (i == 0 || v[i-1] != '\\') {
+			components = append(components, v[start:i])
+			start = i + 1
+		}
+	}
+	return append(components, v[start:])
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	var parts []string
+	for _, v := range values {
+		if v = strings.TrimSpace(text(v)); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func typed(t string) govcard.Params {
+	return govcard.Params{govcard.ParamType: []string{t}}
+}
+
+// component escapes a value for use as a component of a structured property.
+func component(v string) string {
+	return textEscaper.Replace(v)
+}
+
+// text undoes the escaping of semicolons, which the decoder leaves alone.
+func text(v string) string {
+	return strings.ReplaceAll(v, `\;`, ";")
+}
+
+func photoField(data []byte, contentType, version string) *govcard.Field {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if version == Version3 {
+		subtype := strings.ToUpper(strings.TrimPrefix(contentType, "image/"))
+		return &govcard.Field{
+			Value:  encoded,
+			Params: govcard.Params{"ENCODING": []string{"b"}, govcard.ParamType: []string{subtype}},
+		}
+	}
+	return &govcard.Field{Value: "data:" + contentType + ";base64," + encoded}
+}
+
+// decodePhoto returns the image of an inline photo. Photos given by URL are ignored.
+func decodePhoto(f *govcard.Field) ([]byte, string, error) {
+	value := strings.Join(strings.Fields(f.Value), "")
+	if enc := strings.ToLower(f.Params.Get("ENCODING")); enc == "b" || enc == "base64" {
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, http.DetectContentType(data), nil
+	}
+	if !strings.HasPrefix(value, "data:") {
+		return nil, "", nil
+	}
+	meta, payload, ok := cut(strings.TrimPrefix(value, "data:"), ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return nil, "", nil
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, strings.TrimSuffix(meta, ";base64"), nil
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// paramReader rewrites bare parameters such as TEL;WORK;VOICE to TYPE parameters, which go-vcard
+// would otherwise mistake for the value.
+type paramReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (p *paramReader) Read(b []byte) (int, error) {
+	for len(p.buf) == 0 {
+		line, err := p.r.ReadString('\n')
+		if line == "" {
+			return 0, err
+		}
+		p.buf = []byte(typeBareParams(line))
+	}
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	return n, nil
+}
+
+func typeBareParams(line string) string {
+	if line[0] == ' ' || line[0] == '\t' {
+		// folded continuation
+		return line
+	}
+	name, value, ok := cut(line, ":")
+	if !ok || strings.Contains(name, `"`) {
+		// quoted parameters may hold colons, and are never bare
+		return line
+	}
+	params := strings.Split(name, ";")
+	for i := 1; i < len(params); i++ {
+		if params[i] != "" && !strings.Contains(params[i], "=") {
+			params[i] = govcard.ParamType + "=" + params[i]
+		}
+	}
+	return strings.Join(params, ";") + ":" + value
+}
@@ -0,0 +1,166 @@
+package vcard
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"grpc-contact-manager/services/contact"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeFile(t *testing.T, name string) []*Entry {
+	f, err := os.Open("testdata/" + name)
+	require.NoError(t, err)
+	defer f.Close()
+	var entries []*Entry
+	dec := NewDecoder(f)
+	for {
+		e, err := dec.Decode()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		entries = append(entries, e)
+	}
+}
+
+func TestDecodeClients(t *testing.T) {
+	table := []struct {
+		file string
+		want []Entry
+	}{
+		{
+			file: "apple.vcf",
+			want: []Entry{{
+				Contact: contact.Contact{
+					Fullname:   "John Appleseed",
+					Email:      "j.appleseed@apple.com",
+					Phone:      "+1 (555) 123-4567",
+					Address:    "1 Infinite Loop, Cupertino, CA, 95014, United States",
+					JobTitle:   "Software Engineer",
+					Department: "Engineering",
+				},
+				Organization:     "Apple Inc.",
+				PhotoContentType: "image/png",
+			}},
+		},
+		{
+			file: "google.vcf",
+			want: []Entry{
+				{
+					Contact: contact.Contact{
+						Fullname: "Jane Doe",
+						Email:    "jane.doe@acme.com",
+						Phone:    "+234 703 330 4280",
+						Address:  "33, Tioya Street, Ibadan, Oyo, 200001, Nigeria",
+						JobTitle: "Engineering Manager",
+					},
+					Organization: "Acme",
+				},
+				{
+					Contact: contact.Contact{
+						Fullname: "Ola Bello",
+						Email:    "ola@example.com",
+						Phone:    "08155040074",
+						Address:  "12 Allen Avenue, Ikeja, Lagos, Nigeria",
+					},
+				},
+			},
+		},
+		{
+			file: "outlook.vcf",
+			want: []Entry{{
+				Contact: contact.Contact{
+					Fullname:   "Mary Smith",
+					Email:      "mary.smith@contoso.com",
+					Phone:      "(425) 555-0100",
+					Address:    "1 Microsoft Way, Redmond, WA, 98052, United States of America",
+					JobTitle:   "Account Manager",
+					Department: "Sales",
+				},
+				Organization: "Contoso",
+			}},
+		},
+		{
+			file: "thunderbird.vcf",
+			want: []Entry{{
+				Contact: contact.Contact{
+					Fullname: "Dr. Jürgen Müller",
+					Email:    "juergen@example.de",
+					Phone:    "+49-170-1234567",
+					Address:  "Unter den Linden 1, Berlin, 10117, Germany",
+				},
+				UID:              "urn:uuid:4fbe8971-0bc3-424c-9c26-36c3e1eff6b1",
+				PhotoContentType: "image/png",
+			}},
+		},
+	}
+	for _, tt := range table {
+		t.Run(tt.file, func(t *testing.T) {
+			entries := decodeFile(t, tt.file)
+			require.Len(t, entries, len(tt.want))
+			for i, e := range entries {
+				if tt.want[i].PhotoContentType != "" {
+					assert.Equal(t, "\x89PNG", string(e.Photo[:4]))
+				}
+				e.Photo = nil
+				assert.Equal(t, tt.want[i], *e)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	entries := append(decodeFile(t, "apple.vcf"), decodeFile(t, "google.vcf")...)
+	entries[1].Contact.Address = "Flat 2; 33, Tioya Street\nIbadan"
+	entries[2].Contact.Fullname = "Ọlá Bello-Adéyẹmí, Jr."
+	entries[2].UID = "urn:uuid:0e6c8a3c-93f1-4b5e-b7b2-3f1d2a9c6e41"
+	for _, version := range []string{Version3, Version4} {
+		t.Run(version, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := NewEncoder(&buf, version)
+			require.NoError(t, err)
+			for _, e := range entries {
+				require.NoError(t, enc.Encode(e))
+			}
+			assert.Contains(t, buf.String(), "VERSION:"+version+"\r\n")
+
+			dec := NewDecoder(&buf)
+			for _, want := range entries {
+				got, err := dec.Decode()
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+			_, err = dec.Decode()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestFolding(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, Version4)
+	require.NoError(t, err)
+	e := &Entry{Contact: contact.Contact{
+		Fullname: "Jürgen Müller",
+		Address:  strings.Repeat("Straße ", 40),
+	}}
+	require.NoError(t, enc.Encode(e))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength, line)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+	got, err := NewDecoder(&buf).Decode()
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(e.Contact.Address), got.Contact.Address)
+
+	_, err = NewEncoder(&buf, "2.1")
+	assert.Equal(t, errUnsupportedVersion, err)
+}